@@ -0,0 +1,238 @@
+// Package api composes the schemas converted by zen into a typed API client.
+// Endpoints are registered with their request and response structs, and
+// Export returns a TS module with their schemas, the routes and a client
+// built on fetch, validating requests and responses with zod.
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hypersequent/zen"
+)
+
+// Registry collects the routes of an API, see NewRegistry.
+type Registry struct {
+	converter *zen.Converter
+	routes    []route
+	names     map[string]bool
+}
+
+type route struct {
+	name      string
+	method    string
+	path      string
+	request   string
+	locations []string
+	response  string
+}
+
+// NewRegistry returns a registry converting the request and response structs
+// of its routes with the given converter, so that its options apply to them.
+func NewRegistry(c *zen.Converter) *Registry {
+	return &Registry{
+		converter: c,
+		names:     make(map[string]bool),
+	}
+}
+
+var matchIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// Add registers a route, eg. `r.Add("getUser", "GET", "/users/{id}",
+// GetUserParams{}, User{})`. name is the name of the client method and path
+// parameters are written in braces. The request struct is converted with
+// AddParams, so its fields are sent in the path, query, headers or JSON body
+// according to their `in` tag, and the response struct with AddType. Routes
+// without a request or a response take nil instead. Add panics on invalid or
+// duplicate names, on paths whose parameters differ from the path fields of the
+// request and on structs that cannot be converted.
+func (r *Registry) Add(name, method, path string, request, response interface{}) {
+	if !matchIdentifier.MatchString(name) {
+		panic(fmt.Sprintf("invalid route name: %s", name))
+	}
+	if r.names[name] {
+		panic(fmt.Sprintf("duplicate route: %s", name))
+	}
+	var params []string
+	if request != nil {
+		params = r.converter.ParamNames(request, "path")
+	}
+	if !sameNames(pathParams(path), params) {
+		panic(fmt.Sprintf("path %s of route %s does not match the path fields of its request: %s",
+			path, name, strings.Join(params, ", ")))
+	}
+	r.names[name] = true
+
+	rt := route{
+		name:   name,
+		method: strings.ToUpper(method),
+		path:   path,
+	}
+	if request != nil {
+		rt.locations = r.converter.AddParams(request)
+		rt.request = r.converter.TypeName(request)
+	}
+	if response != nil {
+		r.converter.AddType(response)
+		rt.response = r.converter.TypeName(response)
+	}
+
+	r.routes = append(r.routes, rt)
+}
+
+var matchPathParam = regexp.MustCompile(`{(\w+)}`)
+
+// pathParams returns the names of the parameters of a path, eg. id for
+// /users/{id}, as substituted by the client.
+func pathParams(path string) []string {
+	var names []string
+	for _, m := range matchPathParam.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[1])
+	}
+
+	return names
+}
+
+// sameNames checks whether two lists hold the same names, in any order.
+func sameNames(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	return slices.Equal(a, b)
+}
+
+// Export returns a TS module with the schemas converted so far, the routes in
+// registration order and a createClient function returning the typed client.
+func (r *Registry) Export() string {
+	output := strings.Builder{}
+	output.WriteString("import { z } from \"zod\"\n\n")
+	output.WriteString(r.converter.Export())
+	output.WriteString(r.exportRoutes())
+	output.WriteString(requestHelper)
+	output.WriteString(r.exportClient())
+
+	return output.String()
+}
+
+// exportRoutes returns the routes with the schemas of their request parameters
+// and response.
+func (r *Registry) exportRoutes() string {
+	output := strings.Builder{}
+	output.WriteString("export const routes = {\n")
+	for _, rt := range r.routes {
+		output.WriteString(fmt.Sprintf("  %s: {\n", rt.name))
+		output.WriteString(fmt.Sprintf("    method: %s,\n", strconv.Quote(rt.method)))
+		output.WriteString(fmt.Sprintf("    path: %s,\n", strconv.Quote(rt.path)))
+		if len(rt.locations) == 0 {
+			output.WriteString("    request: {},\n")
+		} else {
+			output.WriteString("    request: {\n")
+			for _, location := range rt.locations {
+				output.WriteString(fmt.Sprintf("      %s: %sSchema,\n", location, paramsName(rt.request, location)))
+			}
+			output.WriteString("    },\n")
+		}
+		if rt.response == "" {
+			output.WriteString("    response: null,\n")
+		} else {
+			output.WriteString(fmt.Sprintf("    response: %sSchema,\n", rt.response))
+		}
+		output.WriteString("  },\n")
+	}
+	output.WriteString("} as const\n")
+
+	return output.String()
+}
+
+// requestHelper sends the validated parameters of a request and throws on
+// responses with an error status.
+const requestHelper = `
+type RequestParams = {
+  path?: Record<string, unknown>
+  query?: Record<string, unknown>
+  header?: Record<string, unknown>
+  body?: unknown
+}
+
+async function send(fetchFn: typeof fetch, baseUrl: string, method: string, path: string, params: RequestParams): Promise<Response> {
+  const url = baseUrl + path.replace(/{(\w+)}/g, (_, key: string) => encodeURIComponent(String(params.path?.[key])))
+  const query = new URLSearchParams()
+  for (const [key, value] of Object.entries(params.query ?? {})) {
+    for (const item of [value].flat()) {
+      if (item !== undefined && item !== null) query.append(key, String(item))
+    }
+  }
+  const headers = new Headers()
+  for (const [key, value] of Object.entries(params.header ?? {})) {
+    if (value !== undefined && value !== null) headers.set(key, String(value))
+  }
+  let body: string | undefined
+  if (params.body !== undefined) {
+    headers.set("Content-Type", "application/json")
+    body = JSON.stringify(params.body)
+  }
+  const search = query.toString()
+  const response = await fetchFn(search === "" ? url : url + "?" + search, { method, headers, body })
+  if (!response.ok) {
+    throw new Error(method + " " + path + " failed with status " + response.status)
+  }
+  return response
+}
+`
+
+// exportClient returns the createClient function, with a method per route
+// parsing its parameters and response with their schemas.
+func (r *Registry) exportClient() string {
+	output := strings.Builder{}
+	output.WriteString("\nexport function createClient(baseUrl: string, fetchFn: typeof fetch = fetch) {\n")
+	output.WriteString("  return {\n")
+	for _, rt := range r.routes {
+		result := "void"
+		if rt.response != "" {
+			result = rt.response
+		}
+		if len(rt.locations) == 0 {
+			output.WriteString(fmt.Sprintf("    async %s(): Promise<%s> {\n", rt.name, result))
+		} else {
+			params := make([]string, 0, len(rt.locations))
+			for _, location := range rt.locations {
+				params = append(params, fmt.Sprintf("%s: %s", location, paramsName(rt.request, location)))
+			}
+			output.WriteString(fmt.Sprintf("    async %s(params: { %s }): Promise<%s> {\n",
+				rt.name, strings.Join(params, ", "), result))
+		}
+
+		call := fmt.Sprintf("send(fetchFn, baseUrl, %s, %s, {", strconv.Quote(rt.method), strconv.Quote(rt.path))
+		if len(rt.locations) == 0 {
+			call += "})"
+		} else {
+			call += "\n"
+			for _, location := range rt.locations {
+				call += fmt.Sprintf("        %s: %sSchema.parse(params.%s),\n", location, paramsName(rt.request, location), location)
+			}
+			call += "      })"
+		}
+		if rt.response == "" {
+			output.WriteString(fmt.Sprintf("      await %s\n", call))
+		} else {
+			output.WriteString(fmt.Sprintf("      const response = await %s\n", call))
+			output.WriteString(fmt.Sprintf("      return %sSchema.parse(await response.json())\n", rt.response))
+		}
+		output.WriteString("    },\n")
+	}
+	output.WriteString("  }\n")
+	output.WriteString("}\n")
+
+	return output.String()
+}
+
+// paramsName returns the name of the type of the request parameters in the
+// given location, see zen.Converter.AddParams.
+func paramsName(request, location string) string {
+	return request + strings.ToUpper(location[:1]) + location[1:]
+}
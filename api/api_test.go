@@ -0,0 +1,192 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/hypersequent/zen"
+	"github.com/stretchr/testify/assert"
+)
+
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name" validate:"required"`
+}
+
+type GetUserParams struct {
+	ID     string   `json:"id" in:"path"`
+	Expand []string `json:"expand,omitempty" in:"query"`
+}
+
+type CreateUserParams struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type DeleteUserParams struct {
+	ID        string `json:"id" in:"path"`
+	RequestID string `json:"X-Request-Id" in:"header"`
+}
+
+func TestRegistry(t *testing.T) {
+	c := zen.NewConverterWithOpts()
+	r := NewRegistry(&c)
+	r.Add("getUser", "get", "/users/{id}", GetUserParams{}, User{})
+	r.Add("createUser", "POST", "/users", CreateUserParams{}, User{})
+	r.Add("deleteUser", "DELETE", "/users/{id}", DeleteUserParams{}, nil)
+	r.Add("ping", "GET", "/ping", nil, nil)
+	assert.Equal(t, `import { z } from "zod"
+
+export const GetUserParamsPathSchema = z.object({
+  id: z.string(),
+})
+export type GetUserParamsPath = z.infer<typeof GetUserParamsPathSchema>
+
+export const GetUserParamsQuerySchema = z.object({
+  expand: z.string().array().optional(),
+})
+export type GetUserParamsQuery = z.infer<typeof GetUserParamsQuerySchema>
+
+export const UserSchema = z.object({
+  id: z.string(),
+  name: z.string().min(1),
+})
+export type User = z.infer<typeof UserSchema>
+
+export const CreateUserParamsBodySchema = z.object({
+  name: z.string().min(1),
+})
+export type CreateUserParamsBody = z.infer<typeof CreateUserParamsBodySchema>
+
+export const DeleteUserParamsPathSchema = z.object({
+  id: z.string(),
+})
+export type DeleteUserParamsPath = z.infer<typeof DeleteUserParamsPathSchema>
+
+export const DeleteUserParamsHeaderSchema = z.object({
+  "X-Request-Id": z.string(),
+})
+export type DeleteUserParamsHeader = z.infer<typeof DeleteUserParamsHeaderSchema>
+
+export const routes = {
+  getUser: {
+    method: "GET",
+    path: "/users/{id}",
+    request: {
+      path: GetUserParamsPathSchema,
+      query: GetUserParamsQuerySchema,
+    },
+    response: UserSchema,
+  },
+  createUser: {
+    method: "POST",
+    path: "/users",
+    request: {
+      body: CreateUserParamsBodySchema,
+    },
+    response: UserSchema,
+  },
+  deleteUser: {
+    method: "DELETE",
+    path: "/users/{id}",
+    request: {
+      path: DeleteUserParamsPathSchema,
+      header: DeleteUserParamsHeaderSchema,
+    },
+    response: null,
+  },
+  ping: {
+    method: "GET",
+    path: "/ping",
+    request: {},
+    response: null,
+  },
+} as const
+
+type RequestParams = {
+  path?: Record<string, unknown>
+  query?: Record<string, unknown>
+  header?: Record<string, unknown>
+  body?: unknown
+}
+
+async function send(fetchFn: typeof fetch, baseUrl: string, method: string, path: string, params: RequestParams): Promise<Response> {
+  const url = baseUrl + path.replace(/{(\w+)}/g, (_, key: string) => encodeURIComponent(String(params.path?.[key])))
+  const query = new URLSearchParams()
+  for (const [key, value] of Object.entries(params.query ?? {})) {
+    for (const item of [value].flat()) {
+      if (item !== undefined && item !== null) query.append(key, String(item))
+    }
+  }
+  const headers = new Headers()
+  for (const [key, value] of Object.entries(params.header ?? {})) {
+    if (value !== undefined && value !== null) headers.set(key, String(value))
+  }
+  let body: string | undefined
+  if (params.body !== undefined) {
+    headers.set("Content-Type", "application/json")
+    body = JSON.stringify(params.body)
+  }
+  const search = query.toString()
+  const response = await fetchFn(search === "" ? url : url + "?" + search, { method, headers, body })
+  if (!response.ok) {
+    throw new Error(method + " " + path + " failed with status " + response.status)
+  }
+  return response
+}
+
+export function createClient(baseUrl: string, fetchFn: typeof fetch = fetch) {
+  return {
+    async getUser(params: { path: GetUserParamsPath, query: GetUserParamsQuery }): Promise<User> {
+      const response = await send(fetchFn, baseUrl, "GET", "/users/{id}", {
+        path: GetUserParamsPathSchema.parse(params.path),
+        query: GetUserParamsQuerySchema.parse(params.query),
+      })
+      return UserSchema.parse(await response.json())
+    },
+    async createUser(params: { body: CreateUserParamsBody }): Promise<User> {
+      const response = await send(fetchFn, baseUrl, "POST", "/users", {
+        body: CreateUserParamsBodySchema.parse(params.body),
+      })
+      return UserSchema.parse(await response.json())
+    },
+    async deleteUser(params: { path: DeleteUserParamsPath, header: DeleteUserParamsHeader }): Promise<void> {
+      await send(fetchFn, baseUrl, "DELETE", "/users/{id}", {
+        path: DeleteUserParamsPathSchema.parse(params.path),
+        header: DeleteUserParamsHeaderSchema.parse(params.header),
+      })
+    },
+    async ping(): Promise<void> {
+      await send(fetchFn, baseUrl, "GET", "/ping", {})
+    },
+  }
+}
+`, r.Export())
+
+	assert.PanicsWithValue(t, "duplicate route: ping", func() {
+		r.Add("ping", "HEAD", "/ping", nil, nil)
+	})
+	assert.PanicsWithValue(t, "invalid route name: get-user", func() {
+		r.Add("get-user", "GET", "/users/{id}", GetUserParams{}, User{})
+	})
+	assert.PanicsWithValue(t, "path /users/{userId} of route getUserById does not match the path fields of its request: id", func() {
+		r.Add("getUserById", "GET", "/users/{userId}", GetUserParams{}, User{})
+	})
+	assert.PanicsWithValue(t, "path /users of route listUsers does not match the path fields of its request: id", func() {
+		r.Add("listUsers", "GET", "/users", GetUserParams{}, nil)
+	})
+	assert.PanicsWithValue(t, "path /users/{id}/ping of route pingUser does not match the path fields of its request: ", func() {
+		r.Add("pingUser", "GET", "/users/{id}/ping", nil, nil)
+	})
+}
+
+func TestRegistryPrefix(t *testing.T) {
+	c := zen.NewConverterWithOpts(zen.WithPrefix("API"))
+	r := NewRegistry(&c)
+	r.Add("createUser", "POST", "/users", CreateUserParams{}, User{})
+	assert.Contains(t, r.Export(), `    async createUser(params: { body: APICreateUserParamsBody }): Promise<APIUser> {
+      const response = await send(fetchFn, baseUrl, "POST", "/users", {
+        body: APICreateUserParamsBodySchema.parse(params.body),
+      })
+      return APIUserSchema.parse(await response.json())
+    },
+`)
+}
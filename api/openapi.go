@@ -0,0 +1,162 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hypersequent/zen"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of an OpenAPI document, see ExportOpenAPI.
+type Format int
+
+const (
+	// JSON encodes the document as indented JSON.
+	JSON Format = iota
+	// YAML encodes the document as YAML.
+	YAML
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi" yaml:"openapi"`
+	Info       openAPIInfo                             `json:"info" yaml:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths" yaml:"paths"`
+	Components openAPIComponents                       `json:"components" yaml:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title" yaml:"title"`
+	Version string `json:"version" yaml:"version"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId" yaml:"operationId"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses" yaml:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string          `json:"name" yaml:"name"`
+	In       string          `json:"in" yaml:"in"`
+	Required bool            `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *zen.JSONSchema `json:"schema" yaml:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                        `json:"required" yaml:"required"`
+	Content  map[string]openAPIMediaType `json:"content" yaml:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description" yaml:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *zen.JSONSchema `json:"schema" yaml:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*zen.JSONSchema `json:"schemas" yaml:"schemas"`
+}
+
+// ExportOpenAPI returns an OpenAPI 3.1 document describing the routes, with
+// the schemas converted so far as components, so that the documentation of the
+// API is generated from the same structs as its zod schemas, see
+// zen.Converter.OpenAPISchemas. Path, query and header parameters are listed
+// per route, while bodies and responses reference their components.
+func (r *Registry) ExportOpenAPI(title, version string, format Format) (string, error) {
+	schemas, err := r.converter.OpenAPISchemas()
+	if err != nil {
+		return "", err
+	}
+
+	doc := openAPIDocument{
+		OpenAPI:    "3.1.0",
+		Info:       openAPIInfo{title, version},
+		Paths:      make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{schemas},
+	}
+	listed := make(map[string]bool)
+	for _, rt := range r.routes {
+		op := &openAPIOperation{OperationID: rt.name, Responses: make(map[string]openAPIResponse)}
+		for _, location := range rt.locations {
+			name := paramsName(rt.request, location)
+			if location == "body" {
+				op.RequestBody = &openAPIBody{true, jsonContent(name)}
+				continue
+			}
+
+			op.Parameters = append(op.Parameters, openAPIParameters(location, schemas[name])...)
+			listed[name] = true
+		}
+		if rt.response == "" {
+			op.Responses["204"] = openAPIResponse{Description: "No Content"}
+		} else {
+			op.Responses["200"] = openAPIResponse{"OK", jsonContent(rt.response)}
+		}
+
+		if doc.Paths[rt.path] == nil {
+			doc.Paths[rt.path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[rt.path][strings.ToLower(rt.method)] = op
+	}
+	// The parameters are listed on the operations instead.
+	for name := range listed {
+		delete(schemas, name)
+	}
+
+	output := strings.Builder{}
+	if format == YAML {
+		enc := yaml.NewEncoder(&output)
+		enc.SetIndent(2)
+		err = enc.Encode(doc)
+	} else {
+		enc := json.NewEncoder(&output)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(doc)
+	}
+	if err != nil {
+		return "", fmt.Errorf("encoding OpenAPI document: %w", err)
+	}
+
+	return output.String(), nil
+}
+
+// openAPIParameters returns the parameters in a location, in name order, from
+// the object schema of the parameters. Path parameters are always required.
+func openAPIParameters(location string, params *zen.JSONSchema) []openAPIParameter {
+	required := make(map[string]bool)
+	for _, name := range params.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(params.Properties))
+	for name := range params.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := make([]openAPIParameter, 0, len(names))
+	for _, name := range names {
+		parameters = append(parameters, openAPIParameter{
+			Name:     name,
+			In:       location,
+			Required: required[name] || location == "path",
+			Schema:   params.Properties[name],
+		})
+	}
+
+	return parameters
+}
+
+// jsonContent returns the JSON content referencing the named component.
+func jsonContent(name string) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{
+		"application/json": {&zen.JSONSchema{Ref: "#/components/schemas/" + name}},
+	}
+}
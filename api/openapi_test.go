@@ -0,0 +1,151 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hypersequent/zen"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportOpenAPI(t *testing.T) {
+	c := zen.NewConverterWithOpts()
+	r := NewRegistry(&c)
+	r.Add("getUser", "get", "/users/{id}", GetUserParams{}, User{})
+	r.Add("createUser", "POST", "/users", CreateUserParams{}, User{})
+	r.Add("deleteUser", "DELETE", "/users/{id}", DeleteUserParams{}, nil)
+
+	doc, err := r.ExportOpenAPI("Users", "1.0.0", YAML)
+	assert.NoError(t, err)
+	assert.Equal(t, `openapi: 3.1.0
+info:
+  title: Users
+  version: 1.0.0
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/CreateUserParamsBody'
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+  /users/{id}:
+    delete:
+      operationId: deleteUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: X-Request-Id
+          in: header
+          required: true
+          schema:
+            type: string
+      responses:
+        "204":
+          description: No Content
+    get:
+      operationId: getUser
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+        - name: expand
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/User'
+components:
+  schemas:
+    CreateUserParamsBody:
+      type: object
+      properties:
+        name:
+          type: string
+          minLength: 1
+      required:
+        - name
+    User:
+      type: object
+      properties:
+        id:
+          type: string
+        name:
+          type: string
+          minLength: 1
+      required:
+        - id
+        - name
+`, doc)
+
+	doc, err = r.ExportOpenAPI("Users", "1.0.0", JSON)
+	assert.NoError(t, err)
+	assert.Contains(t, doc, `{
+  "openapi": "3.1.0",
+  "info": {
+    "title": "Users",
+    "version": "1.0.0"
+  },
+`)
+	assert.Contains(t, doc, `        "parameters": [
+          {
+            "name": "id",
+            "in": "path",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "expand",
+            "in": "query",
+            "schema": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            }
+          }
+        ],
+`)
+}
+
+type Money string
+
+func TestExportOpenAPICustomType(t *testing.T) {
+	type Invoice struct {
+		Total Money `json:"total"`
+	}
+
+	c := zen.NewConverterWithOpts(zen.WithCustomTypes(map[string]zen.CustomFn{
+		"github.com/hypersequent/zen/api.Money": func(c *zen.Converter, t reflect.Type, validate string, indent int) string {
+			return "z.string()"
+		},
+	}))
+	r := NewRegistry(&c)
+	r.Add("getInvoice", "GET", "/invoice", nil, Invoice{})
+
+	_, err := r.ExportOpenAPI("Invoices", "1.0.0", JSON)
+	assert.EqualError(t, err, "Invoice: Total: custom type api.Money has no OpenAPI schema")
+}
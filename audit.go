@@ -0,0 +1,478 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AuditError is raised with WithAudit when the explicit TS type of a field
+// disagrees with its schema.
+type AuditError struct {
+	Type   reflect.Type
+	Field  string
+	Schema string
+	TSType string
+	Reason string
+}
+
+func (e *AuditError) Error() string {
+	return fmt.Sprintf("audit of %s.%s: %s, schema %s, type %s",
+		qualifiedTypeName(e.Type), e.Field, e.Reason, e.Schema, e.TSType)
+}
+
+// auditStruct compares the schemas of the fields of a struct with their
+// explicit TS types and panics with an AuditError on the first disagreement.
+// Optionality and nullability are compared first for precise errors, then the
+// TS type derived from the schema is compared with the explicit one. Fields
+// whose schemas the audit cannot derive a type from, eg. nested objects and
+// custom types, are only checked for optionality and nullability.
+func (c *Converter) auditStruct(t reflect.Type, schemaFields []string) {
+	typeFields, _ := c.getTypeStructFields(t, 0)
+
+	types := make(map[string]string, len(typeFields))
+	for _, field := range typeFields {
+		name, typ := splitEntry(field)
+		types[name] = typ
+	}
+
+	for _, field := range schemaFields {
+		name, schema := splitEntry(field)
+
+		typ, ok := types[name]
+		optional := false
+		if !ok {
+			typ, ok = types[name+"?"]
+			optional = true
+		}
+		if !ok {
+			panic(&AuditError{t, name, schema, "", "field is missing from the TS type"})
+		}
+
+		// unknown and any are optional in z.infer whatever the schema.
+		tsParts := splitType(typ, " | ")
+		if tsParts[0] == "unknown" || tsParts[0] == "any" {
+			continue
+		}
+
+		calls := schemaModifiers(schema)
+		// defaults accept undefined like optional fields, see getTypeField
+		if optional != (calls["optional"] || calls["nullish"] || calls["default"]) {
+			panic(&AuditError{t, name, schema, typ, "optionality differs"})
+		}
+		if reason := auditNullable(schema, typ); reason != "" {
+			panic(&AuditError{t, name, schema, typ, reason})
+		}
+
+		derived, ok := schemaType(schema, false)
+		if !ok {
+			continue
+		}
+		declared, ok := canonicalType(typ, false)
+		if ok && derived != declared {
+			panic(&AuditError{t, name, schema, typ, fmt.Sprintf("type differs from %s derived from the schema", derived)})
+		}
+	}
+}
+
+// auditNullable compares the nullability of a schema with that of a TS type,
+// descending into record values and array elements.
+func auditNullable(schema, typ string) string {
+	calls := schemaModifiers(schema)
+	var tsNullable bool
+	var tsRest []string
+	for _, part := range splitType(typ, " | ") {
+		switch part {
+		case "null":
+			tsNullable = true
+		case "undefined":
+		default:
+			tsRest = append(tsRest, part)
+		}
+	}
+
+	if tsNullable != (calls["nullable"] || calls["nullish"]) {
+		return "nullability differs"
+	}
+	if len(tsRest) != 1 {
+		return ""
+	}
+	ts := tsRest[0]
+	base := schemaBase(schema)
+
+	if strings.HasSuffix(ts, "[]") && strings.HasSuffix(base, ".array()") {
+		elem := strings.TrimSuffix(ts, "[]")
+		if strings.HasPrefix(elem, "(") && strings.HasSuffix(elem, ")") {
+			elem = elem[1 : len(elem)-1]
+		}
+		if reason := auditNullable(strings.TrimSuffix(base, ".array()"), elem); reason != "" {
+			return "element " + reason
+		}
+		return ""
+	}
+
+	if strings.HasPrefix(ts, "Partial<") {
+		ts = ts[len("Partial<") : len(ts)-1]
+	}
+	if strings.HasPrefix(ts, "Record<") && strings.HasPrefix(base, "z.record(") {
+		tsArgs := splitType(ts[len("Record<"):len(ts)-1], ", ")
+		args := splitSchema(base[len("z.record("):len(base)-1], ", ")
+		if len(tsArgs) == 2 && len(args) == 2 {
+			if reason := auditNullable(args[1], tsArgs[1]); reason != "" {
+				return "value " + reason
+			}
+		}
+	}
+
+	return ""
+}
+
+// splitEntry splits an object literal or TS type entry, ie. `  name: value,\n`,
+// into its key and value, leaving out the comment of WithIgnoredTagComments.
+func splitEntry(entry string) (string, string) {
+	entry = strings.TrimSpace(entry)
+	if i := strings.LastIndex(entry, ","+ignoredCommentPrefix); i >= 0 {
+		entry = entry[:i+1]
+	}
+
+	// Quoted keys may contain ": ".
+	start := 0
+	if strings.HasPrefix(entry, `"`) {
+		start = skipLiteral(entry, 0, '"')
+	}
+	i := strings.Index(entry[start:], ": ")
+	if i < 0 {
+		return entry, ""
+	}
+
+	return entry[:start+i], strings.TrimSuffix(entry[start+i+2:], ",")
+}
+
+// schemaModifiers returns the optional, nullable, nullish and default calls
+// applied to a schema as a whole, ie. after its last .array() call.
+func schemaModifiers(schema string) map[string]bool {
+	calls := map[string]bool{}
+	for _, call := range splitSchema(schema, ".") {
+		switch {
+		case call == "array()":
+			calls = map[string]bool{}
+		case call == "optional()" || call == "nullable()" || call == "nullish()":
+			calls[strings.TrimSuffix(call, "()")] = true
+		case strings.HasPrefix(call, "default("):
+			calls["default"] = true
+		}
+	}
+
+	return calls
+}
+
+// schemaBase strips the trailing optional, nullable, nullish and default calls
+// from a schema, as well as the checks of records and arrays, eg. .min(1) or
+// .refine().
+func schemaBase(schema string) string {
+	parts := splitSchema(schema, ".")
+	end := len(parts)
+	for i, part := range parts {
+		if part == "array()" || strings.HasPrefix(part, "record(") {
+			end = i + 1
+		}
+	}
+	for end > 0 {
+		switch part := parts[end-1]; {
+		case part == "optional()" || part == "nullable()" || part == "nullish()" || strings.HasPrefix(part, "default("):
+			end--
+			continue
+		}
+		break
+	}
+
+	return strings.Join(parts[:end], ".")
+}
+
+// splitSchema splits a schema on sep outside of brackets, string literals and
+// regular expression literals.
+func splitSchema(s, sep string) []string {
+	return splitTopLevel(s, sep, false)
+}
+
+// splitType splits a TS type on sep outside of brackets, including angle
+// brackets.
+func splitType(s, sep string) []string {
+	return splitTopLevel(s, sep, true)
+}
+
+func splitTopLevel(s, sep string, angles bool) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '(' || ch == '[' || ch == '{' || (angles && ch == '<'):
+			depth++
+		case ch == ')' || ch == ']' || ch == '}' || (angles && ch == '>'):
+			depth--
+		case !angles && (ch == '\'' || ch == '"' || ch == '`'):
+			i = skipLiteral(s, i, ch)
+			continue
+		case !angles && ch == '/' && startsRegex(s[:i]):
+			i = skipRegex(s, i)
+			continue
+		}
+
+		if depth == 0 && strings.HasPrefix(s[i:], sep) {
+			parts = append(parts, s[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// skipLiteral returns the index of the quote closing the string literal
+// starting at i.
+func skipLiteral(s string, i int, quote byte) int {
+	for i++; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == quote {
+			return i
+		}
+	}
+
+	return i
+}
+
+// startsRegex checks whether a slash following the given text starts a regular
+// expression literal, as opposed to a division.
+func startsRegex(before string) bool {
+	before = strings.TrimRight(before, " ")
+
+	return strings.HasSuffix(before, "(") || strings.HasSuffix(before, ",")
+}
+
+// skipRegex returns the index of the slash closing the regular expression
+// literal starting at i.
+func skipRegex(s string, i int) int {
+	class := false
+	for i++; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				return i
+			}
+		}
+	}
+
+	return i
+}
+
+// schemaType derives the canonical TS type of a schema, see canonicalType. In
+// key position, string literals are kept as is, as they are for the keys of
+// records keyed by an enum. Elsewhere they are widened to string or number, as
+// the explicit types don't narrow enums.
+func schemaType(schema string, key bool) (string, bool) {
+	parts := splitSchema(strings.TrimSpace(schema), ".")
+
+	var members []string
+	var rest []string
+	switch {
+	case len(parts) >= 2 && parts[0] == "z" && parts[1] == "coerce" && len(parts) >= 3:
+		typ, ok := map[string]string{"date()": "Date", "number()": "number", "bigint()": "bigint"}[parts[2]]
+		if !ok {
+			return "", false
+		}
+		members, rest = []string{typ}, parts[3:]
+	case len(parts) >= 2 && parts[0] == "z":
+		call, args := splitCall(parts[1])
+		switch call {
+		case "string", "number", "boolean", "bigint", "any", "unknown", "null":
+			members = []string{call}
+		case "literal":
+			members = []string{literalType(args, key)}
+		case "enum":
+			for _, val := range splitSchema(strings.TrimSuffix(strings.TrimSuffix(args, " as const"), "]")[1:], ", ") {
+				members = append(members, literalType(val, key))
+			}
+		case "union":
+			for _, option := range splitSchema(strings.TrimSuffix(strings.TrimPrefix(args, "["), "]"), ", ") {
+				typ, ok := schemaType(option, key)
+				if !ok {
+					return "", false
+				}
+				members = append(members, typ)
+			}
+		case "record":
+			recordArgs := splitSchema(args, ", ")
+			if len(recordArgs) != 2 {
+				return "", false
+			}
+			keyType, ok := schemaType(recordArgs[0], true)
+			if !ok {
+				return "", false
+			}
+			valueType, ok := schemaType(recordArgs[1], false)
+			if !ok {
+				return "", false
+			}
+			members = []string{recordType(keyType, valueType)}
+		case "lazy":
+			name := strings.TrimPrefix(args, "() => ")
+			if !strings.HasSuffix(name, "Schema") || strings.ContainsAny(name, "(.") {
+				return "", false
+			}
+			members = []string{strings.TrimSuffix(name, "Schema")}
+		default:
+			return "", false
+		}
+		rest = parts[2:]
+	case strings.HasSuffix(parts[0], "Schema"):
+		members, rest = []string{strings.TrimSuffix(parts[0], "Schema")}, parts[1:]
+	default:
+		return "", false
+	}
+
+	for _, part := range rest {
+		call, args := splitCall(part)
+		// branded schemas are typed after their brand, see WithBrandedTypes
+		if brand, ok := strings.CutPrefix(call, "brand<"); ok {
+			members = []string{strings.Trim(strings.TrimSuffix(brand, ">"), `"`)}
+			continue
+		}
+		switch call {
+		case "array":
+			members = []string{arrayType(members)}
+		case "nullable":
+			members = append(members, "null")
+		case "optional":
+			members = append(members, "undefined")
+		case "nullish":
+			members = append(members, "null", "undefined")
+		case "or":
+			typ, ok := schemaType(args, key)
+			if !ok {
+				return "", false
+			}
+			members = append(members, typ)
+		case "and", "merge", "extend", "transform", "default", "catch", "brand":
+			return "", false
+		}
+	}
+
+	return unionType(members), true
+}
+
+// canonicalType returns the canonical form of a TS type, with the members of
+// unions deduplicated and sorted so that types can be compared as strings.
+// String and number literals are widened unless in key position, see
+// schemaType. Object types are not supported.
+func canonicalType(typ string, key bool) (string, bool) {
+	var members []string
+	for _, member := range splitType(strings.TrimSpace(typ), " | ") {
+		switch {
+		case strings.HasPrefix(member, "{"):
+			return "", false
+		case strings.HasSuffix(member, "[]"):
+			elem := strings.TrimSuffix(member, "[]")
+			if strings.HasPrefix(elem, "(") && strings.HasSuffix(elem, ")") {
+				elem = elem[1 : len(elem)-1]
+			}
+			elemType, ok := canonicalType(elem, false)
+			if !ok {
+				return "", false
+			}
+			members = append(members, arrayType(splitType(elemType, " | ")))
+		case strings.HasPrefix(member, "Partial<Record<"), strings.HasPrefix(member, "Record<"):
+			record := member
+			if strings.HasPrefix(record, "Partial<") {
+				record = record[len("Partial<") : len(record)-1]
+			}
+			recordArgs := splitType(record[len("Record<"):len(record)-1], ", ")
+			if len(recordArgs) != 2 {
+				return "", false
+			}
+			keyType, ok := canonicalType(recordArgs[0], true)
+			if !ok {
+				return "", false
+			}
+			valueType, ok := canonicalType(recordArgs[1], false)
+			if !ok {
+				return "", false
+			}
+			members = append(members, recordType(keyType, valueType))
+		default:
+			members = append(members, literalType(member, key))
+		}
+	}
+
+	return unionType(members), true
+}
+
+// splitCall splits a call, ie. name(args), into the name and the arguments.
+func splitCall(call string) (string, string) {
+	name, args, ok := strings.Cut(call, "(")
+	if !ok {
+		return call, ""
+	}
+
+	return name, strings.TrimSuffix(args, ")")
+}
+
+// literalType widens string and number literals outside of key position.
+func literalType(literal string, key bool) string {
+	if key {
+		return literal
+	}
+	if strings.HasPrefix(literal, `"`) || strings.HasPrefix(literal, "'") {
+		return "string"
+	}
+	if _, err := strconv.ParseFloat(literal, 64); err == nil {
+		return "number"
+	}
+
+	return literal
+}
+
+// recordType returns the type of a record, which z.infer makes partial when
+// keyed by literals.
+func recordType(keyType, valueType string) string {
+	if strings.HasPrefix(keyType, `"`) {
+		return fmt.Sprintf("Partial<Record<%s, %s>>", keyType, valueType)
+	}
+
+	return fmt.Sprintf("Record<%s, %s>", keyType, valueType)
+}
+
+// arrayType returns the type of an array of the given union members.
+func arrayType(members []string) string {
+	elem := unionType(members)
+	if strings.Contains(elem, " | ") {
+		return fmt.Sprintf("(%s)[]", elem)
+	}
+
+	return elem + "[]"
+}
+
+// unionType returns the union of the given members, deduplicated and sorted.
+func unionType(members []string) string {
+	seen := map[string]bool{}
+	var union []string
+	for _, member := range members {
+		for _, m := range splitType(member, " | ") {
+			if !seen[m] {
+				seen[m] = true
+				union = append(union, m)
+			}
+		}
+	}
+	sort.Strings(union)
+
+	return strings.Join(union, " | ")
+}
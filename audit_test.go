@@ -0,0 +1,105 @@
+package zen
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	type Post struct {
+		Title string `json:"title"`
+	}
+	type User struct {
+		Name   *string          `json:"name,omitempty"`
+		Posts  map[string]*Post `json:"posts"`
+		Drafts map[string]Post  `json:"drafts" validate:"dive,required"`
+		Email  string           `json:"email" validate:"omitempty,email"`
+		Labels []string         `json:"labels" validate:"dive,oneof='a b' c"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.NoError(t, c.TryAddType(User{}))
+	assert.Contains(t, c.Export(), `  posts: Record<string, Post | null> | null,`)
+}
+
+func TestAuditNullable(t *testing.T) {
+	tests := []struct {
+		schema string
+		typ    string
+		reason string
+	}{
+		{"z.string().nullable()", "string | null", ""},
+		{"z.string().optional().nullable()", "string | null | undefined", ""},
+		{"z.string().nullable()", "string", "nullability differs"},
+		{"z.string().regex(/^[(]+$/).nullable()", "string | null", ""},
+		{"z.string().refine((val) => val.length < 3, 'Too (long').nullable()", "string | null", ""},
+		{"PSchema.nullable().array().nullable()", "(P | null)[] | null", ""},
+		{"PSchema.array().min(1).nullable()", "(P | null)[] | null", "element nullability differs"},
+		{"z.record(z.string(), PSchema.nullable()).nullable()", "Record<string, P | null> | null", ""},
+		{"z.record(z.string(), PSchema).refine((val) => Object.keys(val).length > 0, 'Empty map')", "Record<string, P | null>", "value nullability differs"},
+		{"z.record(z.enum([\"a\", \"b\"] as const), z.number().array())", "Partial<Record<\"a\" | \"b\", number[] | null>>", "value nullability differs"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.reason, auditNullable(tt.schema, tt.typ), tt.schema)
+	}
+}
+
+func TestAuditError(t *testing.T) {
+	type Item struct {
+		Tags []string `json:"tags"`
+	}
+
+	err := &AuditError{reflect.TypeOf(Item{}), "tags", "z.string().array()", "string[] | null", "nullability differs"}
+	assert.Equal(t, "audit of github.com/hypersequent/zen.Item.tags: nullability differs, schema z.string().array(), type string[] | null", err.Error())
+}
+
+func TestAuditSchemaType(t *testing.T) {
+	tests := []struct {
+		schema string
+		typ    string
+	}{
+		{"z.string().min(1).optional()", "string | undefined"},
+		{"z.coerce.date().refine((val) => val.getTime() !== 0, 'Invalid date')", "Date"},
+		{"z.number().int().gte(2).or(z.literal(0)).optional()", "number | undefined"},
+		{`z.enum(["a", "b"] as const).nullish()`, "null | string | undefined"},
+		{"z.lazy(() => TreeSchema).array().nullable()", "Tree[] | null"},
+		{"PostSchema.nullable().array()", "(Post | null)[]"},
+		{`z.record(z.enum(["a", "b"] as const), z.union([z.string(), z.number()]))`, `Partial<Record<"a" | "b", number | string>>`},
+		{`z.string().regex(/^\d+$/).pipe(z.string().refine((val) => BigInt(val) >= 10n))`, "string"},
+	}
+
+	for _, tt := range tests {
+		typ, ok := schemaType(tt.schema, false)
+		assert.True(t, ok, tt.schema)
+		assert.Equal(t, tt.typ, typ, tt.schema)
+	}
+
+	_, ok := schemaType("z.object({\n  a: z.string(),\n})", false)
+	assert.False(t, ok)
+	_, ok = schemaType("BaseSchema.merge(OtherSchema)", false)
+	assert.False(t, ok)
+}
+
+func TestAuditCanonicalType(t *testing.T) {
+	tests := []struct {
+		typ       string
+		canonical string
+	}{
+		{"string | null | undefined", "null | string | undefined"},
+		{"(Post | null)[] | null", "(Post | null)[] | null"},
+		{`Partial<Record<"b" | "a", number[] | null>>`, `Partial<Record<"a" | "b", null | number[]>>`},
+		{"Record<string, Post | null>", "Record<string, Post | null>"},
+	}
+
+	for _, tt := range tests {
+		canonical, ok := canonicalType(tt.typ, false)
+		assert.True(t, ok, tt.typ)
+		assert.Equal(t, tt.canonical, canonical, tt.typ)
+	}
+
+	_, ok := canonicalType("{\n  a: string,\n}", false)
+	assert.False(t, ok)
+}
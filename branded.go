@@ -0,0 +1,78 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// WithBrandedTypes exports a branded schema for each named primitive type, eg.
+// `export const UserIDSchema = z.string().brand<"UserID">()` for
+// `type UserID string`, which is referenced wherever the type appears, so that
+// values of distinct types cannot be mixed up in TS. Fields with validations
+// are branded in place, as branded schemas have no checks. Map keys are not
+// branded.
+func WithBrandedTypes() Opt {
+	return func(c *Converter) {
+		c.brandedTypes = true
+	}
+}
+
+// isBranded checks whether t is a named primitive type branded with
+// WithBrandedTypes.
+func (c *Converter) isBranded(t reflect.Type) bool {
+	if !c.brandedTypes || c.unbranded || t.Name() == "" || t.PkgPath() == "" || t.Kind() == reflect.Interface {
+		return false
+	}
+
+	_, ok := typeMapping[t.Kind()]
+	return ok
+}
+
+// brandName returns the schema and type name of a branded type, see
+// structName. Branded types are the same in the variants of
+// WithInputOutputSchemas.
+func (c *Converter) brandName(t reflect.Type) string {
+	name := getTypeNameWithGenerics(t.Name())
+	if c.qualifyNames {
+		return c.namePrefix + packageName(t.PkgPath()) + name
+	}
+
+	return c.namePrefix + name
+}
+
+// convertBranded returns the schema of a branded type, exporting it the first
+// time. Validations other than those of the type itself brand the field in
+// place.
+func (c *Converter) convertBranded(t reflect.Type, validate string, indent int) string {
+	name := c.addBranded(t)
+
+	c.unbranded = true
+	base := c.ConvertType(t, "", indent)
+	schema := c.ConvertType(t, validate, indent)
+	c.unbranded = false
+	if schema == base {
+		return schemaName(c.prefix, name)
+	}
+
+	return fmt.Sprintf("%s.brand<%s>()", schema, strconv.Quote(c.prefix+name))
+}
+
+// addBranded exports the schema of a branded type unless it is already
+// exported, and returns its name.
+func (c *Converter) addBranded(t reflect.Type) string {
+	name := c.brandName(t)
+	c.checkCollision(name, t)
+	if _, ok := c.outputs[name]; ok {
+		return name
+	}
+
+	c.unbranded = true
+	base := c.ConvertType(t, "", 0)
+	c.unbranded = false
+
+	c.addSchema(name, fmt.Sprintf("export const %s = %s.brand<%s>()\nexport type %s%s = z.infer<typeof %s>",
+		schemaName(c.prefix, name), base, strconv.Quote(c.prefix+name), c.prefix, name, schemaName(c.prefix, name)))
+
+	return name
+}
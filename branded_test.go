@@ -0,0 +1,67 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrandedTypes(t *testing.T) {
+	type UserID string
+	type Score uint8
+	type User struct {
+		ID      UserID           `json:"id"`
+		Manager *UserID          `json:"manager"`
+		Friends []UserID         `json:"friends"`
+		Alias   UserID           `json:"alias" validate:"required,max=5"`
+		Scores  map[UserID]Score `json:"scores"`
+	}
+
+	c := NewConverterWithOpts(WithBrandedTypes())
+	assert.Equal(t, `export const UserIDSchema = z.string().brand<"UserID">()
+export type UserID = z.infer<typeof UserIDSchema>
+
+export const ScoreSchema = z.number().int().nonnegative().brand<"Score">()
+export type Score = z.infer<typeof ScoreSchema>
+
+export const UserSchema = z.object({
+  id: UserIDSchema,
+  manager: UserIDSchema.nullable(),
+  friends: UserIDSchema.array().nullable(),
+  alias: z.string().min(1).max(5).brand<"UserID">(),
+  scores: z.record(z.string(), ScoreSchema).nullable(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+	assert.Contains(t, schemas, "User")
+
+	c = NewConverterWithOpts(WithBrandedTypes(), WithExplicitTypes(), WithAudit(), WithPrefix("API"))
+	assert.Equal(t, `export const APIUserIDSchema = z.string().brand<"APIUserID">()
+export type APIUserID = z.infer<typeof APIUserIDSchema>
+
+export const APIScoreSchema = z.number().int().nonnegative().brand<"APIScore">()
+export type APIScore = z.infer<typeof APIScoreSchema>
+
+export type APIUser = {
+  id: APIUserID,
+  manager: APIUserID | null,
+  friends: APIUserID[] | null,
+  alias: APIUserID,
+  scores: Record<string, APIScore> | null,
+}
+export const APIUserSchemaShape = {
+  id: APIUserIDSchema,
+  manager: APIUserIDSchema.nullable(),
+  friends: APIUserIDSchema.array().nullable(),
+  alias: z.string().min(1).max(5).brand<"APIUserID">(),
+  scores: z.record(z.string(), APIScoreSchema).nullable(),
+}
+export const APIUserSchema: z.ZodType<APIUser> = z.object(APIUserSchemaShape)
+
+`, c.Convert(User{}))
+}
@@ -0,0 +1,75 @@
+// Command zen verifies schemas generated by zen.
+//
+// Usage:
+//
+//	zen verify --node <schemas.ts> <fixtures.json>
+//
+// The verify command runs the schemas exported by the given module under node,
+// 22.6 or later, against a corpus of fixtures written with
+// Converter.ExportFixtures, and reports the schemas that rejected valid
+// payloads or accepted invalid ones. The module must import zod itself. The
+// command exits with status 1 if any fixture fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/hypersequent/zen"
+)
+
+const usage = "usage: zen verify --node <schemas.ts> <fixtures.json>\n"
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "verify" {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	node := flags.Bool("node", false, "run the schemas under node")
+	flags.StringVar(&zen.NodeCommand, "node-command", zen.NodeCommand, "node binary")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if !*node || flags.NArg() != 2 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	corpus, err := os.ReadFile(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(stderr, "zen: %v\n", err)
+		return 1
+	}
+	failures, err := zen.VerifyNodeFile(ctx, flags.Arg(0), corpus)
+	if err != nil {
+		fmt.Fprintf(stderr, "zen: %v\n", err)
+		return 1
+	}
+
+	for _, failure := range failures {
+		if failure.Error != "" {
+			fmt.Fprintf(stdout, "FAIL %s rejected valid payload %s: %s\n", failure.Schema, failure.Payload, failure.Error)
+		} else {
+			fmt.Fprintf(stdout, "FAIL %s accepted invalid payload %s\n", failure.Schema, failure.Payload)
+		}
+	}
+	if len(failures) > 0 {
+		return 1
+	}
+
+	return 0
+}
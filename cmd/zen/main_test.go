@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hypersequent/zen"
+)
+
+func TestVerify(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no sh")
+	}
+
+	type User struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	c := zen.NewConverter(nil)
+	c.AddType(User{})
+	corpus, err := c.ExportFixtures([]zen.Fixture{
+		{Type: User{}, Value: User{Name: "John"}, Valid: true},
+		{Type: User{}, Value: User{}, Valid: true},
+		{Type: User{}, Value: map[string]int{"name": 1}, Valid: false},
+	})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	schemas := filepath.Join(dir, "schemas.ts")
+	fixtures := filepath.Join(dir, "fixtures.json")
+	require.NoError(t, os.WriteFile(schemas, []byte("import { z } from \"zod\"\n\n"+c.Export()), 0o600))
+	require.NoError(t, os.WriteFile(fixtures, corpus, 0o600))
+
+	// node is replaced by a script printing the results, checking that the
+	// script imports the schemas module in place
+	node := filepath.Join(dir, "node")
+	require.NoError(t, os.WriteFile(node, []byte(`#!/bin/sh
+grep -q 'from "file://`+filepath.ToSlash(schemas)+`"' "$3" || exit 1
+echo '[{"accepted":true,"error":""},{"accepted":false,"error":"String must contain at least 1 character(s)"},{"accepted":true,"error":""}]'
+`), 0o700))
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), []string{"verify", "--node", "--node-command", node, schemas, fixtures}, &stdout, &stderr)
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(t, `FAIL UserSchema rejected valid payload {"name":""}: String must contain at least 1 character(s)
+FAIL UserSchema accepted invalid payload {"name":1}
+`, stdout.String())
+}
+
+func TestVerifyUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"check"}, {"verify", "schemas.ts", "fixtures.json"}, {"verify", "--node", "schemas.ts"}} {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 2, run(context.Background(), args, &stdout, &stderr))
+		assert.Contains(t, stderr.String(), "usage: zen verify --node")
+	}
+}
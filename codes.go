@@ -0,0 +1,121 @@
+package zen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats of the codes, used unless WithCodeLists is set.
+const (
+	alpha2CodeRegexString = "^[A-Z]{2}$"
+	alpha3CodeRegexString = "^[A-Z]{3}$"
+	iso31662RegexString   = "^[A-Z]{2}-[A-Z0-9]{1,3}$"
+)
+
+// Code lists of the iso3166_1 and iso4217 validations, as in go-validator. The
+// iso3166_2 list is left out for its size, as it has thousands of codes.
+var (
+	iso3166Alpha2Codes = []string{
+		"AD", "AE", "AF", "AG", "AI", "AL", "AM", "AO", "AQ", "AR",
+		"AS", "AT", "AU", "AW", "AX", "AZ", "BA", "BB", "BD", "BE",
+		"BF", "BG", "BH", "BI", "BJ", "BL", "BM", "BN", "BO", "BQ",
+		"BR", "BS", "BT", "BV", "BW", "BY", "BZ", "CA", "CC", "CD",
+		"CF", "CG", "CH", "CI", "CK", "CL", "CM", "CN", "CO", "CR",
+		"CU", "CV", "CW", "CX", "CY", "CZ", "DE", "DJ", "DK", "DM",
+		"DO", "DZ", "EC", "EE", "EG", "EH", "ER", "ES", "ET", "FI",
+		"FJ", "FK", "FM", "FO", "FR", "GA", "GB", "GD", "GE", "GF",
+		"GG", "GH", "GI", "GL", "GM", "GN", "GP", "GQ", "GR", "GS",
+		"GT", "GU", "GW", "GY", "HK", "HM", "HN", "HR", "HT", "HU",
+		"ID", "IE", "IL", "IM", "IN", "IO", "IQ", "IR", "IS", "IT",
+		"JE", "JM", "JO", "JP", "KE", "KG", "KH", "KI", "KM", "KN",
+		"KP", "KR", "KW", "KY", "KZ", "LA", "LB", "LC", "LI", "LK",
+		"LR", "LS", "LT", "LU", "LV", "LY", "MA", "MC", "MD", "ME",
+		"MF", "MG", "MH", "MK", "ML", "MM", "MN", "MO", "MP", "MQ",
+		"MR", "MS", "MT", "MU", "MV", "MW", "MX", "MY", "MZ", "NA",
+		"NC", "NE", "NF", "NG", "NI", "NL", "NO", "NP", "NR", "NU",
+		"NZ", "OM", "PA", "PE", "PF", "PG", "PH", "PK", "PL", "PM",
+		"PN", "PR", "PS", "PT", "PW", "PY", "QA", "RE", "RO", "RS",
+		"RU", "RW", "SA", "SB", "SC", "SD", "SE", "SG", "SH", "SI",
+		"SJ", "SK", "SL", "SM", "SN", "SO", "SR", "SS", "ST", "SV",
+		"SX", "SY", "SZ", "TC", "TD", "TF", "TG", "TH", "TJ", "TK",
+		"TL", "TM", "TN", "TO", "TR", "TT", "TV", "TW", "TZ", "UA",
+		"UG", "UM", "US", "UY", "UZ", "VA", "VC", "VE", "VG", "VI",
+		"VN", "VU", "WF", "WS", "XK", "YE", "YT", "ZA", "ZM", "ZW",
+	}
+	iso3166Alpha2EUCodes = []string{
+		"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI",
+		"FR", "GR", "HR", "HU", "IE", "IT", "LT", "LU", "LV", "MT",
+		"NL", "PL", "PT", "RO", "SE", "SI", "SK",
+	}
+	iso3166Alpha3Codes = []string{
+		"ABW", "AFG", "AGO", "AIA", "ALA", "ALB", "AND", "ARE", "ARG", "ARM",
+		"ASM", "ATA", "ATF", "ATG", "AUS", "AUT", "AZE", "BDI", "BEL", "BEN",
+		"BES", "BFA", "BGD", "BGR", "BHR", "BHS", "BIH", "BLM", "BLR", "BLZ",
+		"BMU", "BOL", "BRA", "BRB", "BRN", "BTN", "BVT", "BWA", "CAF", "CAN",
+		"CCK", "CHE", "CHL", "CHN", "CIV", "CMR", "COD", "COG", "COK", "COL",
+		"COM", "CPV", "CRI", "CUB", "CUW", "CXR", "CYM", "CYP", "CZE", "DEU",
+		"DJI", "DMA", "DNK", "DOM", "DZA", "ECU", "EGY", "ERI", "ESH", "ESP",
+		"EST", "ETH", "FIN", "FJI", "FLK", "FRA", "FRO", "FSM", "GAB", "GBR",
+		"GEO", "GGY", "GHA", "GIB", "GIN", "GLP", "GMB", "GNB", "GNQ", "GRC",
+		"GRD", "GRL", "GTM", "GUF", "GUM", "GUY", "HKG", "HMD", "HND", "HRV",
+		"HTI", "HUN", "IDN", "IMN", "IND", "IOT", "IRL", "IRN", "IRQ", "ISL",
+		"ISR", "ITA", "JAM", "JEY", "JOR", "JPN", "KAZ", "KEN", "KGZ", "KHM",
+		"KIR", "KNA", "KOR", "KWT", "LAO", "LBN", "LBR", "LBY", "LCA", "LIE",
+		"LKA", "LSO", "LTU", "LUX", "LVA", "MAC", "MAF", "MAR", "MCO", "MDA",
+		"MDG", "MDV", "MEX", "MHL", "MKD", "MLI", "MLT", "MMR", "MNE", "MNG",
+		"MNP", "MOZ", "MRT", "MSR", "MTQ", "MUS", "MWI", "MYS", "MYT", "NAM",
+		"NCL", "NER", "NFK", "NGA", "NIC", "NIU", "NLD", "NOR", "NPL", "NRU",
+		"NZL", "OMN", "PAK", "PAN", "PCN", "PER", "PHL", "PLW", "PNG", "POL",
+		"PRI", "PRK", "PRT", "PRY", "PSE", "PYF", "QAT", "REU", "ROU", "RUS",
+		"RWA", "SAU", "SDN", "SEN", "SGP", "SGS", "SHN", "SJM", "SLB", "SLE",
+		"SLV", "SMR", "SOM", "SPM", "SRB", "SSD", "STP", "SUR", "SVK", "SVN",
+		"SWE", "SWZ", "SXM", "SYC", "SYR", "TCA", "TCD", "TGO", "THA", "TJK",
+		"TKL", "TKM", "TLS", "TON", "TTO", "TUN", "TUR", "TUV", "TWN", "TZA",
+		"UGA", "UKR", "UMI", "UNK", "URY", "USA", "UZB", "VAT", "VCT", "VEN",
+		"VGB", "VIR", "VNM", "VUT", "WLF", "WSM", "YEM", "ZAF", "ZMB", "ZWE",
+	}
+	iso3166Alpha3EUCodes = []string{
+		"AUT", "BEL", "BGR", "CYP", "CZE", "DEU", "DNK", "ESP", "EST", "FIN",
+		"FRA", "GRC", "HRV", "HUN", "IRL", "ITA", "LTU", "LUX", "LVA", "MLT",
+		"NLD", "POL", "PRT", "ROU", "SVK", "SVN", "SWE",
+	}
+	iso4217Codes = []string{
+		"AED", "AFN", "ALL", "AMD", "AOA", "ARS", "AUD", "AWG", "AZN", "BAM",
+		"BBD", "BDT", "BGN", "BHD", "BIF", "BMD", "BND", "BOB", "BOV", "BRL",
+		"BSD", "BTN", "BWP", "BYN", "BZD", "CAD", "CDF", "CHE", "CHF", "CHW",
+		"CLF", "CLP", "CNY", "COP", "COU", "CRC", "CUP", "CVE", "CZK", "DJF",
+		"DKK", "DOP", "DZD", "EGP", "ERN", "ETB", "EUR", "FJD", "FKP", "GBP",
+		"GEL", "GHS", "GIP", "GMD", "GNF", "GTQ", "GYD", "HKD", "HNL", "HTG",
+		"HUF", "IDR", "ILS", "INR", "IQD", "IRR", "ISK", "JMD", "JOD", "JPY",
+		"KES", "KGS", "KHR", "KMF", "KPW", "KRW", "KWD", "KYD", "KZT", "LAK",
+		"LBP", "LKR", "LRD", "LSL", "LYD", "MAD", "MDL", "MGA", "MKD", "MMK",
+		"MNT", "MOP", "MRU", "MUR", "MVR", "MWK", "MXN", "MXV", "MYR", "MZN",
+		"NAD", "NGN", "NIO", "NOK", "NPR", "NZD", "OMR", "PAB", "PEN", "PGK",
+		"PHP", "PKR", "PLN", "PYG", "QAR", "RON", "RSD", "RUB", "RWF", "SAR",
+		"SBD", "SCR", "SDG", "SEK", "SGD", "SHP", "SLE", "SOS", "SRD", "SSP",
+		"STN", "SVC", "SYP", "SZL", "THB", "TJS", "TMT", "TND", "TOP", "TRY",
+		"TTD", "TWD", "TZS", "UAH", "UGX", "USD", "USN", "UYI", "UYU", "UYW",
+		"UZS", "VED", "VES", "VND", "VUV", "WST", "XAF", "XAG", "XAU", "XBA",
+		"XBB", "XBC", "XBD", "XCD", "XCG", "XDR", "XOF", "XPD", "XPF", "XPT",
+		"XSU", "XTS", "XUA", "XXX", "YER", "ZAR", "ZMW", "ZWG",
+	}
+)
+
+// codeListTags maps the code validations to the codes they accept.
+var codeListTags = map[string][]string{
+	"iso3166_1_alpha2":    iso3166Alpha2Codes,
+	"iso3166_1_alpha2_eu": iso3166Alpha2EUCodes,
+	"iso3166_1_alpha3":    iso3166Alpha3Codes,
+	"iso3166_1_alpha3_eu": iso3166Alpha3EUCodes,
+	"iso4217":             iso4217Codes,
+}
+
+// codeList returns the check of an iso3166 or iso4217 code, which is an enum of
+// the codes with WithCodeLists and a regex matching their format otherwise.
+func (c *Converter) codeList(tag, format string) string {
+	if codes, ok := codeListTags[tag]; c.codeLists && ok {
+		return fmt.Sprintf(".enum([\"%s\"] as const)", strings.Join(codes, "\", \""))
+	}
+
+	return c.regexCheck(tag, format)
+}
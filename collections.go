@@ -0,0 +1,115 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// WithNamedCollectionSchemas exports a schema for each named slice or map
+// type, eg. `export const TagsSchema = z.string().array()` for
+// `type Tags []string`, which is referenced wherever the type appears instead
+// of inlining its expansion. Fields with validations that change the schema,
+// eg. `dive,min=1`, are still inlined.
+func WithNamedCollectionSchemas() Opt {
+	return func(c *Converter) {
+		c.namedCollections = true
+	}
+}
+
+// isNamedCollection checks whether t is a named slice or map type exported
+// with WithNamedCollectionSchemas.
+func (c *Converter) isNamedCollection(t reflect.Type) bool {
+	if !c.namedCollections || t.Name() == "" || t.PkgPath() == "" {
+		return false
+	}
+
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+}
+
+// collectionName returns the schema and type name of a named collection, see
+// structName.
+func (c *Converter) collectionName(t reflect.Type) string {
+	name := getTypeNameWithGenerics(t.Name()) + c.variant
+	if c.qualifyNames {
+		return c.namePrefix + packageName(t.PkgPath()) + name
+	}
+
+	return c.namePrefix + name
+}
+
+// namedCollection exports the schema of a named collection unless it is
+// already exported, and returns its name. It returns false if the validations
+// change the schema, in which case the field is inlined.
+func (c *Converter) namedCollection(t reflect.Type, validate string, indent int) (string, bool) {
+	name := c.collectionName(t)
+	c.checkCollision(name, t)
+	if c.expandingCollection(name) {
+		if c.recursiveCollections == nil {
+			c.recursiveCollections = make(map[string]bool)
+		}
+		c.recursiveCollections[name] = true
+		return name, true
+	}
+	if _, ok := c.outputs[name]; !ok {
+		c.addCollection(t, name)
+	}
+
+	if validate != "" && c.expandCollection(t, validate, indent) != c.expandCollection(t, "", indent) {
+		return "", false
+	}
+
+	return name, true
+}
+
+// addCollection exports the schema of a named collection. Collections
+// referring to themselves through other collections, eg. `type Tree
+// map[string]Tree`, are referenced with z.lazy() while they are expanded, and
+// get an explicit type, as z.infer cannot follow the reference.
+func (c *Converter) addCollection(t reflect.Type, name string) {
+	c.collections = append(c.collections, collectionExpansion{name, len(c.stack)})
+	defer func() { c.collections = c.collections[:len(c.collections)-1] }()
+
+	schema := c.expandCollection(t, "", 0)
+	if !c.recursiveCollections[name] {
+		c.addSchema(name, fmt.Sprintf("export const %s = %s\nexport type %s%s = z.infer<typeof %s>",
+			schemaName(c.prefix, name), schema, c.prefix, name, schemaName(c.prefix, name)))
+		return
+	}
+
+	// interfaces may refer to themselves through the records they extend
+	decl := fmt.Sprintf("export type %s%s = %s", c.prefix, name, c.getTypeSliceAndArray(t, "", 0))
+	if t.Kind() == reflect.Map {
+		decl = fmt.Sprintf("export interface %s%s extends %s {}", c.prefix, name, c.getTypeMap(t, "", 0))
+	}
+	c.addSchema(name, fmt.Sprintf("%s\nexport const %s: z.ZodType<%s%s> = %s",
+		decl, schemaName(c.prefix, name), c.prefix, name, schema))
+}
+
+// collectionExpansion is a named collection being expanded, along with the
+// depth of the struct stack it is expanded at.
+type collectionExpansion struct {
+	name  string
+	stack int
+}
+
+// expandingCollection checks whether a named collection refers to itself while
+// it is expanded, without structs in between, which break the cycle with
+// z.lazy() themselves.
+func (c *Converter) expandingCollection(name string) bool {
+	for _, expansion := range c.collections {
+		if expansion.name == name && expansion.stack == len(c.stack) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expandCollection returns the inline schema of a named collection.
+func (c *Converter) expandCollection(t reflect.Type, validate string, indent int) string {
+	if t.Kind() == reflect.Map {
+		return c.convertMap(t, validate, indent)
+	}
+
+	return c.convertSliceAndArray(t, validate, indent)
+}
@@ -0,0 +1,141 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamedCollectionSchemas(t *testing.T) {
+	type Tags []string
+	type Labels map[string]string
+	type Row []int
+	type Matrix []Row
+	type Post struct {
+		Tags   Tags   `json:"tags"`
+		Draft  *Tags  `json:"draft"`
+		Extra  Tags   `json:"extra,omitempty" validate:"omitempty"`
+		Short  Tags   `json:"short" validate:"dive,max=10"`
+		Labels Labels `json:"labels"`
+		Matrix Matrix `json:"matrix"`
+	}
+
+	c := NewConverterWithOpts(WithNamedCollectionSchemas())
+	assert.Equal(t, `export const TagsSchema = z.string().array()
+export type Tags = z.infer<typeof TagsSchema>
+
+export const LabelsSchema = z.record(z.string(), z.string())
+export type Labels = z.infer<typeof LabelsSchema>
+
+export const RowSchema = z.number().int().array()
+export type Row = z.infer<typeof RowSchema>
+
+export const MatrixSchema = RowSchema.array()
+export type Matrix = z.infer<typeof MatrixSchema>
+
+export const PostSchema = z.object({
+  tags: TagsSchema.nullable(),
+  draft: TagsSchema.nullable(),
+  extra: TagsSchema.optional(),
+  short: z.string().max(10).array().nullable(),
+  labels: LabelsSchema.nullable(),
+  matrix: MatrixSchema.nullable(),
+})
+export type Post = z.infer<typeof PostSchema>
+
+`, c.Convert(Post{}))
+
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	assert.Len(t, schemas, 1)
+	assert.Contains(t, schemas, "Post")
+
+	c = NewConverterWithOpts(WithNamedCollectionSchemas(), WithExplicitTypes(), WithAudit(), WithPrefix("API"))
+	assert.Equal(t, `export const APITagsSchema = z.string().array()
+export type APITags = z.infer<typeof APITagsSchema>
+
+export const APILabelsSchema = z.record(z.string(), z.string())
+export type APILabels = z.infer<typeof APILabelsSchema>
+
+export const APIRowSchema = z.number().int().array()
+export type APIRow = z.infer<typeof APIRowSchema>
+
+export const APIMatrixSchema = APIRowSchema.array()
+export type APIMatrix = z.infer<typeof APIMatrixSchema>
+
+export type APIPost = {
+  tags: APITags | null,
+  draft: APITags | null,
+  extra?: APITags | undefined,
+  short: string[] | null,
+  labels: APILabels | null,
+  matrix: APIMatrix | null,
+}
+export const APIPostSchemaShape = {
+  tags: APITagsSchema.nullable(),
+  draft: APITagsSchema.nullable(),
+  extra: APITagsSchema.optional(),
+  short: z.string().max(10).array().nullable(),
+  labels: APILabelsSchema.nullable(),
+  matrix: APIMatrixSchema.nullable(),
+}
+export const APIPostSchema: z.ZodType<APIPost> = z.object(APIPostSchemaShape)
+
+`, c.Convert(Post{}))
+}
+
+type collectionForest []collectionTree
+
+type collectionTree struct {
+	Name string           `json:"name"`
+	Kids collectionForest `json:"kids"`
+}
+
+func TestNamedCollectionSchemasRecursive(t *testing.T) {
+	c := NewConverterWithOpts(WithNamedCollectionSchemas())
+	assert.Equal(t, `export const collectionForestSchema = z.lazy(() => collectionTreeSchema).array()
+export type collectionForest = z.infer<typeof collectionForestSchema>
+
+export type collectionTree = {
+  name: string,
+  kids: collectionForest | null,
+}
+export const collectionTreeSchemaShape = {
+  name: z.string(),
+  kids: collectionForestSchema.nullable(),
+}
+export const collectionTreeSchema: z.ZodType<collectionTree> = z.object(collectionTreeSchemaShape)
+
+`, c.Convert(collectionTree{}))
+}
+
+type collectionNode map[string]collectionNode
+
+type collectionList []collectionMap
+
+type collectionMap map[string]collectionList
+
+func TestNamedCollectionSchemasSelfReferential(t *testing.T) {
+	type Menu struct {
+		Items collectionNode `json:"items"`
+		Lists collectionList `json:"lists"`
+	}
+
+	c := NewConverterWithOpts(WithNamedCollectionSchemas())
+	assert.Equal(t, `export interface collectionNode extends Record<string, collectionNode> {}
+export const collectionNodeSchema: z.ZodType<collectionNode> = z.record(z.string(), z.lazy(() => collectionNodeSchema))
+
+export const collectionMapSchema = z.record(z.string(), z.lazy(() => collectionListSchema))
+export type collectionMap = z.infer<typeof collectionMapSchema>
+
+export type collectionList = collectionMap[]
+export const collectionListSchema: z.ZodType<collectionList> = collectionMapSchema.array()
+
+export const MenuSchema = z.object({
+  items: collectionNodeSchema.nullable(),
+  lists: collectionListSchema.nullable(),
+})
+export type Menu = z.infer<typeof MenuSchema>
+
+`, c.Convert(Menu{}))
+}
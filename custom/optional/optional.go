@@ -3,6 +3,7 @@ package optional
 import (
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/hypersequent/zen"
 )
@@ -12,4 +13,13 @@ var (
 	OptionalFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
 		return fmt.Sprintf("%s.optional().nullish()", c.ConvertType(t.Elem(), validate, i))
 	}
+	// OptionalTSFunc returns the TS type of OptionalFunc's schema, see
+	// zen.WithCustomTSTypes.
+	OptionalTSFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		elem := c.ConvertTSType(t.Elem(), validate, i)
+		if strings.HasSuffix(elem, " | null | undefined") {
+			return elem
+		}
+		return elem + " | null | undefined"
+	}
 )
@@ -34,7 +34,7 @@ export type Profile = z.infer<typeof ProfileSchema>
 
 export const UserSchema = z.object({
   MaybeName: z.string().optional().nullish(),
-  MaybeAge: z.number().optional().nullish(),
+  MaybeAge: z.number().int().optional().nullish(),
   MaybeHeight: z.number().optional().nullish(),
   MaybeProfile: ProfileSchema.optional().nullish(),
 })
@@ -43,3 +43,45 @@ export type User = z.infer<typeof UserSchema>
 `,
 		c.Convert(User{}))
 }
+
+type Node struct {
+	Name     string                                      `json:"name"`
+	Next     optional.Optional[*Node]                    `json:"next"`
+	Children []optional.Optional[Node]                   `json:"children"`
+	Parent   optional.Optional[optional.Optional[*Node]] `json:"parent"`
+}
+
+func TestCustomRecursive(t *testing.T) {
+	c := zen.NewConverterWithOpts(
+		zen.WithCustomTypes(map[string]zen.CustomFn{
+			customoptional.OptionalType: customoptional.OptionalFunc,
+		}),
+		zen.WithCustomTSTypes(map[string]zen.CustomFn{
+			customoptional.OptionalType: customoptional.OptionalTSFunc,
+		}),
+	)
+
+	assert.Equal(t,
+		`export type Node = {
+  name: string,
+  next?: Node | null | undefined,
+  children: (Node | null | undefined)[] | null,
+  parent?: Node | null | undefined,
+}
+export const NodeSchemaShape = {
+  name: z.string(),
+  next: z.lazy(() => NodeSchema).optional().nullish(),
+  children: z.lazy(() => NodeSchema).optional().nullish().array().nullable(),
+  parent: z.lazy(() => NodeSchema).optional().nullish().optional().nullish(),
+}
+export const NodeSchema: z.ZodType<Node> = z.object(NodeSchemaShape)
+
+`,
+		c.Convert(Node{}))
+
+	// without a TS type, the custom type is unknown
+	c = zen.NewConverter(map[string]zen.CustomFn{
+		customoptional.OptionalType: customoptional.OptionalFunc,
+	})
+	assert.Contains(t, c.Convert(Node{}), "  next?: unknown,\n")
+}
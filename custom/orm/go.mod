@@ -0,0 +1,25 @@
+module github.com/hypersequent/zen/custom/orm
+
+go 1.21
+
+replace github.com/hypersequent/zen => ../..
+
+require (
+	github.com/hypersequent/zen v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.8.3
+	gorm.io/datatypes v1.2.4
+	gorm.io/gorm v1.25.12
+)
+
+require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-sql-driver/mysql v1.8.1 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	gorm.io/driver/mysql v1.5.6 // indirect
+)
@@ -0,0 +1,83 @@
+package orm
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/hypersequent/zen"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var (
+	DeletedAtType = "gorm.io/gorm.DeletedAt"
+	DeletedAtFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		// gorm's soft delete timestamp serialises to null until the record is
+		// deleted.
+		return c.ConvertType(timeType, "", i) + ".nullable()"
+	}
+
+	DateType = "gorm.io/datatypes.Date"
+	DateFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		return c.ConvertType(timeType, "", i)
+	}
+
+	TimeType = "gorm.io/datatypes.Time"
+	TimeFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		// The time of day serialises to a string, eg. "15:04:05".
+		return "z.string()"
+	}
+
+	JSONType = "gorm.io/datatypes.JSON"
+	JSONFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		// The raw JSON is embedded as is.
+		return "z.unknown()"
+	}
+
+	JSONMapType = "gorm.io/datatypes.JSONMap"
+	JSONMapFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		return "z.record(z.string(), z.unknown()).nullable()"
+	}
+
+	URLType = "gorm.io/datatypes.URL"
+	URLFunc = func(c *zen.Converter, t reflect.Type, validate string, i int) string {
+		return "z.string().url()"
+	}
+)
+
+// CustomTypes returns the custom types of gorm and gorm.io/datatypes, for
+// zen.WithCustomTypes.
+func CustomTypes() map[string]zen.CustomFn {
+	return map[string]zen.CustomFn{
+		DeletedAtType: DeletedAtFunc,
+		DateType:      DateFunc,
+		TimeType:      TimeFunc,
+		JSONType:      JSONFunc,
+		JSONMapType:   JSONMapFunc,
+		URLType:       URLFunc,
+	}
+}
+
+// SkipFields skips the fields of persistence models that do not belong in API
+// schemas, for zen.WithSkipFields: the fields gorm ignores, tagged `gorm:"-"`,
+// and the edges of ent entities, which are only set when eager-loaded.
+func SkipFields(field reflect.StructField) bool {
+	if tag := field.Tag.Get("gorm"); tag == "-" || tag == "-:all" {
+		return true
+	}
+
+	return isEntEdges(field)
+}
+
+// isEntEdges checks whether a field holds the edges of an ent entity, ie. a
+// field named Edges of a generated XEdges struct tracking the loaded edges.
+func isEntEdges(field reflect.StructField) bool {
+	t := field.Type
+	if field.Name != "Edges" || t.Kind() != reflect.Struct || !strings.HasSuffix(t.Name(), "Edges") {
+		return false
+	}
+
+	_, ok := t.FieldByName("loadedTypes")
+	return ok
+}
@@ -0,0 +1,65 @@
+package orm_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/hypersequent/zen"
+	"github.com/hypersequent/zen/custom/orm"
+)
+
+type Pet struct {
+	ID   int    `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// UserEdges mirrors the edges ent generates for a User entity.
+type UserEdges struct {
+	Pets        []*Pet `json:"pets,omitempty"`
+	loadedTypes [1]bool
+}
+
+type User struct {
+	gorm.Model
+	Name     string            `json:"name"`
+	Birthday datatypes.Date    `json:"birthday"`
+	Opens    datatypes.Time    `json:"opens"`
+	Settings datatypes.JSON    `json:"settings"`
+	Labels   datatypes.JSONMap `json:"labels"`
+	Website  datatypes.URL     `json:"website"`
+	Cache    []string          `json:"cache" gorm:"-"`
+	Edges    UserEdges         `json:"edges"`
+}
+
+func TestCustom(t *testing.T) {
+	c := zen.NewConverterWithOpts(
+		zen.WithCustomTypes(orm.CustomTypes()),
+		zen.WithSkipFields(orm.SkipFields),
+		zen.WithTimeFormat(zen.ISOStringDatetime),
+	)
+	assert.Equal(t, `export const ModelSchema = z.object({
+  ID: z.number().int().nonnegative(),
+  CreatedAt: z.string().datetime({ offset: true }),
+  UpdatedAt: z.string().datetime({ offset: true }),
+  DeletedAt: z.string().datetime({ offset: true }).nullable(),
+})
+export type Model = z.infer<typeof ModelSchema>
+
+export const UserSchema = z.object({
+  ...ModelSchema.shape,
+  name: z.string(),
+  birthday: z.string().datetime({ offset: true }),
+  opens: z.string(),
+  settings: z.unknown(),
+  labels: z.record(z.string(), z.unknown()).nullable(),
+  website: z.string().url(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	_ = UserEdges{loadedTypes: [1]bool{true}}
+}
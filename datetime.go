@@ -0,0 +1,198 @@
+package zen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Regexes of the elements of Go reference layouts. Numeric ranges are split
+// into two digits and the single digit time.Parse also accepts unless the
+// element is zero padded.
+const (
+	layoutMonth      = "0[1-9]|1[0-2]"
+	layoutDay        = "0[1-9]|[12][0-9]|3[01]"
+	layoutYearDay    = "00[1-9]|0[1-9][0-9]|[12][0-9]{2}|3[0-5][0-9]|36[0-6]"
+	layoutHour       = "[01][0-9]|2[0-3]"
+	layoutHour12     = "0[0-9]|1[0-2]"
+	layoutMinute     = "[0-5][0-9]"
+	layoutShortDigit = "[1-9]"
+	layoutAnyDigit   = "[0-9]"
+	layoutSignedHour = "[+-]0*(1?[0-9]|2[0-3])(?![0-9])"
+	layoutFraction   = "([.,][0-9]+)?"
+)
+
+// datetimeChecks returns the checks of a datetime=layout validation, which
+// go-validator applies with time.Parse. Layouts with a zod equivalent use it and
+// the others are converted into a regex.
+func datetimeChecks(layout string) string {
+	switch layout {
+	case time.DateOnly:
+		return ".date()"
+	case time.RFC3339, time.RFC3339Nano:
+		return ".datetime({ offset: true })"
+	}
+
+	return fmt.Sprintf(".regex(/%s/)", layoutRegexString(layout))
+}
+
+// layoutRegexString converts a Go reference layout into a regex matching the
+// strings time.Parse accepts, except that days are not checked against the month
+// and month and day names must be capitalized as in the layout.
+func layoutRegexString(layout string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for len(layout) > 0 {
+		elem, n, second := layoutElem(layout)
+		switch {
+		case n > 0:
+		case layout[0] == ' ':
+			// runs of spaces are equivalent
+			elem, n = " +", len(layout)-len(strings.TrimLeft(layout, " "))
+		default:
+			elem, n = strings.ReplaceAll(regexp.QuoteMeta(layout[:1]), "/", `\/`), 1
+		}
+		layout = layout[n:]
+
+		if !layoutDigitNext(layout) {
+			// a single digit can only be followed by a digit of the next element
+			elem = strings.ReplaceAll(elem, "(?![0-9])", "")
+		}
+		b.WriteString(elem)
+		if _, n, _ := layoutElem(layout); second && (n == 0 || layout[0] != '.' && layout[0] != ',') {
+			// seconds may be followed by a fraction absent from the layout
+			b.WriteString(layoutFraction)
+		}
+	}
+	b.WriteString("$")
+
+	return b.String()
+}
+
+// layoutDigitNext reports whether the rest of a layout may start with a digit.
+func layoutDigitNext(layout string) bool {
+	return len(layout) > 0 && ('0' <= layout[0] && layout[0] <= '9' || layout[0] == '_')
+}
+
+// layoutNames returns the alternation of the month or day names, abbreviated to
+// three letters if short.
+func layoutNames(n int, name func(int) string, short bool) string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = name(i)
+		if short {
+			names[i] = names[i][:3]
+		}
+	}
+
+	return "(" + strings.Join(names, "|") + ")"
+}
+
+// layoutElem returns the regex of the element of a Go reference layout at its
+// start, along with the length of the element, or 0 if it starts with a literal,
+// and whether it is seconds. Elements are matched as in time.nextStdChunk.
+func layoutElem(layout string) (string, int, bool) {
+	// getnum accepts a single digit unless fixed, but takes two if there are two
+	getnum := func(two, one string) string {
+		return fmt.Sprintf("(%s|%s(?![0-9]))", two, one)
+	}
+	// offsets of up to 24 hours and 60 minutes and seconds are accepted
+	offset := func(sep string, parts int) string {
+		return "[+-]([01][0-9]|2[0-4])" + strings.Repeat(sep+"([0-5][0-9]|60)", parts-1)
+	}
+	has := func(prefix string) bool {
+		return strings.HasPrefix(layout, prefix)
+	}
+	lowerAt := func(i int) bool {
+		return len(layout) > i && 'a' <= layout[i] && layout[i] <= 'z'
+	}
+
+	switch {
+	case has("January"):
+		return layoutNames(12, monthName, false), 7, false
+	case has("Jan") && !lowerAt(3):
+		return layoutNames(12, monthName, true), 3, false
+	case has("Monday"):
+		return layoutNames(7, dayName, false), 6, false
+	case has("Mon") && !lowerAt(3):
+		return layoutNames(7, dayName, true), 3, false
+	case has("MST"):
+		// abbreviations as accepted by time.parseTimeZone, or signed hours
+		return "(GMT" + layoutSignedHour + "|[A-Z]{3,4}T|WITA|ChST|MeST|[A-Z]{3}|" + layoutSignedHour + ")", 3, false
+	case has("01"):
+		return "(" + layoutMonth + ")", 2, false
+	case has("02"):
+		return "(" + layoutDay + ")", 2, false
+	case has("03"):
+		return "(" + layoutHour12 + ")", 2, false
+	case has("04"), has("05"):
+		return layoutMinute, 2, has("05")
+	case has("06"):
+		return "[0-9]{2}", 2, false
+	case has("002"):
+		return "(" + layoutYearDay + ")", 3, false
+	case has("15"):
+		return getnum(layoutHour, layoutAnyDigit), 2, false
+	case has("1"):
+		return getnum(layoutMonth, layoutShortDigit), 1, false
+	case has("2006"):
+		return "[0-9]{4}", 4, false
+	case has("2"):
+		return getnum(layoutDay, layoutShortDigit), 1, false
+	case has("_2006"):
+		return "_", 1, false
+	case has("_2"):
+		return " ?" + getnum(layoutDay, layoutShortDigit), 2, false
+	case has("__2"):
+		return " {0,2}" + getnum(layoutYearDay, "0?[1-9][0-9]?"), 3, false
+	case has("3"):
+		return getnum(layoutHour12, layoutAnyDigit), 1, false
+	case has("4"), has("5"):
+		return getnum(layoutMinute, layoutAnyDigit), 1, has("5")
+	case has("PM"):
+		return "(AM|PM)", 2, false
+	case has("pm"):
+		return "(am|pm)", 2, false
+	case has("-070000"):
+		return offset("", 3), 7, false
+	case has("-07:00:00"):
+		return offset(":", 3), 9, false
+	case has("-0700"):
+		return offset("", 2), 5, false
+	case has("-07:00"):
+		return offset(":", 2), 6, false
+	case has("-07"):
+		return offset("", 1), 3, false
+	case has("Z070000"):
+		return "(Z|" + offset("", 3) + ")", 7, false
+	case has("Z07:00:00"):
+		return "(Z|" + offset(":", 3) + ")", 9, false
+	case has("Z0700"):
+		return "(Z|" + offset("", 2) + ")", 5, false
+	case has("Z07:00"):
+		return "(Z|" + offset(":", 2) + ")", 6, false
+	case has("Z07"):
+		return "(Z|" + offset("", 1) + ")", 3, false
+	case has(".0"), has(",0"), has(".9"), has(",9"):
+		n := 1 + len(layout[1:]) - len(strings.TrimLeft(layout[1:], layout[1:2]))
+		if len(layout) > n && '0' <= layout[n] && layout[n] <= '9' {
+			return "", 0, false
+		}
+		if layout[1] == '0' {
+			return fmt.Sprintf("[.,][0-9]{%d}", n-1), n, false
+		}
+		// any number of digits, as for seconds without a fraction in the layout
+		return layoutFraction, n, false
+	}
+
+	return "", 0, false
+}
+
+func monthName(i int) string {
+	return time.Month(i + 1).String()
+}
+
+func dayName(i int) string {
+	return time.Weekday(i).String()
+}
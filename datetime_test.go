@@ -0,0 +1,44 @@
+package zen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatetimeChecks(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{time.DateOnly, ".date()"},
+		{time.RFC3339, ".datetime({ offset: true })"},
+		{time.RFC3339Nano, ".datetime({ offset: true })"},
+		{"01/02/2006", `.regex(/^(0[1-9]|1[0-2])\/(0[1-9]|[12][0-9]|3[01])\/[0-9]{4}$/)`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, datetimeChecks(tt.layout), tt.layout)
+	}
+}
+
+func TestLayoutRegexString(t *testing.T) {
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{time.TimeOnly, `^([01][0-9]|2[0-3]|[0-9]):[0-5][0-9]:[0-5][0-9]([.,][0-9]+)?$`},
+		{time.Kitchen, `^(0[0-9]|1[0-2]|[0-9]):[0-5][0-9](AM|PM)$`},
+		{time.StampMilli, `^(Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) + ?(0[1-9]|[12][0-9]|3[01]|[1-9]) +([01][0-9]|2[0-3]|[0-9]):[0-5][0-9]:[0-5][0-9][.,][0-9]{3}$`},
+		{"Monday 2006-002", `^(Sunday|Monday|Tuesday|Wednesday|Thursday|Friday|Saturday) +[0-9]{4}-(00[1-9]|0[1-9][0-9]|[12][0-9]{2}|3[0-5][0-9]|36[0-6])$`},
+		// a single digit hour must not be followed by another digit
+		{"150405", `^([01][0-9]|2[0-3]|[0-9](?![0-9]))[0-5][0-9][0-5][0-9]([.,][0-9]+)?$`},
+		{"1/2/06 3:4pm", `^(0[1-9]|1[0-2]|[1-9])\/(0[1-9]|[12][0-9]|3[01]|[1-9])\/[0-9]{2} +(0[0-9]|1[0-2]|[0-9]):([0-5][0-9]|[0-9])(am|pm)$`},
+		{"15:04:05.999 Z07:00", `^([01][0-9]|2[0-3]|[0-9]):[0-5][0-9]:[0-5][0-9]([.,][0-9]+)? +(Z|[+-]([01][0-9]|2[0-4]):([0-5][0-9]|60))$`},
+		{"2006-01-02 -0700 MST", `^[0-9]{4}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01]) +[+-]([01][0-9]|2[0-4])([0-5][0-9]|60) +(GMT[+-]0*(1?[0-9]|2[0-3])|[A-Z]{3,4}T|WITA|ChST|MeST|[A-Z]{3}|[+-]0*(1?[0-9]|2[0-3]))$`},
+		{"Month: _2 (2006)", `^Month: + ?(0[1-9]|[12][0-9]|3[01]|[1-9]) +\([0-9]{4}\)$`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, layoutRegexString(tt.layout), tt.layout)
+	}
+}
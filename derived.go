@@ -0,0 +1,89 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Derivation describes a schema derived from the schema of every converted
+// struct, see WithDerivedSchemas.
+type Derivation struct {
+	// Suffix is appended to the name of the struct, eg. "Update" for
+	// UserUpdateSchema.
+	Suffix string
+	// Pick keeps only the fields with the given JSON names. Structs with none
+	// of them get no derived schema.
+	Pick []string
+	// Omit leaves out the fields with the given JSON names.
+	Omit []string
+	// Partial makes all the fields optional.
+	Partial bool
+}
+
+// WithDerivedSchemas exports schemas derived from the schema of every
+// converted struct with .pick(), .omit() and .partial(), eg.
+// `export const UserUpdateSchema = UserSchema.omit({ id: true }).partial()`,
+// along with their inferred types, for the payloads of create and update
+// endpoints. Picked and omitted fields missing from a struct are ignored.
+// Derived schemas cannot be combined with WithAlwaysLazy.
+func WithDerivedSchemas(derivations ...Derivation) Opt {
+	return func(c *Converter) {
+		c.derivations = append(c.derivations, derivations...)
+	}
+}
+
+// derivedSchemas returns the schemas derived from the schema of a struct, see
+// WithDerivedSchemas. base is the z.object schema of the struct.
+func (c *Converter) derivedSchemas(t reflect.Type, base string) string {
+	if len(c.derivations) == 0 {
+		return ""
+	}
+
+	keys := make(map[string]bool)
+	for _, f := range c.jsonFields(t) {
+		keys[f.name] = true
+	}
+
+	output := strings.Builder{}
+	for _, d := range c.derivations {
+		schema := base
+		if len(d.Pick) > 0 {
+			mask := keyMask(d.Pick, keys)
+			if mask == "" {
+				continue
+			}
+			schema += fmt.Sprintf(".pick(%s)", mask)
+		}
+		if mask := keyMask(d.Omit, keys); mask != "" {
+			schema += fmt.Sprintf(".omit(%s)", mask)
+		}
+		if d.Partial {
+			schema += ".partial()"
+		}
+
+		name := c.structName(t) + d.Suffix
+		c.checkCollision(name, t)
+		output.WriteString(fmt.Sprintf("\nexport const %s = %s\n", schemaName(c.prefix, name), schema))
+		output.WriteString(fmt.Sprintf("export type %s = z.infer<typeof %s>",
+			c.prefix+name, schemaName(c.prefix, name)))
+	}
+
+	return output.String()
+}
+
+// keyMask returns the mask of .pick() and .omit() for the given keys present
+// in the struct, or "" if there are none.
+func keyMask(names []string, keys map[string]bool) string {
+	var mask []string
+	for _, name := range names {
+		if keys[name] {
+			mask = append(mask, propertyKey(name)+": true")
+		}
+	}
+	if len(mask) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("{ %s }", strings.Join(mask, ", "))
+}
@@ -0,0 +1,87 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerivedSchemas(t *testing.T) {
+	type Stamp struct {
+		CreatedAt string `json:"createdAt"`
+	}
+	type User struct {
+		Stamp
+		ID    string `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"e-mail"`
+	}
+	derived := WithDerivedSchemas(
+		Derivation{Suffix: "Update", Omit: []string{"id", "createdAt"}, Partial: true},
+		Derivation{Suffix: "Ref", Pick: []string{"id", "e-mail"}},
+	)
+
+	c := NewConverterWithOpts(derived)
+	assert.Equal(t, `export const StampSchema = z.object({
+  createdAt: z.string(),
+})
+export type Stamp = z.infer<typeof StampSchema>
+export const StampUpdateSchema = StampSchema.omit({ createdAt: true }).partial()
+export type StampUpdate = z.infer<typeof StampUpdateSchema>
+
+export const UserSchema = z.object({
+  ...StampSchema.shape,
+  id: z.string(),
+  name: z.string(),
+  "e-mail": z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+export const UserUpdateSchema = UserSchema.omit({ id: true, createdAt: true }).partial()
+export type UserUpdate = z.infer<typeof UserUpdateSchema>
+export const UserRefSchema = UserSchema.pick({ id: true, "e-mail": true })
+export type UserRef = z.infer<typeof UserRefSchema>
+
+`, c.Convert(User{}))
+	assert.Equal(t, []string{"Stamp"}, c.DependenciesOf("User"))
+
+	c = NewConverterWithOpts(derived, WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type Stamp = {
+  createdAt: string,
+}
+export const StampSchemaShape = {
+  createdAt: z.string(),
+}
+export const StampSchema: z.ZodType<Stamp> = z.object(StampSchemaShape)
+export const StampUpdateSchema = z.object(StampSchemaShape).omit({ createdAt: true }).partial()
+export type StampUpdate = z.infer<typeof StampUpdateSchema>
+
+export type User = {
+  id: string,
+  name: string,
+  "e-mail": string,
+} & Stamp
+export const UserSchemaShape = {
+  ...StampSchemaShape,
+  id: z.string(),
+  name: z.string(),
+  "e-mail": z.string(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+export const UserUpdateSchema = z.object(UserSchemaShape).omit({ id: true, createdAt: true }).partial()
+export type UserUpdate = z.infer<typeof UserUpdateSchema>
+export const UserRefSchema = z.object(UserSchemaShape).pick({ id: true, "e-mail": true })
+export type UserRef = z.infer<typeof UserRefSchema>
+
+`, c.Convert(User{}))
+
+	type UserUpdate struct {
+		Name string `json:"name"`
+	}
+	c = NewConverterWithOpts(derived)
+	c.AddType(User{})
+	_, ok := c.TryAddType(UserUpdate{}).(*NameCollisionError)
+	assert.True(t, ok)
+
+	c = NewConverterWithOpts(derived, WithAlwaysLazy())
+	assert.EqualError(t, c.TryAddType(User{}), "derived schemas are not supported with WithAlwaysLazy")
+}
@@ -0,0 +1,225 @@
+package zen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithDescriptions adds .describe() to the schemas of fields with a
+// description, for tools generating forms or OpenAPI documents from the
+// schemas. Descriptions come from the `zen_desc:"..."` struct tag, or else
+// from the doc or line comments of the fields in the Go packages in dirs,
+// matched by import path, type name and field name. Comments with swaggo
+// annotations use the text of their `@Description` lines instead of the whole
+// comment, followed by their `@example` values. The import paths of the
+// packages are derived from the nearest go.mod. Packages that cannot be parsed
+// fail AddType, see TryAddType.
+func WithDescriptions(dirs ...string) Opt {
+	return WithDescriptionsContext(context.Background(), dirs...)
+}
+
+// WithDescriptionsContext is like WithDescriptions, but stops loading the
+// packages once ctx is done, failing AddType with the context error.
+func WithDescriptionsContext(ctx context.Context, dirs ...string) Opt {
+	return func(c *Converter) {
+		c.describe = true
+		if c.docs == nil {
+			c.docs = make(map[string]string)
+		}
+		for _, dir := range dirs {
+			if c.docsErr != nil {
+				return
+			}
+			c.docsErr = parseFieldDocs(ctx, dir, c.docs)
+		}
+	}
+}
+
+// parseFieldDocs collects the comments of the struct fields declared in the Go
+// package in dir, keyed on the import path, type and field name, eg.
+// "github.com/hypersequent/zen.User.Name". Structs declared in functions are
+// included. The context is checked before parsing each file.
+func parseFieldDocs(ctx context.Context, dir string, docs map[string]string) error {
+	importPath, err := packageImportPath(dir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", dir, err)
+	}
+
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("parse %s: %w", dir, err)
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, entry.Name()), nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", dir, err)
+		}
+
+		// External test packages are compiled as a package of their own.
+		pkgPath := importPath
+		if strings.HasSuffix(file.Name.Name, "_test") {
+			pkgPath += "_test"
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			st, ok := spec.Type.(*ast.StructType)
+			if !ok {
+				return true
+			}
+			for _, field := range st.Fields.List {
+				doc := fieldDoc(field.Doc)
+				if doc == "" {
+					doc = fieldDoc(field.Comment)
+				}
+				if doc == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					docs[fmt.Sprintf("%s.%s.%s", pkgPath, spec.Name.Name, name.Name)] = doc
+				}
+			}
+			return true
+		})
+	}
+
+	return nil
+}
+
+// packageImportPath returns the import path of the package in dir, joining the
+// module path of the nearest go.mod with the path of dir inside the module.
+func packageImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		data, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			modulePath := modulePath(data)
+			if modulePath == "" {
+				return "", fmt.Errorf("parse %s: no module directive", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			return path.Join(modulePath, filepath.ToSlash(rel)), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("parse %s: no go.mod found", dir)
+		}
+	}
+}
+
+// modulePath returns the path of the module directive of a go.mod file.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "module" {
+			if unquoted, err := strconv.Unquote(fields[1]); err == nil {
+				return unquoted
+			}
+			return fields[1]
+		}
+	}
+
+	return ""
+}
+
+// fieldDoc returns the description in the comment of a field, joining its
+// lines into a single line. When the comment has swaggo annotations, the
+// `@Description` lines make up the description and the `@example` values are
+// appended to it, eg. "Currency code (example: EUR)". Other annotations are
+// left out.
+func fieldDoc(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+
+	var text, annotated, examples []string
+	for _, line := range strings.Split(group.Text(), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "@") {
+			text = append(text, line)
+			continue
+		}
+		name, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(name) {
+		case "@description":
+			annotated = append(annotated, value)
+		case "@example":
+			examples = append(examples, value)
+		}
+	}
+	if len(annotated) > 0 {
+		text = annotated
+	}
+
+	doc := strings.Join(strings.Fields(strings.Join(text, " ")), " ")
+	for _, example := range examples {
+		if example == "" {
+			continue
+		}
+		if doc != "" {
+			doc += " "
+		}
+		doc += fmt.Sprintf("(example: %s)", example)
+	}
+
+	return doc
+}
+
+// describeField returns the .describe() call of a field with a description,
+// see WithDescriptions.
+func (c *Converter) describeField(parent reflect.Type, field reflect.StructField) string {
+	desc := c.fieldDescription(parent, field)
+	if desc == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(".describe(%s)", strconv.Quote(desc))
+}
+
+// fieldDescription returns the description of a field, see WithDescriptions.
+func (c *Converter) fieldDescription(parent reflect.Type, field reflect.StructField) string {
+	if !c.describe {
+		return ""
+	}
+
+	desc, ok := field.Tag.Lookup("zen_desc")
+	if !ok && parent.Name() != "" {
+		name, _, _ := strings.Cut(parent.Name(), "[")
+		desc = c.docs[fmt.Sprintf("%s.%s.%s", parent.PkgPath(), name, field.Name)]
+	}
+
+	return desc
+}
@@ -0,0 +1,116 @@
+package zen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Invoice is documented for TestDescriptions.
+type Invoice struct {
+	// Number is the sequential
+	// number of the invoice.
+	Number int `json:"number" validate:"gte=1"`
+	// Currency is overridden by the tag.
+	Currency string  `json:"currency" zen_desc:"ISO 4217 code, eg. \"EUR\""`
+	Note     *string `json:"note"` // free text shown on the invoice
+	Paid     bool    `json:"paid"`
+}
+
+// Payment is documented with swaggo annotations for TestSwaggoDescriptions.
+type Payment struct {
+	// Amount is replaced by the annotation.
+	// @Description Amount in cents
+	// @example 1250
+	Amount int `json:"amount"`
+	// @Description Currency of the amount,
+	// @Description as an ISO 4217 code
+	// @Example EUR
+	Currency string `json:"currency"`
+	// Method of the payment.
+	// @Enums card,transfer
+	Method    string `json:"method"`
+	Reference string `json:"reference"` // @example INV-001
+}
+
+func TestDescriptions(t *testing.T) {
+	c := NewConverterWithOpts(WithDescriptions("."))
+	assert.Equal(t, `export const InvoiceSchema = z.object({
+  number: z.number().int().gte(1).describe("Number is the sequential number of the invoice."),
+  currency: z.string().describe("ISO 4217 code, eg. \"EUR\""),
+  note: z.string().nullable().describe("free text shown on the invoice"),
+  paid: z.boolean(),
+})
+export type Invoice = z.infer<typeof InvoiceSchema>
+
+`, c.Convert(Invoice{}))
+
+	c = NewConverterWithOpts(WithDescriptions(), WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type Invoice = {
+  number: number,
+  currency: string,
+  note: string | null,
+  paid: boolean,
+}
+export const InvoiceSchemaShape = {
+  number: z.number().int().gte(1),
+  currency: z.string().describe("ISO 4217 code, eg. \"EUR\""),
+  note: z.string().nullable(),
+  paid: z.boolean(),
+}
+export const InvoiceSchema: z.ZodType<Invoice> = z.object(InvoiceSchemaShape)
+
+`, c.Convert(Invoice{}))
+
+	c = NewConverterWithOpts()
+	assert.NotContains(t, c.Convert(Invoice{}), "describe")
+
+	c = NewConverterWithOpts(WithDescriptions("./does-not-exist"))
+	assert.ErrorContains(t, c.TryAddType(Invoice{}), "does-not-exist")
+	c = NewConverterWithOpts()
+	assert.ErrorContains(t, c.TryAddType(Invoice{}, WithDescriptions("./does-not-exist")), "does-not-exist")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c = NewConverterWithOpts(WithDescriptionsContext(ctx, "."))
+	assert.ErrorIs(t, c.TryAddType(Invoice{}), context.Canceled)
+}
+
+func TestSwaggoDescriptions(t *testing.T) {
+	c := NewConverterWithOpts(WithDescriptions("."))
+	assert.Equal(t, `export const PaymentSchema = z.object({
+  amount: z.number().int().describe("Amount in cents (example: 1250)"),
+  currency: z.string().describe("Currency of the amount, as an ISO 4217 code (example: EUR)"),
+  method: z.string().describe("Method of the payment."),
+  reference: z.string().describe("(example: INV-001)"),
+})
+export type Payment = z.infer<typeof PaymentSchema>
+
+`, c.Convert(Payment{}))
+}
+
+func TestFieldDocsImportPath(t *testing.T) {
+	// two packages named models in different modules
+	dir := t.TempDir()
+	for module, doc := range map[string]string{"a": "Name in a.", "b": "Name in b."} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, module, "models"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, module, "go.mod"),
+			[]byte("module \"example.com/"+module+"\" // comment\n\ngo 1.21\n"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, module, "models", "user.go"),
+			[]byte("package models\n\ntype User struct {\n\t// "+doc+"\n\tName string\n}\n"), 0o600))
+	}
+
+	docs := make(map[string]string)
+	require.NoError(t, parseFieldDocs(context.Background(), filepath.Join(dir, "a", "models"), docs))
+	require.NoError(t, parseFieldDocs(context.Background(), filepath.Join(dir, "b", "models"), docs))
+	assert.Equal(t, map[string]string{
+		"example.com/a/models.User.Name": "Name in a.",
+		"example.com/b/models.User.Name": "Name in b.",
+	}, docs)
+
+	assert.ErrorContains(t, parseFieldDocs(context.Background(), dir, docs), "no go.mod found")
+}
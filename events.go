@@ -0,0 +1,135 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// eventUnion is the type under which the names of event unions are registered,
+// so that structs of the same name are reported as a NameCollisionError.
+type eventUnion string
+
+// topicGroup is the type under which the names of topics are registered, see
+// eventUnion.
+type topicGroup string
+
+// AddEventUnion registers event payload structs keyed by event name and emits a
+// discriminated union schema over `{type, payload}` envelopes, together with a
+// TS map type from event names to payload types. With WithExplicitTypes the
+// union type is written out instead of inferred. The payload structs are
+// converted as if they were passed to AddType, pointers to them included. Events
+// are emitted in event name order so that the output does not depend on map
+// iteration order.
+// AddEventUnion panics with a NameCollisionError if the union or its map type
+// is named like another schema.
+func (c *Converter) AddEventUnion(name string, events map[string]interface{}) {
+	if len(events) == 0 {
+		panic("event union must have at least one event")
+	}
+	c.checkCollision(name, reflect.TypeOf(eventUnion("")))
+	c.checkCollision(name+"Map", reflect.TypeOf(eventUnion("")))
+
+	eventNames := make([]string, 0, len(events))
+	for eventName := range events {
+		eventNames = append(eventNames, eventName)
+	}
+	sort.Strings(eventNames)
+
+	payloads := make([]string, 0, len(eventNames))
+	for _, eventName := range eventNames {
+		payloads = append(payloads, c.addPayload(events[eventName]))
+	}
+
+	output := strings.Builder{}
+	explicit := c.explicitTypes || c.alwaysLazy
+	if explicit {
+		envelopes := make([]string, 0, len(eventNames))
+		for i, eventName := range eventNames {
+			envelopes = append(envelopes, fmt.Sprintf("{\n%stype: %s,\n%spayload: %s%s,\n}",
+				indentation(1), strconv.Quote(eventName), indentation(1), c.prefix, payloads[i]))
+		}
+		output.WriteString(fmt.Sprintf("export type %s%s = %s\n", c.prefix, name, strings.Join(envelopes, " | ")))
+		output.WriteString(fmt.Sprintf("export const %s: z.ZodType<%s%s> = z.discriminatedUnion(\"type\", [\n",
+			schemaName(c.prefix, name), c.prefix, name))
+	} else {
+		output.WriteString(fmt.Sprintf("export const %s = z.discriminatedUnion(\"type\", [\n", schemaName(c.prefix, name)))
+	}
+	for i, eventName := range eventNames {
+		output.WriteString(fmt.Sprintf(`%sz.object({
+%stype: z.literal(%s),
+%spayload: %s,
+%s}),
+`,
+			indentation(1),
+			indentation(2), strconv.Quote(eventName),
+			indentation(2), schemaName(c.prefix, payloads[i]),
+			indentation(1)))
+	}
+	output.WriteString("])\n")
+	if !explicit {
+		output.WriteString(fmt.Sprintf("export type %s%s = z.infer<typeof %s>\n",
+			c.prefix, name, schemaName(c.prefix, name)))
+	}
+
+	output.WriteString(fmt.Sprintf("export type %s%sMap = {\n", c.prefix, name))
+	for i, eventName := range eventNames {
+		output.WriteString(fmt.Sprintf("%s%s: %s%s,\n",
+			indentation(1), strconv.Quote(eventName), c.prefix, payloads[i]))
+	}
+	output.WriteString("}")
+
+	c.addSchema(name, output.String())
+}
+
+// addPayload converts an event or message struct, or the struct it points to,
+// and returns its name.
+func (c *Converter) addPayload(payload interface{}) string {
+	t := reflect.TypeOf(payload)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+	c.AddType(reflect.New(t).Elem().Interface())
+
+	return c.structName(t)
+}
+
+// AddTopic registers the message structs published on an async messaging topic
+// (e.g. a Kafka topic) and emits a named group of their schemas along with the
+// topic name and a TS union of the message types, so that consumers can look up
+// the schemas for the topic they subscribe to. The message structs are
+// converted as if they were passed to AddType, pointers to them included, and
+// are kept in argument order. AddTopic panics with a NameCollisionError if the
+// topic or its message type is named like another schema.
+func (c *Converter) AddTopic(name string, topic string, messages ...interface{}) {
+	if len(messages) == 0 {
+		panic("topic must have at least one message")
+	}
+	c.checkCollision(name, reflect.TypeOf(topicGroup("")))
+	c.checkCollision(name+"Message", reflect.TypeOf(topicGroup("")))
+
+	names := make([]string, 0, len(messages))
+	for _, message := range messages {
+		names = append(names, c.addPayload(message))
+	}
+
+	output := strings.Builder{}
+	output.WriteString(fmt.Sprintf("export const %s%s = {\n", c.prefix, name))
+	output.WriteString(fmt.Sprintf("%stopic: %s,\n", indentation(1), strconv.Quote(topic)))
+	output.WriteString(fmt.Sprintf("%smessages: {\n", indentation(1)))
+	types := make([]string, 0, len(names))
+	for _, n := range names {
+		output.WriteString(fmt.Sprintf("%s%s%s: %s,\n", indentation(2), c.prefix, n, schemaName(c.prefix, n)))
+		types = append(types, c.prefix+n)
+	}
+	output.WriteString(fmt.Sprintf("%s},\n", indentation(1)))
+	output.WriteString("} as const\n")
+	output.WriteString(fmt.Sprintf("export type %s%sMessage = %s", c.prefix, name, strings.Join(types, " | ")))
+
+	c.addSchema(name, output.String())
+}
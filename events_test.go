@@ -0,0 +1,252 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventUnion(t *testing.T) {
+	type UserCreated struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type UserDeleted struct {
+		ID string `json:"id"`
+	}
+
+	c := NewConverter(nil)
+	c.AddEventUnion("ServerEvent", map[string]interface{}{
+		"user.deleted": UserDeleted{},
+		"user.created": UserCreated{},
+	})
+	assert.Equal(t, `export const UserCreatedSchema = z.object({
+  id: z.string(),
+  name: z.string(),
+})
+export type UserCreated = z.infer<typeof UserCreatedSchema>
+
+export const UserDeletedSchema = z.object({
+  id: z.string(),
+})
+export type UserDeleted = z.infer<typeof UserDeletedSchema>
+
+export const ServerEventSchema = z.discriminatedUnion("type", [
+  z.object({
+    type: z.literal("user.created"),
+    payload: UserCreatedSchema,
+  }),
+  z.object({
+    type: z.literal("user.deleted"),
+    payload: UserDeletedSchema,
+  }),
+])
+export type ServerEvent = z.infer<typeof ServerEventSchema>
+export type ServerEventMap = {
+  "user.created": UserCreated,
+  "user.deleted": UserDeleted,
+}
+
+`, c.Export())
+}
+
+func TestEventUnionPrefix(t *testing.T) {
+	type Ping struct {
+		At int `json:"at"`
+	}
+
+	c := Converter{settings: settings{prefix: "Ws"}, outputs: make(map[string]entry)}
+	c.AddEventUnion("Event", map[string]interface{}{"ping": Ping{}})
+	assert.Equal(t, `export const WsPingSchema = z.object({
+  at: z.number().int(),
+})
+export type WsPing = z.infer<typeof WsPingSchema>
+
+export const WsEventSchema = z.discriminatedUnion("type", [
+  z.object({
+    type: z.literal("ping"),
+    payload: WsPingSchema,
+  }),
+])
+export type WsEvent = z.infer<typeof WsEventSchema>
+export type WsEventMap = {
+  "ping": WsPing,
+}
+
+`, c.Export())
+}
+
+func TestEventUnionEmpty(t *testing.T) {
+	c := NewConverter(nil)
+	assert.Panics(t, func() {
+		c.AddEventUnion("Event", nil)
+	})
+}
+
+func TestEventUnionCollision(t *testing.T) {
+	type ServerEvent struct {
+		ID string `json:"id"`
+	}
+	type Ping struct {
+		At int `json:"at"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(ServerEvent{})
+	assert.PanicsWithError(t, "type name collision for ServerEvent: github.com/hypersequent/zen.ServerEvent and event union ServerEvent", func() {
+		c.AddEventUnion("ServerEvent", map[string]interface{}{"ping": Ping{}})
+	})
+
+	c = NewConverter(nil)
+	c.AddEventUnion("Ping", map[string]interface{}{"ping": ServerEvent{}})
+	_, ok := c.TryAddType(Ping{}).(*NameCollisionError)
+	assert.True(t, ok)
+}
+
+func TestEventUnionPointerPayload(t *testing.T) {
+	type Ping struct {
+		At int `json:"at"`
+	}
+
+	c := NewConverterWithOpts(WithPackageQualifiedNames())
+	c.AddEventUnion("Event", map[string]interface{}{"ping": &Ping{}})
+	c.AddTopic("Pings", "pings.v1", &Ping{})
+	assert.Equal(t, `export const ZenPingSchema = z.object({
+  at: z.number().int(),
+})
+export type ZenPing = z.infer<typeof ZenPingSchema>
+
+export const EventSchema = z.discriminatedUnion("type", [
+  z.object({
+    type: z.literal("ping"),
+    payload: ZenPingSchema,
+  }),
+])
+export type Event = z.infer<typeof EventSchema>
+export type EventMap = {
+  "ping": ZenPing,
+}
+
+export const Pings = {
+  topic: "pings.v1",
+  messages: {
+    ZenPing: ZenPingSchema,
+  },
+} as const
+export type PingsMessage = ZenPing
+
+`, c.Export())
+}
+
+func TestTopic(t *testing.T) {
+	type OrderCreated struct {
+		ID string `json:"id"`
+	}
+	type OrderCancelled struct {
+		ID     string `json:"id"`
+		Reason string `json:"reason"`
+	}
+
+	c := NewConverter(nil)
+	c.AddTopic("OrdersTopic", "orders.v1", OrderCreated{}, OrderCancelled{})
+	assert.Equal(t, `export const OrderCreatedSchema = z.object({
+  id: z.string(),
+})
+export type OrderCreated = z.infer<typeof OrderCreatedSchema>
+
+export const OrderCancelledSchema = z.object({
+  id: z.string(),
+  reason: z.string(),
+})
+export type OrderCancelled = z.infer<typeof OrderCancelledSchema>
+
+export const OrdersTopic = {
+  topic: "orders.v1",
+  messages: {
+    OrderCreated: OrderCreatedSchema,
+    OrderCancelled: OrderCancelledSchema,
+  },
+} as const
+export type OrdersTopicMessage = OrderCreated | OrderCancelled
+
+`, c.Export())
+}
+
+func TestTopicCollision(t *testing.T) {
+	type Orders struct {
+		IDs []string `json:"ids"`
+	}
+	type OrderCreated struct {
+		ID string `json:"id"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(Orders{})
+	assert.PanicsWithError(t, "type name collision for Orders: github.com/hypersequent/zen.Orders and topic Orders", func() {
+		c.AddTopic("Orders", "orders.v1", OrderCreated{})
+	})
+
+	type OrdersMessage struct{}
+	c = NewConverter(nil)
+	c.AddTopic("Orders", "orders.v1", OrderCreated{})
+	_, ok := c.TryAddType(OrdersMessage{}).(*NameCollisionError)
+	assert.True(t, ok)
+}
+
+func TestTopicEmpty(t *testing.T) {
+	c := NewConverter(nil)
+	assert.Panics(t, func() {
+		c.AddTopic("OrdersTopic", "orders.v1")
+	})
+}
+
+func TestEventUnionExplicitTypes(t *testing.T) {
+	type Ping struct {
+		At int `json:"at"`
+	}
+	type Pong struct {
+		At int `json:"at"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	c.AddEventUnion("Event", map[string]interface{}{"ping": Ping{}, "pong": Pong{}})
+	assert.Equal(t, `export type Ping = {
+  at: number,
+}
+export const PingSchemaShape = {
+  at: z.number().int(),
+}
+export const PingSchema: z.ZodType<Ping> = z.object(PingSchemaShape)
+
+export type Pong = {
+  at: number,
+}
+export const PongSchemaShape = {
+  at: z.number().int(),
+}
+export const PongSchema: z.ZodType<Pong> = z.object(PongSchemaShape)
+
+export type Event = {
+  type: "ping",
+  payload: Ping,
+} | {
+  type: "pong",
+  payload: Pong,
+}
+export const EventSchema: z.ZodType<Event> = z.discriminatedUnion("type", [
+  z.object({
+    type: z.literal("ping"),
+    payload: PingSchema,
+  }),
+  z.object({
+    type: z.literal("pong"),
+    payload: PongSchema,
+  }),
+])
+export type EventMap = {
+  "ping": Ping,
+  "pong": Pong,
+}
+
+`, c.Export())
+}
@@ -0,0 +1,399 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// mobileTarget describes how Go types are rendered in a mobile language.
+type mobileTarget struct {
+	lang    string
+	scalars map[reflect.Kind]string
+	// bytes is the type of []byte, which encoding/json encodes as base64.
+	bytes    string
+	list     func(elem string) string
+	dict     func(key, elem string) string
+	keys     map[reflect.Kind]string
+	any      string
+	keywords map[string]bool
+}
+
+var kotlinTarget = mobileTarget{
+	lang: "Kotlin",
+	scalars: map[reflect.Kind]string{
+		reflect.Bool:    "Boolean",
+		reflect.Int:     "Long",
+		reflect.Int8:    "Byte",
+		reflect.Int16:   "Short",
+		reflect.Int32:   "Int",
+		reflect.Int64:   "Long",
+		reflect.Uint:    "ULong",
+		reflect.Uint8:   "UByte",
+		reflect.Uint16:  "UShort",
+		reflect.Uint32:  "UInt",
+		reflect.Uint64:  "ULong",
+		reflect.Float32: "Float",
+		reflect.Float64: "Double",
+		reflect.String:  "String",
+	},
+	bytes: "String",
+	list:  func(elem string) string { return fmt.Sprintf("List<%s>", elem) },
+	dict:  func(key, elem string) string { return fmt.Sprintf("Map<%s, %s>", key, elem) },
+	// kotlinx.serialization encodes primitive keys as JSON strings
+	keys: map[reflect.Kind]string{
+		reflect.Int:    "Long",
+		reflect.Int8:   "Byte",
+		reflect.Int16:  "Short",
+		reflect.Int32:  "Int",
+		reflect.Int64:  "Long",
+		reflect.Uint:   "ULong",
+		reflect.Uint8:  "UByte",
+		reflect.Uint16: "UShort",
+		reflect.Uint32: "UInt",
+		reflect.Uint64: "ULong",
+		reflect.String: "String",
+	},
+	any: "JsonElement",
+	keywords: map[string]bool{
+		"as": true, "break": true, "class": true, "continue": true, "do": true, "else": true, "false": true,
+		"for": true, "fun": true, "if": true, "in": true, "interface": true, "is": true, "null": true,
+		"object": true, "package": true, "return": true, "super": true, "this": true, "throw": true,
+		"true": true, "try": true, "typealias": true, "typeof": true, "val": true, "var": true,
+		"when": true, "while": true,
+	},
+}
+
+var swiftTarget = mobileTarget{
+	lang: "Swift",
+	scalars: map[reflect.Kind]string{
+		reflect.Bool:    "Bool",
+		reflect.Int:     "Int",
+		reflect.Int8:    "Int8",
+		reflect.Int16:   "Int16",
+		reflect.Int32:   "Int32",
+		reflect.Int64:   "Int64",
+		reflect.Uint:    "UInt",
+		reflect.Uint8:   "UInt8",
+		reflect.Uint16:  "UInt16",
+		reflect.Uint32:  "UInt32",
+		reflect.Uint64:  "UInt64",
+		reflect.Float32: "Float",
+		reflect.Float64: "Double",
+		reflect.String:  "String",
+	},
+	// JSONDecoder decodes Data from base64 by default
+	bytes: "Data",
+	list:  func(elem string) string { return fmt.Sprintf("[%s]", elem) },
+	dict:  func(key, elem string) string { return fmt.Sprintf("[%s: %s]", key, elem) },
+	// Codable encodes dictionaries with other keys than String as arrays, so
+	// integer keys are decoded as the strings they are in JSON.
+	keys: map[reflect.Kind]string{
+		reflect.Int:    "String",
+		reflect.Int8:   "String",
+		reflect.Int16:  "String",
+		reflect.Int32:  "String",
+		reflect.Int64:  "String",
+		reflect.Uint:   "String",
+		reflect.Uint8:  "String",
+		reflect.Uint16: "String",
+		reflect.Uint32: "String",
+		reflect.Uint64: "String",
+		reflect.String: "String",
+	},
+	keywords: map[string]bool{
+		"as": true, "associatedtype": true, "break": true, "case": true, "catch": true, "class": true,
+		"continue": true, "default": true, "defer": true, "deinit": true, "do": true, "else": true,
+		"enum": true, "extension": true, "fallthrough": true, "false": true, "fileprivate": true, "for": true,
+		"func": true, "guard": true, "if": true, "import": true, "in": true, "init": true, "inout": true,
+		"internal": true, "is": true, "let": true, "nil": true, "operator": true, "private": true,
+		"protocol": true, "public": true, "repeat": true, "rethrows": true, "return": true, "self": true,
+		"static": true, "struct": true, "subscript": true, "super": true, "switch": true, "throw": true,
+		"throws": true, "true": true, "try": true, "typealias": true, "var": true, "where": true, "while": true,
+	},
+}
+
+// mobileField is a JSON property of a struct, with the fields of embedded
+// structs promoted as encoding/json does.
+type mobileField struct {
+	name     string
+	jsonName string
+	typ      reflect.Type
+	optional bool
+	nullable bool
+	validate string
+	desc     string
+}
+
+// ExportKotlin returns the source of a Kotlin file, in the given package, with
+// a kotlinx.serialization data class for every struct converted so far.
+// Properties omitted from JSON when empty are nullable and default to null.
+// Types without a Kotlin counterpart, eg. custom types, fail the export. The
+// Kotlin target is experimental.
+func (c *Converter) ExportKotlin(pkg string) (string, error) {
+	output := strings.Builder{}
+	output.WriteString(fmt.Sprintf(`// Code generated by zen. DO NOT EDIT.
+
+package %s
+
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+import kotlinx.serialization.json.JsonElement
+`, pkg))
+
+	for _, name := range c.convertedStructs() {
+		conv := c.convertedAs(name)
+		fields, err := conv.mobileFields(kotlinTarget.lang, c.names[name])
+		if err != nil {
+			return "", err
+		}
+
+		output.WriteString(fmt.Sprintf("\n@Serializable\ndata class %s%s(\n", c.prefix, name))
+		for _, f := range fields {
+			typ, err := conv.mobileType(kotlinTarget, f.typ)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", name, f.name, err)
+			}
+			typ, def := strings.TrimSuffix(typ, "?"), ""
+			if f.optional {
+				def = " = null"
+			}
+			if f.optional || f.nullable {
+				typ += "?"
+			}
+			// $ starts a template in Kotlin strings
+			serialName := strings.ReplaceAll(strconv.Quote(f.jsonName), "$", `\$`)
+			output.WriteString(fmt.Sprintf("    @SerialName(%s) val %s: %s%s,\n",
+				serialName, kotlinTarget.identifier(f.name), typ, def))
+		}
+		output.WriteString(")\n")
+	}
+
+	return output.String(), nil
+}
+
+// ExportSwift returns the source of a Swift file with a Codable struct for
+// every struct converted so far. Properties that are omitted or null in JSON are
+// optionals. Types without a Swift counterpart, eg. interfaces and custom types,
+// fail the export. The Swift target is experimental.
+func (c *Converter) ExportSwift() (string, error) {
+	output := strings.Builder{}
+	output.WriteString("// Code generated by zen. DO NOT EDIT.\n\nimport Foundation\n")
+
+	for _, name := range c.convertedStructs() {
+		conv := c.convertedAs(name)
+		fields, err := conv.mobileFields(swiftTarget.lang, c.names[name])
+		if err != nil {
+			return "", err
+		}
+
+		output.WriteString(fmt.Sprintf("\nstruct %s%s: Codable {\n", c.prefix, name))
+		keys := strings.Builder{}
+		renamed := false
+		for _, f := range fields {
+			typ, err := conv.mobileType(swiftTarget, f.typ)
+			if err != nil {
+				return "", fmt.Errorf("%s.%s: %w", name, f.name, err)
+			}
+			typ = strings.TrimSuffix(typ, "?")
+			if f.optional || f.nullable {
+				typ += "?"
+			}
+			id := swiftTarget.identifier(f.name)
+			output.WriteString(fmt.Sprintf("    let %s: %s\n", id, typ))
+
+			if id == f.jsonName {
+				keys.WriteString(fmt.Sprintf("        case %s\n", id))
+			} else {
+				keys.WriteString(fmt.Sprintf("        case %s = %s\n", id, strconv.Quote(f.jsonName)))
+				renamed = true
+			}
+		}
+		if renamed {
+			output.WriteString(fmt.Sprintf("\n    enum CodingKeys: String, CodingKey {\n%s    }\n", keys.String()))
+		}
+		output.WriteString("}\n")
+	}
+
+	return output.String(), nil
+}
+
+// convertedStructs returns the names of the structs converted so far, in the
+// order of their schemas. Branded types are left out, see WithBrandedTypes.
+func (c *Converter) convertedStructs() []string {
+	var sorted []string
+	for name, t := range c.names {
+		if _, ok := c.outputs[name]; ok && t.Kind() == reflect.Struct {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return c.outputs[sorted[i]].order < c.outputs[sorted[j]].order
+	})
+
+	return sorted
+}
+
+// mobileFields returns the JSON properties of a struct, including promoted
+// fields, see jsonFields. lang names the target in errors.
+func (c *Converter) mobileFields(lang string, t reflect.Type) ([]mobileField, error) {
+	if embedded := c.embeddedCustomType(t, map[reflect.Type]bool{}); embedded != nil {
+		return nil, fmt.Errorf("embedded custom type %s has no %s type", embedded, lang)
+	}
+
+	var fields []mobileField
+	for _, f := range c.jsonFields(t) {
+		if c.isNever(f.field) {
+			continue
+		}
+		if _, ok := c.overrideField(f.parent, f.field); ok {
+			return nil, fmt.Errorf("overridden field %s.%s has no %s type", f.parent.Name(), f.field.Name, lang)
+		}
+		if parseZenTag(f.field).typ != "" {
+			return nil, fmt.Errorf("field %s.%s with a zen type has no %s type", f.parent.Name(), f.field.Name, lang)
+		}
+
+		optional, nullable := c.fieldPresence(f.field)
+		fields = append(fields, mobileField{
+			f.field.Name, f.name, f.field.Type, optional || f.optional, nullable,
+			c.fieldValidate(f.field), c.fieldDescription(f.parent, f.field),
+		})
+	}
+
+	return fields, nil
+}
+
+// embeddedCustomType returns the custom type embedded in a struct, directly or
+// through embedded structs, if any.
+func (c *Converter) embeddedCustomType(t reflect.Type, path map[reflect.Type]bool) reflect.Type {
+	path[t] = true
+	defer delete(path, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		embedded, _, ok := c.embeddedStruct(t.Field(i))
+		if !ok || path[embedded] || c.isExcluded(embedded) {
+			continue
+		}
+		if _, custom := c.custom[getFullName(embedded)]; custom {
+			return embedded
+		}
+		if custom := c.embeddedCustomType(embedded, path); custom != nil {
+			return custom
+		}
+	}
+
+	return nil
+}
+
+// mobileType returns the type of a Go type in the target language. Pointers are
+// nullable, although the nullability of fields is decided by their tags, and
+// nested structs must have been converted.
+func (c *Converter) mobileType(target mobileTarget, t reflect.Type) (string, error) {
+	if t.Kind() == reflect.Ptr {
+		elem, err := c.mobileType(target, t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(elem, "?") + "?", nil
+	}
+
+	if _, ok := c.custom[getFullName(t)]; ok {
+		return "", fmt.Errorf("custom type %s has no %s type", t, target.lang)
+	}
+
+	if c.isMappedError(t) {
+		if len(c.errorTypes) > 0 {
+			return "", fmt.Errorf("error union %s has no %s type", c.errorName, target.lang)
+		}
+		return target.scalars[reflect.String], nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return target.bytes, nil
+		}
+		elem, err := c.mobileType(target, t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return target.list(elem), nil
+
+	case reflect.Map:
+		key, ok := target.keys[t.Key().Kind()]
+		if !ok {
+			if !t.Key().Implements(textMarshalerType) {
+				return "", fmt.Errorf("map key %s has no %s type", t.Key(), target.lang)
+			}
+			key = target.scalars[reflect.String]
+		}
+		elem, err := c.mobileType(target, t.Elem())
+		if err != nil {
+			return "", err
+		}
+		return target.dict(key, elem), nil
+
+	case reflect.Struct:
+		name := typeName(t)
+		switch {
+		case name == "":
+			return "", fmt.Errorf("anonymous struct has no %s type", target.lang)
+		case name == "Time":
+			if c.timeFormat == UnixNumber {
+				return target.scalars[reflect.Int64], nil
+			}
+			return target.scalars[reflect.String], nil
+		case c.isExcluded(t):
+			if target.any == "" {
+				return "", fmt.Errorf("excluded type %s has no %s type", t, target.lang)
+			}
+			return target.any, nil
+		}
+
+		name = c.structName(t)
+		if _, ok := c.outputs[name]; !ok {
+			return "", fmt.Errorf("type %s has not been converted", name)
+		}
+		return c.prefix + name, nil
+
+	case reflect.Interface:
+		if target.any == "" {
+			return "", fmt.Errorf("interface %s has no %s type", t, target.lang)
+		}
+		return target.any, nil
+	}
+
+	if scalar, ok := target.scalars[t.Kind()]; ok {
+		return scalar, nil
+	}
+
+	return "", fmt.Errorf("%s has no %s type", t, target.lang)
+}
+
+// identifier returns the property name of a Go field in the target language,
+// in lower camel case and escaped with backticks if it is a keyword.
+func (target mobileTarget) identifier(name string) string {
+	runes := []rune(name)
+	upper := 0
+	for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+		upper++
+	}
+	// keep the last capital of an initialism starting a word, ie. URLPath
+	// becomes urlPath
+	if upper > 1 && upper < len(runes) && unicode.IsLower(runes[upper]) {
+		upper--
+	}
+	for i := 0; i < upper; i++ {
+		runes[i] = unicode.ToLower(runes[i])
+	}
+
+	id := string(runes)
+	if target.keywords[id] {
+		return "`" + id + "`"
+	}
+
+	return id
+}
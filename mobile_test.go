@@ -0,0 +1,169 @@
+package zen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mobileBase struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type mobilePost struct {
+	Title string `json:"title" validate:"required"`
+}
+
+type mobileUser struct {
+	mobileBase
+	Name    string            `json:"name"`
+	Email   *string           `json:"email,omitempty"`
+	Posts   []mobilePost      `json:"posts"`
+	Pinned  []*mobilePost     `json:"pinned" validate:"required"`
+	Labels  map[int]string    `json:"labels"`
+	Created time.Time         `json:"created"`
+	Avatar  []byte            `json:"avatar"`
+	In      bool              `json:"$in"`
+	URLPath string            `json:"url_path"`
+	Secret  string            `json:"-"`
+	Extra   map[string]string `json:"extra,omitempty"`
+}
+
+func TestExportKotlin(t *testing.T) {
+	c := NewConverter(nil)
+	c.AddType(mobileUser{})
+
+	kotlin, err := c.ExportKotlin("com.example.api")
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by zen. DO NOT EDIT.
+
+package com.example.api
+
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+import kotlinx.serialization.json.JsonElement
+
+@Serializable
+data class mobileBase(
+    @SerialName("id") val id: String,
+    @SerialName("name") val name: String,
+)
+
+@Serializable
+data class mobilePost(
+    @SerialName("title") val title: String,
+)
+
+@Serializable
+data class mobileUser(
+    @SerialName("id") val id: String,
+    @SerialName("name") val name: String,
+    @SerialName("email") val email: String? = null,
+    @SerialName("posts") val posts: List<mobilePost>?,
+    @SerialName("pinned") val pinned: List<mobilePost?>,
+    @SerialName("labels") val labels: Map<Long, String>?,
+    @SerialName("created") val created: String,
+    @SerialName("avatar") val avatar: String?,
+    @SerialName("\$in") val `+"`in`"+`: Boolean,
+    @SerialName("url_path") val urlPath: String,
+    @SerialName("extra") val extra: Map<String, String>? = null,
+)
+`, kotlin)
+}
+
+func TestExportSwift(t *testing.T) {
+	c := NewConverterWithOpts(WithPrefix("Api"), WithTimeFormat(UnixNumber))
+	c.AddType(mobileUser{})
+
+	swift, err := c.ExportSwift()
+	require.NoError(t, err)
+	assert.Equal(t, `// Code generated by zen. DO NOT EDIT.
+
+import Foundation
+
+struct ApimobileBase: Codable {
+    let id: String
+    let name: String
+}
+
+struct ApimobilePost: Codable {
+    let title: String
+}
+
+struct ApimobileUser: Codable {
+    let id: String
+    let name: String
+    let email: String?
+    let posts: [ApimobilePost]?
+    let pinned: [ApimobilePost?]
+    let labels: [String: String]?
+    let created: Int64
+    let avatar: Data?
+    let `+"`in`"+`: Bool
+    let urlPath: String
+    let extra: [String: String]?
+
+    enum CodingKeys: String, CodingKey {
+        case id
+        case name
+        case email
+        case posts
+        case pinned
+        case labels
+        case created
+        case avatar
+        case `+"`in`"+` = "$in"
+        case urlPath = "url_path"
+        case extra
+    }
+}
+`, swift)
+}
+
+func TestExportMobileUnsupported(t *testing.T) {
+	type Event struct {
+		Payload interface{} `json:"payload"`
+	}
+	type Inline struct {
+		Point struct{ X int } `json:"point"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(Event{})
+	_, err := c.ExportKotlin("api")
+	assert.NoError(t, err)
+	_, err = c.ExportSwift()
+	assert.EqualError(t, err, "Event.Payload: interface interface {} has no Swift type")
+
+	c = NewConverter(nil)
+	c.AddType(Inline{})
+	_, err = c.ExportKotlin("api")
+	assert.EqualError(t, err, "Inline.Point: anonymous struct has no Kotlin type")
+
+	// zen types are zod schemas, which the Go type of the field may contradict
+	type Tagged struct {
+		Amount string `json:"amount" zen:"type=z.number()"`
+	}
+	c = NewConverter(nil)
+	c.AddType(Tagged{})
+	_, err = c.ExportKotlin("api")
+	assert.EqualError(t, err, "field Tagged.Amount with a zen type has no Kotlin type")
+	_, err = c.ExportSwift()
+	assert.EqualError(t, err, "field Tagged.Amount with a zen type has no Swift type")
+}
+
+func TestMobileIdentifier(t *testing.T) {
+	for name, want := range map[string]string{
+		"ID":      "id",
+		"UserID":  "userID",
+		"URLPath": "urlPath",
+		"Name":    "name",
+		"X":       "x",
+		"Class":   "`class`",
+	} {
+		assert.Equal(t, want, kotlinTarget.identifier(name), name)
+	}
+}
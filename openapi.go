@@ -0,0 +1,457 @@
+package zen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSONSchema is a schema of an OpenAPI 3.1 document, see OpenAPISchemas.
+type JSONSchema struct {
+	Ref                  string                 `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 SchemaType             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                 `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Const                interface{}            `json:"const,omitempty" yaml:"const,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty" yaml:"exclusiveMinimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	ExclusiveMaximum     *float64               `json:"exclusiveMaximum,omitempty" yaml:"exclusiveMaximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	MinProperties        *int                   `json:"minProperties,omitempty" yaml:"minProperties,omitempty"`
+	MaxProperties        *int                   `json:"maxProperties,omitempty" yaml:"maxProperties,omitempty"`
+	AnyOf                []*JSONSchema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+	OneOf                []*JSONSchema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AllOf                []*JSONSchema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+}
+
+// SchemaType is the type of a JSON Schema, encoded as a string unless it lists
+// several types, eg. ["string", "null"].
+type SchemaType []string
+
+// MarshalJSON encodes a single type as a string.
+func (t SchemaType) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+
+	return json.Marshal([]string(t))
+}
+
+// MarshalYAML encodes a single type as a string.
+func (t SchemaType) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+
+	return []string(t), nil
+}
+
+// OpenAPISchemas returns the schemas of the structs converted so far, keyed by
+// their names, for the components section of an OpenAPI 3.1 document. The
+// schemas reference each other as "#/components/schemas/<name>". Types,
+// presence, nullability, descriptions and the validations with a JSON Schema
+// counterpart are consistent with the zod schemas, while the others, eg. those
+// translated to refinements, are left out. Types without a JSON Schema, eg.
+// custom types, fail the export.
+func (c *Converter) OpenAPISchemas() (map[string]*JSONSchema, error) {
+	schemas := make(map[string]*JSONSchema)
+	for _, name := range c.convertedStructs() {
+		schema, err := c.convertedAs(name).structJSONSchema(c.names[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		schemas[c.prefix+name] = schema
+	}
+
+	// error unions are registered under the error interface, see convertError
+	for name, t := range c.names {
+		if _, ok := c.outputs[name]; !ok || t != errorType {
+			continue
+		}
+		conv := c.convertedAs(name)
+		union := &JSONSchema{}
+		for _, t := range conv.errorTypes {
+			union.OneOf = append(union.OneOf, conv.refJSONSchema(t))
+		}
+		schemas[c.prefix+name] = union
+	}
+
+	return schemas, nil
+}
+
+// refJSONSchema returns a reference to the schema of a named struct.
+func (c *Converter) refJSONSchema(t reflect.Type) *JSONSchema {
+	return &JSONSchema{Ref: "#/components/schemas/" + c.prefix + c.structName(t)}
+}
+
+// structJSONSchema returns the object schema of a struct, intersected with the
+// structs declared with Intersect.
+func (c *Converter) structJSONSchema(t reflect.Type) (*JSONSchema, error) {
+	fields, err := c.mobileFields("OpenAPI", t)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &JSONSchema{Type: SchemaType{"object"}, Properties: make(map[string]*JSONSchema)}
+	for _, f := range fields {
+		property, err := c.jsonSchema(f.typ, f.validate)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+		if f.nullable {
+			property = nullableJSONSchema(property)
+		}
+		if f.desc != "" {
+			property.Description = f.desc
+		}
+
+		schema.Properties[f.jsonName] = property
+		// z.any() and z.unknown() accept missing properties as well
+		if !f.optional && !isAnyJSONSchema(property) {
+			schema.Required = append(schema.Required, f.jsonName)
+		}
+	}
+
+	if intersections := c.intersections[t]; len(intersections) > 0 {
+		schema = &JSONSchema{AllOf: []*JSONSchema{schema}}
+		for _, other := range intersections {
+			schema.AllOf = append(schema.AllOf, c.refJSONSchema(other))
+		}
+	}
+
+	return schema, nil
+}
+
+// isAnyJSONSchema checks whether a schema accepts any value.
+func isAnyJSONSchema(schema *JSONSchema) bool {
+	return len(schema.Type) == 0 && schema.Ref == "" && schema.Const == nil && schema.Enum == nil &&
+		schema.AnyOf == nil && schema.OneOf == nil && schema.AllOf == nil
+}
+
+// nullableJSONSchema adds null to the values accepted by a schema.
+func nullableJSONSchema(schema *JSONSchema) *JSONSchema {
+	if isAnyJSONSchema(schema) {
+		return schema
+	}
+	if len(schema.Type) == 0 || schema.Const != nil {
+		return &JSONSchema{AnyOf: []*JSONSchema{schema, {Type: SchemaType{"null"}}}}
+	}
+
+	schema.Type = append(schema.Type, "null")
+	if schema.Enum != nil {
+		schema.Enum = append(schema.Enum, nil)
+	}
+
+	return schema
+}
+
+// jsonSchema returns the schema of a Go type with the given validations.
+func (c *Converter) jsonSchema(t reflect.Type, validate string) (*JSONSchema, error) {
+	if t.Kind() == reflect.Ptr {
+		return c.jsonSchema(t.Elem(), c.pointeeValidate(t, validate))
+	}
+
+	if c.opaque[t] {
+		return &JSONSchema{}, nil
+	}
+
+	if _, ok := c.custom[getFullName(t)]; ok {
+		return nil, fmt.Errorf("custom type %s has no OpenAPI schema", t)
+	}
+
+	if c.isMappedError(t) {
+		if len(c.errorTypes) == 0 {
+			return &JSONSchema{Type: SchemaType{"string"}}, nil
+		}
+		return &JSONSchema{Ref: "#/components/schemas/" + c.prefix + c.errorName}, nil
+	}
+
+	if impls, ok := c.unions[t]; ok {
+		union := &JSONSchema{}
+		for _, impl := range impls {
+			union.OneOf = append(union.OneOf, c.refJSONSchema(impl))
+		}
+		return union, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		items, err := c.elemJSONSchema(t, getValidateAfterDive(validate))
+		if err != nil {
+			return nil, err
+		}
+		schema := &JSONSchema{Type: SchemaType{"array"}, Items: items}
+		if t.Kind() == reflect.Array {
+			schema.MinItems, schema.MaxItems = intPtr(t.Len()), intPtr(t.Len())
+		}
+		applyJSONSchemaValidations(schema, t, getValidateCurrent(validate))
+		return schema, nil
+
+	case reflect.Map:
+		values, err := c.elemJSONSchema(t, getValidateValues(validate))
+		if err != nil {
+			return nil, err
+		}
+		schema := &JSONSchema{Type: SchemaType{"object"}, AdditionalProperties: values}
+		applyJSONSchemaValidations(schema, t, getValidateCurrent(validate))
+		return schema, nil
+
+	case reflect.Struct:
+		switch {
+		case t.Name() == "":
+			return c.structJSONSchema(t)
+		case t.Name() == "Time":
+			if c.timeFormat == UnixNumber {
+				return &JSONSchema{Type: SchemaType{"number"}}, nil
+			}
+			return &JSONSchema{Type: SchemaType{"string"}, Format: "date-time"}, nil
+		case c.isExcluded(t):
+			return &JSONSchema{}, nil
+		}
+		return c.refJSONSchema(t), nil
+
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	}
+
+	if c.isInt64(t) {
+		if c.int64Type == "string" {
+			return &JSONSchema{Type: SchemaType{"string"}, Pattern: `^-?\d+$`}, nil
+		}
+		return &JSONSchema{Type: SchemaType{"integer"}, Format: "int64"}, nil
+	}
+
+	if c.complexAsObject && (t.Kind() == reflect.Complex64 || t.Kind() == reflect.Complex128) {
+		return &JSONSchema{
+			Type: SchemaType{"object"},
+			Properties: map[string]*JSONSchema{
+				"re": {Type: SchemaType{"number"}},
+				"im": {Type: SchemaType{"number"}},
+			},
+			Required: []string{"re", "im"},
+		}, nil
+	}
+
+	kind, ok := typeMapping[t.Kind()]
+	if !ok {
+		return nil, fmt.Errorf("%s has no OpenAPI schema", t)
+	}
+
+	schema := &JSONSchema{Type: SchemaType{kind}}
+	if isIntegerKind(t.Kind()) {
+		if !c.noIntChecks {
+			schema.Type = SchemaType{"integer"}
+		}
+		if min, max, ok := c.integerRange(t.Kind()); ok {
+			schema.Minimum, schema.Maximum = &min, &max
+		} else if isUnsignedKind(t.Kind()) {
+			schema.Minimum = floatPtr(0)
+		}
+	}
+	applyJSONSchemaValidations(schema, t, getValidateCurrent(validate))
+
+	return schema, nil
+}
+
+// elemJSONSchema returns the schema of the elements of a slice, array or map t,
+// which are nullable like their zod schemas, see isNullableElem.
+func (c *Converter) elemJSONSchema(t reflect.Type, validate string) (*JSONSchema, error) {
+	schema, err := c.jsonSchema(t.Elem(), validate)
+	if err != nil {
+		return nil, err
+	}
+	if t.Kind() != reflect.Slice && c.isNullableElem(t.Elem(), validate) {
+		schema = nullableJSONSchema(schema)
+	}
+
+	return schema, nil
+}
+
+// integerRange returns the range of 8, 16 and 32 bit integer kinds, see
+// WithIntegerBounds.
+func (c *Converter) integerRange(kind reflect.Kind) (float64, float64, bool) {
+	if !c.integerBounds {
+		return 0, 0, false
+	}
+
+	switch kind {
+	case reflect.Int8:
+		return math.MinInt8, math.MaxInt8, true
+	case reflect.Int16:
+		return math.MinInt16, math.MaxInt16, true
+	case reflect.Int32:
+		return math.MinInt32, math.MaxInt32, true
+	case reflect.Uint8:
+		return 0, math.MaxUint8, true
+	case reflect.Uint16:
+		return 0, math.MaxUint16, true
+	case reflect.Uint32:
+		return 0, math.MaxUint32, true
+	}
+
+	return 0, 0, false
+}
+
+// applyJSONSchemaValidations sets the keywords of the validations with a JSON
+// Schema counterpart, ignoring the others. Like the zod checks, bounds only
+// tighten the bounds already set.
+func applyJSONSchemaValidations(schema *JSONSchema, t reflect.Type, validate string) {
+	for _, part := range strings.Split(validate, ",") {
+		valName, valValue, _ := strings.Cut(strings.TrimSpace(part), "=")
+
+		switch t.Kind() {
+		case reflect.String:
+			switch valName {
+			case "required":
+				schema.MinLength = maxIntPtr(schema.MinLength, intPtr(1))
+			case "min", "gte":
+				schema.MinLength = maxIntPtr(schema.MinLength, atoiPtr(valValue))
+			case "max", "lte":
+				schema.MaxLength = minIntPtr(schema.MaxLength, atoiPtr(valValue))
+			case "len":
+				schema.MinLength = maxIntPtr(schema.MinLength, atoiPtr(valValue))
+				schema.MaxLength = minIntPtr(schema.MaxLength, atoiPtr(valValue))
+			case "eq":
+				schema.Const = valValue
+			case "oneof":
+				for _, val := range oneofValues(valValue) {
+					schema.Enum = append(schema.Enum, val)
+				}
+			case "email":
+				schema.Format = "email"
+			case "url", "http_url":
+				schema.Format = "uri"
+			case "uuid", "uuid3", "uuid4", "uuid5":
+				schema.Format = "uuid"
+			case "ipv4":
+				schema.Format = "ipv4"
+			case "ipv6":
+				schema.Format = "ipv6"
+			case "hostname", "hostname_rfc1123", "fqdn":
+				schema.Format = "hostname"
+			}
+
+		case reflect.Slice, reflect.Array:
+			switch valName {
+			case "min", "gte":
+				schema.MinItems = maxIntPtr(schema.MinItems, atoiPtr(valValue))
+			case "max", "lte":
+				schema.MaxItems = minIntPtr(schema.MaxItems, atoiPtr(valValue))
+			case "len":
+				schema.MinItems = maxIntPtr(schema.MinItems, atoiPtr(valValue))
+				schema.MaxItems = minIntPtr(schema.MaxItems, atoiPtr(valValue))
+			}
+
+		case reflect.Map:
+			switch valName {
+			case "min", "gte":
+				schema.MinProperties = maxIntPtr(schema.MinProperties, atoiPtr(valValue))
+			case "max", "lte":
+				schema.MaxProperties = minIntPtr(schema.MaxProperties, atoiPtr(valValue))
+			case "len":
+				schema.MinProperties = maxIntPtr(schema.MinProperties, atoiPtr(valValue))
+				schema.MaxProperties = minIntPtr(schema.MaxProperties, atoiPtr(valValue))
+			}
+
+		default:
+			if kind, ok := typeMapping[t.Kind()]; !ok || kind != "number" {
+				continue
+			}
+			n, err := strconv.ParseFloat(valValue, 64)
+			switch {
+			case valName == "oneof":
+				for _, val := range oneofValues(valValue) {
+					if n, err := strconv.ParseFloat(val, 64); err == nil {
+						schema.Enum = append(schema.Enum, n)
+					}
+				}
+			case err != nil:
+			case valName == "min" || valName == "gte":
+				schema.Minimum = maxFloatPtr(schema.Minimum, &n)
+			case valName == "max" || valName == "lte":
+				schema.Maximum = minFloatPtr(schema.Maximum, &n)
+			case valName == "gt":
+				schema.ExclusiveMinimum = maxFloatPtr(schema.ExclusiveMinimum, &n)
+			case valName == "lt":
+				schema.ExclusiveMaximum = minFloatPtr(schema.ExclusiveMaximum, &n)
+			case valName == "eq" || valName == "len":
+				schema.Const = n
+			}
+		}
+	}
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Uintptr
+}
+
+func isUnsignedKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func floatPtr(n float64) *float64 {
+	return &n
+}
+
+// maxIntPtr returns the greater of two optional lower bounds.
+func maxIntPtr(a, b *int) *int {
+	if a == nil || (b != nil && *b > *a) {
+		return b
+	}
+
+	return a
+}
+
+// minIntPtr returns the lesser of two optional upper bounds.
+func minIntPtr(a, b *int) *int {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+
+	return a
+}
+
+// maxFloatPtr returns the greater of two optional lower bounds.
+func maxFloatPtr(a, b *float64) *float64 {
+	if a == nil || (b != nil && *b > *a) {
+		return b
+	}
+
+	return a
+}
+
+// minFloatPtr returns the lesser of two optional upper bounds.
+func minFloatPtr(a, b *float64) *float64 {
+	if a == nil || (b != nil && *b < *a) {
+		return b
+	}
+
+	return a
+}
+
+// atoiPtr parses the length of a validation, or returns nil if it is invalid.
+func atoiPtr(s string) *int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil
+	}
+
+	return &n
+}
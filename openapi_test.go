@@ -0,0 +1,277 @@
+package zen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPISchemas(t *testing.T) {
+	type Base struct {
+		ID string `json:"id" validate:"uuid"`
+	}
+	type AuditFields struct {
+		CreatedAt time.Time `json:"createdAt"`
+	}
+	type Address struct {
+		City string `json:"city" validate:"required" zen_desc:"City of the address"`
+	}
+	type User struct {
+		Base
+		Name    string          `json:"name" validate:"min=2,max=50"`
+		Email   string          `json:"email,omitempty" validate:"omitempty,email"`
+		Age     uint8           `json:"age" validate:"lte=130"`
+		Role    *string         `json:"role" validate:"omitempty,oneof=admin user"`
+		Tags    []string        `json:"tags" validate:"max=5,dive,min=1"`
+		Address *Address        `json:"address"`
+		Scores  map[string]*int `json:"scores"`
+		Extra   interface{}     `json:"extra"`
+		Friends []User          `json:"friends,omitempty"`
+	}
+
+	c := NewConverterWithOpts(WithDescriptions())
+	c.Intersect(User{}, AuditFields{})
+	c.AddType(User{})
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	doc, err := json.MarshalIndent(schemas, "", "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "Address": {
+    "type": "object",
+    "properties": {
+      "city": {
+        "type": "string",
+        "description": "City of the address",
+        "minLength": 1
+      }
+    },
+    "required": [
+      "city"
+    ]
+  },
+  "AuditFields": {
+    "type": "object",
+    "properties": {
+      "createdAt": {
+        "type": "string",
+        "format": "date-time"
+      }
+    },
+    "required": [
+      "createdAt"
+    ]
+  },
+  "Base": {
+    "type": "object",
+    "properties": {
+      "id": {
+        "type": "string",
+        "format": "uuid"
+      }
+    },
+    "required": [
+      "id"
+    ]
+  },
+  "User": {
+    "allOf": [
+      {
+        "type": "object",
+        "properties": {
+          "address": {
+            "anyOf": [
+              {
+                "$ref": "#/components/schemas/Address"
+              },
+              {
+                "type": "null"
+              }
+            ]
+          },
+          "age": {
+            "type": "integer",
+            "minimum": 0,
+            "maximum": 130
+          },
+          "email": {
+            "type": "string",
+            "format": "email"
+          },
+          "extra": {},
+          "friends": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/User"
+            }
+          },
+          "id": {
+            "type": "string",
+            "format": "uuid"
+          },
+          "name": {
+            "type": "string",
+            "minLength": 2,
+            "maxLength": 50
+          },
+          "role": {
+            "type": [
+              "string",
+              "null"
+            ],
+            "enum": [
+              "admin",
+              "user",
+              null
+            ]
+          },
+          "scores": {
+            "type": [
+              "object",
+              "null"
+            ],
+            "additionalProperties": {
+              "type": [
+                "integer",
+                "null"
+              ]
+            }
+          },
+          "tags": {
+            "type": "array",
+            "items": {
+              "type": "string",
+              "minLength": 1
+            },
+            "maxItems": 5
+          }
+        },
+        "required": [
+          "id",
+          "name",
+          "age",
+          "role",
+          "tags",
+          "address",
+          "scores"
+        ]
+      },
+      {
+        "$ref": "#/components/schemas/AuditFields"
+      }
+    ]
+  }
+}`, string(doc))
+}
+
+func TestOpenAPISchemasOptions(t *testing.T) {
+	type Counter struct {
+		Small int8   `json:"small" validate:"gt=0"`
+		Big   int64  `json:"big"`
+		Code  string `json:"code" validate:"len=3"`
+	}
+
+	c := NewConverterWithOpts(WithIntegerBounds(), WithInt64AsString(), WithPrefix("API"))
+	c.AddType(Counter{})
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*JSONSchema{
+		"APICounter": {
+			Type: SchemaType{"object"},
+			Properties: map[string]*JSONSchema{
+				"small": {Type: SchemaType{"integer"}, Minimum: floatPtr(-128), Maximum: floatPtr(127), ExclusiveMinimum: floatPtr(0)},
+				"big":   {Type: SchemaType{"string"}, Pattern: `^-?\d+$`},
+				"code":  {Type: SchemaType{"string"}, MinLength: intPtr(3), MaxLength: intPtr(3)},
+			},
+			Required: []string{"small", "big", "code"},
+		},
+	}, schemas)
+}
+
+func TestOpenAPISchemasCustomType(t *testing.T) {
+	type Invoice struct {
+		Total TestMoney `json:"total"`
+	}
+
+	c := NewConverterWithOpts(WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.TestMoney": func(c *Converter, t reflect.Type, validate string, indent int) string {
+			return "z.string()"
+		},
+	}))
+	c.AddType(Invoice{})
+	_, err := c.OpenAPISchemas()
+	assert.EqualError(t, err, "Invoice: Total: custom type zen.TestMoney has no OpenAPI schema")
+}
+
+func TestOpenAPISchemasElements(t *testing.T) {
+	type Item struct {
+		Name string `json:"name" validate:"min=3,required"`
+	}
+	type Order struct {
+		Items    []*Item          `json:"items"`
+		Pair     [2]*Item         `json:"pair"`
+		Required [2]*Item         `json:"required" validate:"dive,required"`
+		Labels   map[string]*Item `json:"labels" validate:"dive,required"`
+		Counts   map[string]*int  `json:"counts"`
+		Level    int8             `json:"level" validate:"min=-1000,max=10"`
+	}
+
+	c := NewConverterWithOpts(WithIntegerBounds())
+	c.AddType(Order{})
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	ref := &JSONSchema{Ref: "#/components/schemas/Item"}
+	nullableRef := &JSONSchema{AnyOf: []*JSONSchema{ref, {Type: SchemaType{"null"}}}}
+	assert.Equal(t, map[string]*JSONSchema{
+		"Item": {
+			Type: SchemaType{"object"},
+			Properties: map[string]*JSONSchema{
+				"name": {Type: SchemaType{"string"}, MinLength: intPtr(3)},
+			},
+			Required: []string{"name"},
+		},
+		"Order": {
+			Type: SchemaType{"object"},
+			Properties: map[string]*JSONSchema{
+				"items":    {Type: SchemaType{"array", "null"}, Items: ref},
+				"pair":     {Type: SchemaType{"array"}, Items: nullableRef, MinItems: intPtr(2), MaxItems: intPtr(2)},
+				"required": {Type: SchemaType{"array"}, Items: ref, MinItems: intPtr(2), MaxItems: intPtr(2)},
+				"labels":   {Type: SchemaType{"object", "null"}, AdditionalProperties: ref},
+				"counts":   {Type: SchemaType{"object", "null"}, AdditionalProperties: &JSONSchema{Type: SchemaType{"integer", "null"}}},
+				"level":    {Type: SchemaType{"integer"}, Minimum: floatPtr(-128), Maximum: floatPtr(10)},
+			},
+			Required: []string{"items", "pair", "required", "labels", "counts", "level"},
+		},
+	}, schemas)
+	assert.Contains(t, c.Export(), "items: ItemSchema.array().nullable(),")
+	assert.Contains(t, c.Export(), "pair: ItemSchema.nullable().array().length(2),")
+}
+
+func TestOpenAPISchemasPointers(t *testing.T) {
+	type Profile struct {
+		Nick *string `json:"nick" validate:"required"`
+		Bio  *string `json:"bio" validate:"omitempty,min=2"`
+		Age  *int    `json:"age" validate:"required,gte=18"`
+	}
+
+	// required is met by non-nil pointers, as in the zod schema
+	c := NewConverter(nil)
+	c.AddType(Profile{})
+	schemas, err := c.OpenAPISchemas()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*JSONSchema{
+		"Profile": {
+			Type: SchemaType{"object"},
+			Properties: map[string]*JSONSchema{
+				"nick": {Type: SchemaType{"string"}},
+				"bio":  {Type: SchemaType{"string", "null"}, MinLength: intPtr(2)},
+				"age":  {Type: SchemaType{"integer"}, Minimum: floatPtr(18)},
+			},
+			Required: []string{"nick", "bio", "age"},
+		},
+	}, schemas)
+	assert.Contains(t, c.Export(), "nick: z.string(),")
+}
@@ -0,0 +1,124 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// paramLocations are the locations of request parameters, see AddParams.
+var paramLocations = []string{"path", "query", "header", "body"}
+
+// AddParams converts a struct describing the parameters of a request into one
+// schema per location, for typed API clients. Fields are grouped by their
+// `in:"path"`, `in:"query"` or `in:"header"` tag, while fields without one go
+// to the body. The schemas are named after the struct and the location, eg.
+// GetUserParamsPathSchema, and only locations with fields get one. Fields are
+// named as usual, eg. `json:"X-Request-Id" in:"header"`, and fields promoted
+// from embedded structs are grouped like the struct's own fields, hiding
+// deeper fields of the same name as in encoding/json, while tagged embedded
+// structs are fields. AddParams returns the locations with a schema, in the
+// order above.
+func (c *Converter) AddParams(input interface{}) []string {
+	t := reflect.TypeOf(input)
+	if t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+
+	groups := c.paramFields(t)
+
+	var locations []string
+	for _, location := range paramLocations {
+		fields := groups[location]
+		if len(fields) == 0 {
+			continue
+		}
+
+		locations = append(locations, location)
+		// The schema is backed by the struct of the parameters, so that other
+		// exports see only the fields in the location.
+		name := c.structName(t) + strings.ToUpper(location[:1]) + location[1:]
+		params := reflect.StructOf(fields)
+		c.checkCollision(name, params)
+		if _, ok := c.outputs[name]; ok {
+			continue
+		}
+
+		schema := c.ConvertType(params, "", 0)
+		if c.explicitTypes || c.alwaysLazy {
+			c.addSchema(name, fmt.Sprintf("export type %s%s = %s\nexport const %s: z.ZodType<%s%s> = %s",
+				c.prefix, name, c.getType(params, "", 0),
+				schemaName(c.prefix, name), c.prefix, name, schema))
+		} else {
+			c.addSchema(name, fmt.Sprintf("export const %s = %s\nexport type %s%s = z.infer<typeof %s>",
+				schemaName(c.prefix, name), schema,
+				c.prefix, name, schemaName(c.prefix, name)))
+		}
+	}
+
+	c.convertDeferred()
+
+	return locations
+}
+
+// ParamNames returns the names of the parameters of a request struct in a
+// location, eg. "path", as in the schema of the location, see AddParams.
+func (c *Converter) ParamNames(input interface{}, location string) []string {
+	t := reflect.TypeOf(input)
+	if t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+
+	var names []string
+	for _, field := range c.paramFields(t)[location] {
+		names = append(names, c.fieldName(field))
+	}
+
+	return names
+}
+
+// paramFields groups the JSON properties of a struct by their location, see
+// AddParams and jsonFields. Promoted fields sharing the Go name of another
+// field are renamed, as the fields of a struct type must have distinct names,
+// and those promoted through embedded pointers are optional.
+func (c *Converter) paramFields(t reflect.Type) map[string][]reflect.StructField {
+	groups := make(map[string][]reflect.StructField)
+	names := make(map[string]bool)
+	for _, f := range c.jsonFields(t) {
+		field := f.field
+		if !field.IsExported() {
+			continue
+		}
+
+		location := field.Tag.Get("in")
+		if location == "" {
+			location = "body"
+		}
+		if !containsString(paramLocations, location) {
+			panic(fmt.Sprintf("unknown parameter location of %s.%s: %s", f.parent.Name(), field.Name, location))
+		}
+
+		// The prepended zen options keep the name of the field in the
+		// payloads, and take precedence.
+		var zen []string
+		if names[field.Name] {
+			zen = append(zen, "name="+f.name)
+			field.Name = fmt.Sprintf("%s%d", field.Name, len(names))
+		}
+		if f.optional {
+			zen = append(zen, "optional")
+		}
+		if len(zen) > 0 {
+			if old, ok := field.Tag.Lookup("zen"); ok && old != "" {
+				zen = append(zen, old)
+			}
+			field.Tag = reflect.StructTag(fmt.Sprintf("zen:%s %s", strconv.Quote(strings.Join(zen, ",")), field.Tag))
+		}
+		names[field.Name] = true
+		field.Anonymous = false
+		groups[location] = append(groups[location], field)
+	}
+
+	return groups
+}
@@ -0,0 +1,126 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Pagination struct {
+	Page int `json:"page" in:"query" validate:"omitempty,gte=1"`
+	Size int `json:"size,omitempty" in:"query"`
+}
+
+func TestParams(t *testing.T) {
+	type Profile struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type UpdateUserParams struct {
+		Pagination
+		ID        string  `json:"id" in:"path"`
+		RequestID string  `json:"X-Request-Id" in:"header"`
+		Page      string  `json:"cursor" in:"query"`
+		Note      string  `json:"note" validate:"max=100"`
+		Profile   Profile `json:"profile"`
+		Size      int     `json:"size" in:"query"`
+		internal  string
+	}
+
+	c := NewConverter(nil)
+	assert.Equal(t, []string{"path", "query", "header", "body"}, c.AddParams(UpdateUserParams{internal: "unused"}))
+	assert.Equal(t, `export const UpdateUserParamsPathSchema = z.object({
+  id: z.string(),
+})
+export type UpdateUserParamsPath = z.infer<typeof UpdateUserParamsPathSchema>
+
+export const UpdateUserParamsQuerySchema = z.object({
+  page: z.number().int().gte(1),
+  cursor: z.string(),
+  size: z.number().int(),
+})
+export type UpdateUserParamsQuery = z.infer<typeof UpdateUserParamsQuerySchema>
+
+export const UpdateUserParamsHeaderSchema = z.object({
+  "X-Request-Id": z.string(),
+})
+export type UpdateUserParamsHeader = z.infer<typeof UpdateUserParamsHeaderSchema>
+
+export const ProfileSchema = z.object({
+  name: z.string().min(1),
+})
+export type Profile = z.infer<typeof ProfileSchema>
+
+export const UpdateUserParamsBodySchema = z.object({
+  note: z.string().max(100),
+  profile: ProfileSchema,
+})
+export type UpdateUserParamsBody = z.infer<typeof UpdateUserParamsBodySchema>
+
+`, c.Export())
+	assert.Equal(t, []string{"Profile"}, c.DependenciesOf("UpdateUserParamsBody"))
+	assert.Equal(t, []string{"page", "cursor", "size"}, c.ParamNames(UpdateUserParams{}, "query"))
+	assert.Empty(t, c.ParamNames(UpdateUserParams{}, "cookie"))
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Equal(t, []string{"query"}, c.AddParams(Pagination{}))
+	assert.Equal(t, `export type PaginationQuery = {
+  page: number,
+  size?: number | undefined,
+}
+export const PaginationQuerySchema: z.ZodType<PaginationQuery> = z.object({
+  page: z.number().int().gte(1),
+  size: z.number().int().optional(),
+})
+
+`, c.Export())
+
+	// shallower fields hide deeper ones, whichever embedded struct they are
+	// promoted from
+	type Deep struct {
+		Token string `json:"token" in:"header"`
+	}
+	type First struct {
+		Deep
+	}
+	type Second struct {
+		Token int `json:"token" in:"header"`
+	}
+	type Auth struct {
+		Token string `json:"auth" in:"header"`
+	}
+	type Req struct {
+		First
+		Second
+		*Auth
+		// tagged embedded structs are fields
+		Pagination `json:"pagination"`
+	}
+	c = NewConverter(nil)
+	assert.Equal(t, []string{"header", "body"}, c.AddParams(Req{}))
+	assert.Equal(t, `export const ReqHeaderSchema = z.object({
+  token: z.number().int(),
+  auth: z.string().optional(),
+})
+export type ReqHeader = z.infer<typeof ReqHeaderSchema>
+
+export const PaginationSchema = z.object({
+  page: z.number().int().gte(1),
+  size: z.number().int().optional(),
+})
+export type Pagination = z.infer<typeof PaginationSchema>
+
+export const ReqBodySchema = z.object({
+  pagination: PaginationSchema,
+})
+export type ReqBody = z.infer<typeof ReqBodySchema>
+
+`, c.Export())
+
+	type Session struct {
+		Token string `json:"token" in:"cookie"`
+	}
+	assert.PanicsWithValue(t, "unknown parameter location of Session.Token: cookie", func() {
+		c := NewConverter(nil)
+		c.AddParams(Session{})
+	})
+}
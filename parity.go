@@ -0,0 +1,421 @@
+package zen
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ExportParityTests returns the source of a Go test file, in the given package,
+// that checks the constraints converted so far against go-playground/validator.
+// Every supported validation on the JSON properties of the converted structs,
+// including promoted ones, yields boundary samples, each paired with whether
+// the schema emitted for the property accepts it, as given by zen tags, field
+// overrides and the other validations of the property. The test then asserts
+// that validator.Var agrees on every sample with the validations of the
+// property, catching cases where zen's mapping and validator's behaviour
+// diverge. Samples are left out when the schema has checks that cannot be
+// evaluated, eg. regexes or custom refinements.
+//
+// The samples are checked with validator.New(), tag aliases being expanded.
+// Validations registered with the application's validator, eg. those
+// translated with WithValidatorTranslation, require assigning the
+// zenParityValidator variable of the test, in an init function of another test
+// file of the package.
+func (c *Converter) ExportParityTests(pkg string) string {
+	var cases []paritySample
+	pointers := false
+	for _, name := range c.convertedStructs() {
+		// the schemas are converted again, so on a scratch converter
+		conv := c.scratch(name)
+		for _, f := range conv.jsonFields(c.names[name]) {
+			if conv.isNever(f.field) {
+				continue
+			}
+			schema := conv.paritySchema(f)
+			tag := getValidateCurrent(conv.fieldValidate(f.field))
+			for _, part := range strings.Split(tag, ",") {
+				part = strings.TrimSpace(part)
+				if part == "" || conv.checkIsIgnored(part) {
+					continue
+				}
+				for _, s := range paritySamples(f.field.Type, part) {
+					valid, ok := zodAccepts(schema, s.value)
+					if !ok {
+						continue
+					}
+					literal := s.literal
+					for t := f.field.Type; t.Kind() == reflect.Ptr; t = t.Elem() {
+						literal = fmt.Sprintf("zenParityPtr(%s)", literal)
+						pointers = true
+					}
+					cases = append(cases, paritySample{fmt.Sprintf("%s.%s %s", name, f.field.Name, part), literal, tag, valid})
+				}
+			}
+		}
+	}
+
+	output := strings.Builder{}
+	output.WriteString(fmt.Sprintf(`// Code generated by zen. DO NOT EDIT.
+
+package %s
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// zenParityValidator returns the validator the samples are checked with.
+var zenParityValidator = func() *validator.Validate {
+	return validator.New()
+}
+
+func TestZenParity(t *testing.T) {
+	v := zenParityValidator()
+	cases := []struct {
+		name  string
+		value interface{}
+		tag   string
+		valid bool
+	}{
+`, pkg))
+	for _, s := range cases {
+		output.WriteString(fmt.Sprintf("\t\t{%s, %s, %s, %t},\n",
+			strconv.Quote(s.name), s.value, strconv.Quote(s.tag), s.valid))
+	}
+	output.WriteString(`	}
+
+	for _, tc := range cases {
+		err := v.Var(tc.value, tc.tag)
+		if tc.valid && err != nil {
+			t.Errorf("%s: zod accepts %#v but validator rejects it: %v", tc.name, tc.value, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: zod rejects %#v but validator accepts it", tc.name, tc.value)
+		}
+	}
+}
+`)
+	if pointers {
+		output.WriteString(`
+func zenParityPtr[T any](v T) *T {
+	return &v
+}
+`)
+	}
+
+	return output.String()
+}
+
+// paritySchema returns the schema emitted for a property, see convertField,
+// without its optionality and nullability.
+func (c *Converter) paritySchema(f jsonField) string {
+	schema, ok := c.overrideField(f.parent, f.field)
+	if !ok {
+		c.parent, c.structField = f.parent, f.field
+		if typ := parseZenTag(f.field).typ; typ != "" {
+			schema = typ
+		} else if values, _, ok := parseZenValues(f.field); ok {
+			schema = c.convertRecord(elemType(f.field.Type), c.fieldValidate(f.field), 0, values)
+		} else {
+			schema = c.ConvertType(f.field.Type, c.fieldValidate(f.field), 0)
+		}
+		if zero, ok := c.omittedZeroValue(f.field); ok {
+			schema = fmt.Sprintf("%s.or(z.literal(%s))", schema, zero)
+		}
+	}
+
+	for {
+		trimmed := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(schema, ".optional()"), ".nullable()"), ".nullish()")
+		if trimmed == schema {
+			return schema
+		}
+		schema = trimmed
+	}
+}
+
+type paritySample struct {
+	name  string
+	value string
+	tag   string
+	valid bool
+}
+
+// parityValue is a sample as seen by a zod schema, ie. the kind of schema
+// accepting it, and the string of strings, the number of numbers and the
+// length of strings, slices and maps.
+type parityValue struct {
+	kind string
+	str  string
+	num  float64
+}
+
+// parityCandidate is a sample given as a Go literal, along with its value.
+type parityCandidate struct {
+	literal string
+	value   parityValue
+}
+
+// paritySamples derives boundary samples for a single validation on a field of
+// the given type. Validations without a well defined boundary are skipped.
+func paritySamples(t reflect.Type, part string) []parityCandidate {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	valName, valValue, _ := strings.Cut(part, "=")
+	text := func(s string) parityCandidate {
+		return parityCandidate{strconv.Quote(s), parityValue{"string", s, float64(len(s))}}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if valName == "eq" || valName == "ne" {
+			return []parityCandidate{text(valValue), text(valValue + "x")}
+		}
+		if valName == "required" {
+			return []parityCandidate{text(""), text("a")}
+		}
+		return lengthSamples(valName, valValue, func(n int) parityCandidate {
+			return text(strings.Repeat("a", n))
+		})
+	case reflect.Slice, reflect.Map:
+		if valName == "required" {
+			return nil
+		}
+		return lengthSamples(valName, valValue, func(n int) parityCandidate {
+			if t.Kind() == reflect.Map {
+				entries := make([]string, n)
+				for i := range entries {
+					entries[i] = fmt.Sprintf("%d: {}", i)
+				}
+				return parityCandidate{fmt.Sprintf("map[int]struct{}{%s}", strings.Join(entries, ", ")), parityValue{kind: "record", num: float64(n)}}
+			}
+			return parityCandidate{fmt.Sprintf("make([]struct{}, %d)", n), parityValue{kind: "array", num: float64(n)}}
+		})
+	}
+
+	kind, ok := typeMapping[t.Kind()]
+	if !ok || kind != "number" {
+		return nil
+	}
+
+	var samples []parityCandidate
+	add := func(v float64) {
+		if numberInRange(t.Kind(), v) {
+			samples = append(samples, parityCandidate{
+				fmt.Sprintf("%s(%s)", t.Kind(), strconv.FormatFloat(v, 'f', -1, 64)), parityValue{kind: "number", num: v}})
+		}
+	}
+
+	if valName == "required" {
+		add(0)
+		add(1)
+		return samples
+	}
+	if valName == "oneof" {
+		maxVal := math.Inf(-1)
+		for _, val := range oneofValues(valValue) {
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil
+			}
+			add(n)
+			maxVal = math.Max(maxVal, n)
+		}
+		add(maxVal + 1)
+		return samples
+	}
+
+	n, err := strconv.ParseFloat(valValue, 64)
+	if err != nil {
+		return nil
+	}
+	switch valName {
+	case "min", "gte", "lt":
+		add(n)
+		add(n - 1)
+	case "max", "lte", "gt", "eq", "len", "ne":
+		add(n)
+		add(n + 1)
+	}
+
+	return samples
+}
+
+// lengthSamples derives boundary samples for length based validations, as used
+// for strings, slices and maps.
+func lengthSamples(valName, valValue string, sample func(n int) parityCandidate) []parityCandidate {
+	n, err := strconv.Atoi(valValue)
+	if err != nil {
+		return nil
+	}
+
+	var samples []parityCandidate
+	add := func(n int) {
+		if n >= 0 {
+			samples = append(samples, sample(n))
+		}
+	}
+
+	switch valName {
+	case "min", "gte", "lt":
+		add(n)
+		add(n - 1)
+	case "max", "lte", "len", "eq", "ne", "gt":
+		add(n)
+		add(n + 1)
+	}
+
+	return samples
+}
+
+// zodAccepts evaluates a string, number, array or record schema on a sample,
+// as zod would. It returns false for ok if the schema has checks that cannot be
+// evaluated, or coerces samples of another kind.
+func zodAccepts(schema string, value parityValue) (bool, bool) {
+	kind, checks, ok := schemaChecks(schema)
+	if !ok {
+		return false, false
+	}
+	if kind != value.kind {
+		return false, !strings.HasPrefix(schema, "z.coerce.")
+	}
+
+	valid := true
+	for checks != "" {
+		if m := matchBoundCheck.FindStringSubmatch(checks); m != nil {
+			n, _ := strconv.ParseFloat(m[2], 64)
+			op := map[string]string{"min": ">=", "max": "<=", "length": "===", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}[m[1]]
+			valid = valid && compareNumbers(value.num, op, n)
+			checks = checks[len(m[0]):]
+		} else if m := matchIntCheck.FindStringSubmatch(checks); m != nil {
+			if m[1] == "int" {
+				valid = valid && value.num == math.Trunc(value.num)
+			} else {
+				valid = valid && value.num >= 0
+			}
+			checks = checks[len(m[0]):]
+		} else if m := matchCompareRefine.FindStringSubmatch(checks); m != nil {
+			if strings.HasPrefix(m[3], `"`) {
+				str, err := strconv.Unquote(m[3])
+				if err != nil || m[1] != "val" || value.kind != "string" {
+					return false, false
+				}
+				valid = valid && (str == value.str) == (m[2] == "===")
+			} else {
+				n, _ := strconv.ParseFloat(m[3], 64)
+				valid = valid && compareNumbers(value.num, m[2], n)
+			}
+			checks = checks[len(m[0]):]
+		} else if m := matchIncludesRefine.FindStringSubmatch(checks); m != nil {
+			found := false
+			for _, val := range strings.Split(m[1], ", ") {
+				n, err := strconv.ParseFloat(val, 64)
+				if err != nil {
+					return false, false
+				}
+				found = found || n == value.num
+			}
+			valid = valid && found
+			checks = checks[len(m[0]):]
+		} else if m := matchMapSizeRefine.FindStringSubmatch(checks); m != nil {
+			min, _ := strconv.ParseFloat(m[1], 64)
+			max := math.Inf(1)
+			if m[2] != "Infinity" {
+				max, _ = strconv.ParseFloat(m[2], 64)
+			}
+			valid = valid && value.num >= min && value.num <= max
+			checks = checks[len(m[0]):]
+		} else {
+			return false, false
+		}
+	}
+
+	return valid, true
+}
+
+// schemaChecks returns the kind of a string, number, array or record schema,
+// and the checks applied to it, ie. the calls following z.string(),
+// z.number(), .array() or z.record(...).
+func schemaChecks(schema string) (string, string, bool) {
+	if strings.HasPrefix(schema, "z.record(") {
+		depth := 0
+		for i, r := range schema {
+			switch r {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return "record", schema[i+1:], true
+				}
+			}
+		}
+	}
+	if i := strings.LastIndex(schema, ".array()"); i >= 0 {
+		return "array", schema[i+len(".array()"):], true
+	}
+	if m := matchPrimitiveSchema.FindStringSubmatch(schema); m != nil {
+		return m[1], schema[len(m[0]):], true
+	}
+
+	return "", "", false
+}
+
+// compareNumbers compares a with b using a JS comparison operator.
+func compareNumbers(a float64, op string, b float64) bool {
+	switch op {
+	case "===":
+		return a == b
+	case "!==":
+		return a != b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "<":
+		return a < b
+	default:
+		return a <= b
+	}
+}
+
+var (
+	matchPrimitiveSchema = regexp.MustCompile(`^z\.(?:coerce\.)?(string|number)\(\)`)
+	matchBoundCheck      = regexp.MustCompile(`^\.(min|max|length|gte?|lte?)\((-?[0-9.]+)\)`)
+	matchIntCheck        = regexp.MustCompile(`^\.(int|nonnegative)\(\)`)
+	matchCompareRefine   = regexp.MustCompile(`^\.refine\(\(val\) => (val|val\.length|Object\.keys\(val\)\.length) (===|!==|>=|<=|>|<) (-?[0-9.]+|"(?:[^"\\]|\\.)*")(?:, '[^']*')?\)`)
+	matchIncludesRefine  = regexp.MustCompile(`^\.refine\(\(val\) => \[([^\]]*)\]\.includes\(val\)\)`)
+	matchMapSizeRefine   = regexp.MustCompile(`^\.refine\(mapSize\(([0-9]+), ([0-9]+|Infinity)\), '[^']*'\)`)
+)
+
+// numberInRange checks whether v can be written as a literal of the given kind.
+func numberInRange(kind reflect.Kind, v float64) bool {
+	if kind != reflect.Float32 && kind != reflect.Float64 && v != math.Trunc(v) {
+		return false
+	}
+
+	switch kind {
+	case reflect.Int8:
+		return v >= math.MinInt8 && v <= math.MaxInt8
+	case reflect.Int16:
+		return v >= math.MinInt16 && v <= math.MaxInt16
+	case reflect.Int32:
+		return v >= math.MinInt32 && v <= math.MaxInt32
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return v >= 0
+	case reflect.Uint8:
+		return v >= 0 && v <= math.MaxUint8
+	case reflect.Uint16:
+		return v >= 0 && v <= math.MaxUint16
+	case reflect.Uint32:
+		return v >= 0 && v <= math.MaxUint32
+	}
+
+	return true
+}
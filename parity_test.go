@@ -0,0 +1,197 @@
+package zen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportParityTests(t *testing.T) {
+	type Address struct {
+		Zip string `validate:"len=5"`
+	}
+	type Base struct {
+		ID int `validate:"gte=1"`
+	}
+	type User struct {
+		Base
+		Name    string   `validate:"required,min=2,max=4"`
+		Age     int8     `validate:"gt=0,lte=127"`
+		Score   *float64 `validate:"omitempty,lt=1.5"`
+		Tags    []string `validate:"min=1,dive,required"`
+		Role    int      `validate:"oneof=1 2"`
+		Ignored string   `json:"-" validate:"min=1"`
+		Address Address
+		// the zen type is evaluated, rather than the validations
+		Code string `validate:"max=5" zen:"type=z.string().length(3)"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(User{})
+	schemas := c.Export()
+	assert.Equal(t, `// Code generated by zen. DO NOT EDIT.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// zenParityValidator returns the validator the samples are checked with.
+var zenParityValidator = func() *validator.Validate {
+	return validator.New()
+}
+
+func TestZenParity(t *testing.T) {
+	v := zenParityValidator()
+	cases := []struct {
+		name  string
+		value interface{}
+		tag   string
+		valid bool
+	}{
+		{"Base.ID gte=1", int(1), "gte=1", true},
+		{"Base.ID gte=1", int(0), "gte=1", false},
+		{"Address.Zip len=5", "aaaaa", "len=5", true},
+		{"Address.Zip len=5", "aaaaaa", "len=5", false},
+		{"User.ID gte=1", int(1), "gte=1", true},
+		{"User.ID gte=1", int(0), "gte=1", false},
+		{"User.Name required", "", "required,min=2,max=4", false},
+		{"User.Name required", "a", "required,min=2,max=4", false},
+		{"User.Name min=2", "aa", "required,min=2,max=4", true},
+		{"User.Name min=2", "a", "required,min=2,max=4", false},
+		{"User.Name max=4", "aaaa", "required,min=2,max=4", true},
+		{"User.Name max=4", "aaaaa", "required,min=2,max=4", false},
+		{"User.Age gt=0", int8(0), "gt=0,lte=127", false},
+		{"User.Age gt=0", int8(1), "gt=0,lte=127", true},
+		{"User.Age lte=127", int8(127), "gt=0,lte=127", true},
+		{"User.Score lt=1.5", zenParityPtr(float64(1.5)), "omitempty,lt=1.5", false},
+		{"User.Score lt=1.5", zenParityPtr(float64(0.5)), "omitempty,lt=1.5", true},
+		{"User.Tags min=1", make([]struct{}, 1), "min=1", true},
+		{"User.Tags min=1", make([]struct{}, 0), "min=1", false},
+		{"User.Role oneof=1 2", int(1), "oneof=1 2", true},
+		{"User.Role oneof=1 2", int(2), "oneof=1 2", true},
+		{"User.Role oneof=1 2", int(3), "oneof=1 2", false},
+		{"User.Code max=5", "aaaaa", "max=5", false},
+		{"User.Code max=5", "aaaaaa", "max=5", false},
+	}
+
+	for _, tc := range cases {
+		err := v.Var(tc.value, tc.tag)
+		if tc.valid && err != nil {
+			t.Errorf("%s: zod accepts %#v but validator rejects it: %v", tc.name, tc.value, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("%s: zod rejects %#v but validator accepts it", tc.name, tc.value)
+		}
+	}
+}
+
+func zenParityPtr[T any](v T) *T {
+	return &v
+}
+`, c.ExportParityTests("api"))
+	assert.Equal(t, schemas, c.Export())
+}
+
+func TestExportParityTestsFromSchemas(t *testing.T) {
+	type Query struct {
+		Name   string            `validate:"required"`
+		Limit  uint8             `validate:"lt=10"`
+		Labels map[string]string `validate:"gt=1"`
+		Email  string            `validate:"email,max=3"`
+	}
+
+	// the expected validity follows the generated schemas, so that the
+	// divergence of required with WithRequiredPresenceOnly is caught, while
+	// the email regex cannot be evaluated
+	c := NewConverterWithOpts(WithRequiredPresenceOnly(), WithSharedRefinements())
+	c.AddType(Query{})
+	output := c.ExportParityTests("api")
+	assert.Contains(t, output, `		{"Query.Name required", "", "required", true},
+		{"Query.Name required", "a", "required", true},
+		{"Query.Limit lt=10", uint8(10), "lt=10", false},
+		{"Query.Limit lt=10", uint8(9), "lt=10", true},
+		{"Query.Labels gt=1", map[int]struct{}{0: {}}, "gt=1", false},
+		{"Query.Labels gt=1", map[int]struct{}{0: {}, 1: {}}, "gt=1", true},
+	}`)
+}
+
+// TestExportParityTestsRun runs the generated tests against validator, which is
+// fetched into the module cache unless already there.
+func TestExportParityTestsRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+
+	goTest := func(c *Converter, files map[string]string) (string, error) {
+		dir := t.TempDir()
+		files["go.mod"] = "module parity\n\ngo 1.21\n"
+		files["zen_parity_test.go"] = c.ExportParityTests("parity")
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+		}
+
+		run := func(args ...string) (string, error) {
+			cmd := exec.Command("go", args...)
+			cmd.Dir = dir
+			cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+			out, err := cmd.CombinedOutput()
+			return string(out), err
+		}
+		if out, err := run("get", "github.com/go-playground/validator/v10@v10.30.5"); err != nil {
+			t.Skipf("validator unavailable: %s", out)
+		}
+		return run("test", ".")
+	}
+
+	type Base struct {
+		ID int `validate:"gte=1"`
+	}
+	type Account struct {
+		Base
+		Name  string   `validate:"required,min=2,max=4"`
+		Nick  *string  `validate:"required,short"`
+		Score *float64 `validate:"omitempty,lt=1.5"`
+		Tags  []string `validate:"min=1,dive,required"`
+		Count int      `validate:"small,gte=1"`
+	}
+
+	c := NewConverterWithOpts(
+		WithTagAliases(map[string]string{"short": "max=3"}),
+		WithValidatorTranslation("small", func(v Validation) string { return ".max(10)" }),
+	)
+	c.AddType(Account{})
+	out, err := goTest(&c, map[string]string{"small_test.go": `package parity
+
+import "github.com/go-playground/validator/v10"
+
+func init() {
+	zenParityValidator = func() *validator.Validate {
+		v := validator.New()
+		_ = v.RegisterValidation("small", func(fl validator.FieldLevel) bool {
+			return fl.Field().Int() <= 10
+		})
+		return v
+	}
+}
+`})
+	assert.NoError(t, err, out)
+
+	// validator rejects empty strings with required, which the schema accepts
+	// with WithRequiredPresenceOnly
+	type Query struct {
+		Name string `validate:"required"`
+	}
+	c = NewConverterWithOpts(WithRequiredPresenceOnly())
+	c.AddType(Query{})
+	out, err = goTest(&c, map[string]string{})
+	assert.Error(t, err)
+	assert.Contains(t, out, `Query.Name required: zod accepts "" but validator rejects it`)
+}
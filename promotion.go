@@ -0,0 +1,200 @@
+package zen
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonField is a JSON property of a struct, see jsonFields.
+type jsonField struct {
+	name  string
+	field reflect.StructField
+	// parent is the struct declaring the field.
+	parent reflect.Type
+	// index is the index sequence of the field, as for reflect's
+	// FieldByIndex, starting with the embedded struct it is promoted from.
+	index  []int
+	tagged bool
+	// optional is set on fields promoted through embedded pointers, which
+	// encoding/json leaves out when the pointers are nil.
+	optional bool
+}
+
+// jsonFields returns the JSON properties of a struct in field order, including
+// those promoted from embedded structs, resolving conflicting names like
+// encoding/json: shallower fields hide deeper ones, and of the fields at the
+// same depth a single tagged field hides the others, while ambiguous fields
+// are all dropped. The fields of embedded custom types are unknown and left
+// out.
+func (c *Converter) jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	path := make(map[reflect.Type]bool)
+
+	var collect func(t reflect.Type, index []int, optional bool)
+	collect = func(t reflect.Type, index []int, optional bool) {
+		path[t] = true
+		defer delete(path, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fieldIndex := append(append([]int(nil), index...), i)
+			if embedded, pointer, ok := c.embeddedStruct(field); ok {
+				// The fields of structs embedding themselves are hidden.
+				if _, custom := c.custom[getFullName(embedded)]; !custom && !path[embedded] && !c.isExcluded(embedded) {
+					collect(embedded, fieldIndex, optional || pointer)
+				}
+				continue
+			}
+			if c.isOmitted(field) || field.Anonymous && c.isExcluded(elemType(field.Type)) {
+				continue
+			}
+
+			fields = append(fields, jsonField{c.fieldName(field), field, t, fieldIndex, c.isTagged(field), optional})
+		}
+	}
+	collect(t, nil, false)
+
+	byName := make(map[string][]jsonField)
+	for _, f := range fields {
+		byName[f.name] = append(byName[f.name], f)
+	}
+
+	var visible []jsonField
+	for _, f := range fields {
+		if dominant, ok := dominantField(byName[f.name]); ok && sameIndex(dominant.index, f.index) {
+			visible = append(visible, f)
+		}
+	}
+
+	return visible
+}
+
+// dominantField returns the field hiding the others with the same name, if
+// any, see jsonFields.
+func dominantField(fields []jsonField) (jsonField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+
+	var shallowest, tagged []jsonField
+	for _, f := range fields {
+		if len(f.index) == depth {
+			shallowest = append(shallowest, f)
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+	}
+
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return jsonField{}, false
+	}
+}
+
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isTagged checks whether a field is named by its tag, or `zen:"name=..."`.
+// Tagged embedded structs are not promoted, but are fields named after the
+// tag.
+func (c *Converter) isTagged(field reflect.StructField) bool {
+	if parseZenTag(field).name != "" {
+		return true
+	}
+
+	name, _, _ := strings.Cut(field.Tag.Get(c.nameTagKey()), ",")
+	return name != ""
+}
+
+// hiddenFields returns the names of the JSON properties of the embedded
+// structs of a struct, by field index, that are hidden by other fields of the
+// struct, see jsonFields.
+func (c *Converter) hiddenFields(t reflect.Type) map[int][]string {
+	visible := make(map[int]map[string]bool)
+	for _, f := range c.jsonFields(t) {
+		if visible[f.index[0]] == nil {
+			visible[f.index[0]] = make(map[string]bool)
+		}
+		visible[f.index[0]][f.name] = true
+	}
+
+	hidden := make(map[int][]string)
+	for i := 0; i < t.NumField(); i++ {
+		embedded, _, ok := c.embeddedStruct(t.Field(i))
+		if !ok {
+			continue
+		}
+		if _, custom := c.custom[getFullName(embedded)]; custom {
+			continue
+		}
+
+		for _, f := range c.jsonFields(embedded) {
+			if !visible[i][f.name] {
+				hidden[i] = append(hidden[i], f.name)
+			}
+		}
+	}
+
+	return hidden
+}
+
+// withoutFields returns the fields, or TS types of fields, without those with
+// the given names.
+func withoutFields(fields []string, names []string) []string {
+	if len(names) == 0 {
+		return fields
+	}
+
+	hidden := make(map[string]bool, len(names))
+	for _, name := range names {
+		hidden[propertyKey(name)] = true
+	}
+
+	var kept []string
+	for _, field := range fields {
+		if name, _ := splitEntry(field); !hidden[strings.TrimSuffix(name, "?")] {
+			kept = append(kept, field)
+		}
+	}
+
+	return kept
+}
+
+// omitMask returns the mask of .omit() for the given names.
+func omitMask(names []string) string {
+	mask := make([]string, 0, len(names))
+	for _, name := range names {
+		mask = append(mask, propertyKey(name)+": true")
+	}
+
+	return "{ " + strings.Join(mask, ", ") + " }"
+}
+
+// omitKeys returns the union of the given names, for TS's Omit.
+func omitKeys(names []string) string {
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, strconv.Quote(name))
+	}
+
+	return strings.Join(keys, " | ")
+}
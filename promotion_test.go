@@ -0,0 +1,98 @@
+package zen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddedPromotion(t *testing.T) {
+	type Named struct {
+		Name string `json:"name"`
+		Kind string
+	}
+	type Titled struct {
+		Title string `json:"title"`
+		Kind  string
+	}
+	type Deep struct {
+		Named
+		Extra string `json:"extra"`
+	}
+	type User struct {
+		Named
+		Titled
+		Deep `json:"deep"`
+		Name int `json:"name"`
+	}
+
+	// like in encoding/json, own fields hide promoted ones, untagged fields
+	// promoted at the same depth are dropped, and tagged embedded structs are fields
+	c := NewConverterWithOpts()
+	assert.Equal(t, `export const NamedSchema = z.object({
+  name: z.string(),
+  Kind: z.string(),
+})
+export type Named = z.infer<typeof NamedSchema>
+
+export const TitledSchema = z.object({
+  title: z.string(),
+  Kind: z.string(),
+})
+export type Titled = z.infer<typeof TitledSchema>
+
+export const DeepSchema = z.object({
+  ...NamedSchema.shape,
+  extra: z.string(),
+})
+export type Deep = z.infer<typeof DeepSchema>
+
+export const UserSchema = z.object({
+  ...NamedSchema.omit({ name: true, Kind: true }).shape,
+  ...TitledSchema.omit({ Kind: true }).shape,
+  deep: DeepSchema,
+  name: z.number().int(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	c.AddType(User{})
+	assert.Equal(t, `export type User = {
+  deep: Deep,
+  name: number,
+} & Omit<Named, "name" | "Kind"> & Omit<Titled, "Kind">
+export const UserSchemaShape = {
+  ...z.object(NamedSchemaShape).omit({ name: true, Kind: true }).shape,
+  ...z.object(TitledSchemaShape).omit({ Kind: true }).shape,
+  deep: DeepSchema,
+  name: z.number().int(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+
+`, c.ExportTypes("User")[strings.Index(c.ExportTypes("User"), "export type User"):])
+
+	// a tagged field hides the untagged ones at the same depth
+	type Tagged struct {
+		Code string `json:"Code"`
+	}
+	type Untagged struct {
+		Code  string
+		Count int
+	}
+	type Item struct {
+		Tagged
+		Untagged
+	}
+	c = NewConverterWithOpts()
+	c.AddType(Item{})
+	assert.Equal(t, `export const ItemSchema = z.object({
+  ...TaggedSchema.shape,
+  ...UntaggedSchema.omit({ Code: true }).shape,
+})
+export type Item = z.infer<typeof ItemSchema>
+
+`, c.ExportTypes("Item")[strings.Index(c.ExportTypes("Item"), "export const ItemSchema"):])
+}
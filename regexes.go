@@ -67,7 +67,22 @@ const (
 	dnsRegexStringRFC1035Label       = "^[a-z]([-a-z0-9]*[a-z0-9]){0,62}$"
 	cveRegexString                   = `^CVE-(1999|2\d{3})-(0[^0]\d{2}|0\d[^0]\d{1}|0\d{2}[^0]|[1-9]{1}\d{3,})$` // CVE Format Id https://cve.mitre.org/cve/identifiers/syntaxchange.html
 	mongodbRegexString               = "^[a-f\\d]{24}$"
+	mongodbConnStringRegexString     = "^mongodb(\\+srv)?:\\/\\/(([a-zA-Z\\d]+):([a-zA-Z\\d$:\\/?#\\[\\]@]+)@)?(([a-z\\d.-]+)(:[\\d]+)?)((,(([a-z\\d.-]+)(:(\\d+))?))*)?(\\/[a-zA-Z-_]{1,64})?(\\?(([a-zA-Z]+)=([a-zA-Z\\d]+))(&(([a-zA-Z\\d]+)=([a-zA-Z\\d]+))?)*)?$"
+	spicedbIDRegexString             = `^(([a-zA-Z0-9\/_|\-=+]{1,})|\*)$`
+	spicedbPermissionRegexString     = "^([a-z][a-z0-9_]{1,62}[a-z0-9])?$"
+	spicedbTypeRegexString           = "^([a-z][a-z0-9_]{1,61}[a-z0-9]\\/)?[a-z][a-z0-9_]{1,62}[a-z0-9]$"
 	cronRegexString                  = `(@(annually|yearly|monthly|weekly|daily|hourly|reboot))|(@every (\d+(ns|us|µs|ms|s|m|h))+)|((((\d+,)+\d+|(\d+(\/|-)\d+)|\d+|\*) ?){5,7})`
+	// go-validator parses mac with net.ParseMAC: 6, 8 or 20 octets separated by either colons or hyphens, or
+	// groups of four hexadecimal digits separated by dots.
+	macRegexString = `^(?:[0-9a-fA-F]{2}([:-])[0-9a-fA-F]{2}(?:\1[0-9a-fA-F]{2}){4}(?:(?:\1[0-9a-fA-F]{2}){2}|(?:\1[0-9a-fA-F]{2}){14})?|[0-9a-fA-F]{4}(?:\.[0-9a-fA-F]{4}){2}(?:\.[0-9a-fA-F]{4}|(?:\.[0-9a-fA-F]{4}){7})?)$`
+
+	// regexes of other go-validator releases, selected with WithValidatorSemantics
+	hexadecimalUnprefixedRegexString = "^[0-9a-fA-F]+$"                                                                // before v10.4.1
+	hexColorOpaqueRegexString        = "^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$"                                          // before v10.10.0
+	uRLEncodedUnanchoredRegexString  = `(%[A-Fa-f0-9]{2})`                                                             // before v10.6.1
+	e164ShortRegexString             = "^\\+?[1-9]\\d{1,14}$"                                                          // v10.29.0 to v10.30.0
+	e164PlusOptionalRegexString      = "^\\+?[1-9]\\d{7,14}$"                                                          // since v10.30.1
+	uUIDMixedCaseRegexString         = "^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$" // since v10.30.3
 )
 
 var (
@@ -133,5 +148,12 @@ var (
 	dnsRegexRFC1035Label       = regexp.MustCompile(dnsRegexStringRFC1035Label)
 	cveRegex                   = regexp.MustCompile(cveRegexString)
 	mongodbRegex               = regexp.MustCompile(mongodbRegexString)
+	mongodbConnStringRegex     = regexp.MustCompile(mongodbConnStringRegexString)
 	cronRegex                  = regexp.MustCompile(cronRegexString)
+	hexadecimalUnprefixedRegex = regexp.MustCompile(hexadecimalUnprefixedRegexString)
+	hexColorOpaqueRegex        = regexp.MustCompile(hexColorOpaqueRegexString)
+	uRLEncodedUnanchoredRegex  = regexp.MustCompile(uRLEncodedUnanchoredRegexString)
+	e164ShortRegex             = regexp.MustCompile(e164ShortRegexString)
+	e164PlusOptionalRegex      = regexp.MustCompile(e164PlusOptionalRegexString)
+	uUIDMixedCaseRegex         = regexp.MustCompile(uUIDMixedCaseRegexString)
 )
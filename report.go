@@ -0,0 +1,141 @@
+package zen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Report describes a generation, for tools tracking the quality of the schemas
+// across services, see WithReport.
+type Report struct {
+	// Types are the names of the exported types, in export order.
+	Types []string `json:"types"`
+	// MappedConstraints are the validations of the fields translated to the
+	// schemas.
+	MappedConstraints []ReportConstraint `json:"mappedConstraints"`
+	// DroppedConstraints are the validations of the fields left out of the
+	// schemas, see WithIgnoreTags.
+	DroppedConstraints []ReportConstraint `json:"droppedConstraints"`
+	// AnyFields are the fields falling back to z.any(), see WithMaxAnyFields.
+	AnyFields []string `json:"anyFields"`
+	// Warnings are the conversions losing type safety, eg. fields of opaque
+	// types mapped to z.unknown().
+	Warnings []string `json:"warnings"`
+}
+
+// ReportConstraint is a validation of a field, eg. "min=3" on "pkg.User.Name".
+type ReportConstraint struct {
+	Field string `json:"field"`
+	Tag   string `json:"tag"`
+}
+
+// report collects the Report of a converter, see WithReport.
+type report struct {
+	seen      map[string]bool
+	mapped    []ReportConstraint
+	dropped   []ReportConstraint
+	anyFields []string
+	warnings  []string
+}
+
+// WithReport records what the conversion emits and loses, to be returned by
+// ExportReport as JSON.
+func WithReport() Opt {
+	return func(c *Converter) {
+		c.report = &report{seen: make(map[string]bool)}
+	}
+}
+
+// ExportReport returns the Report of the types converted so far as indented
+// JSON. It fails unless WithReport is set.
+func (c *Converter) ExportReport() (string, error) {
+	if c.report == nil {
+		return "", errors.New("report is not recorded, see WithReport")
+	}
+
+	var sorted []string
+	for name := range c.outputs {
+		sorted = append(sorted, name)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return c.outputs[sorted[i]].order < c.outputs[sorted[j]].order
+	})
+
+	r := Report{
+		Types:              make([]string, 0, len(sorted)),
+		MappedConstraints:  append([]ReportConstraint{}, c.report.mapped...),
+		DroppedConstraints: append([]ReportConstraint{}, c.report.dropped...),
+		AnyFields:          append([]string{}, c.report.anyFields...),
+		Warnings:           append([]string{}, c.report.warnings...),
+	}
+	for _, name := range sorted {
+		r.Types = append(r.Types, c.prefix+name)
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding report: %w", err)
+	}
+
+	return string(data) + "\n", nil
+}
+
+// reportConstraints records the validations of the field being converted as
+// mapped or dropped.
+func (c *Converter) reportConstraints(validate string) {
+	if c.report == nil {
+		return
+	}
+
+	for _, part := range strings.Split(validate, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || part == "dive" || part == "keys" || part == "endkeys" || isOmitMarker(part) {
+			continue
+		}
+
+		key := "constraint " + c.field + " " + part
+		if c.report.seen[key] {
+			continue
+		}
+		c.report.seen[key] = true
+
+		constraint := ReportConstraint{c.field, part}
+		if c.checkIsIgnored(part) {
+			c.report.dropped = append(c.report.dropped, constraint)
+		} else {
+			c.report.mapped = append(c.report.mapped, constraint)
+		}
+	}
+}
+
+// reportAnyField records the field being converted as falling back to
+// z.any().
+func (c *Converter) reportAnyField() {
+	if c.report == nil || c.report.seen["any "+c.field] {
+		return
+	}
+
+	c.report.seen["any "+c.field] = true
+	c.report.anyFields = append(c.report.anyFields, c.field)
+}
+
+// warn records a warning about the field being converted.
+func (c *Converter) warn(format string, args ...interface{}) {
+	if c.report == nil {
+		return
+	}
+
+	warning := fmt.Sprintf(format, args...)
+	if c.field != "" {
+		warning = c.field + ": " + warning
+	}
+	if c.report.seen["warning "+warning] {
+		return
+	}
+
+	c.report.seen["warning "+warning] = true
+	c.report.warnings = append(c.report.warnings, warning)
+}
@@ -0,0 +1,74 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportReport(t *testing.T) {
+	type Config struct {
+		Region string `json:"region"`
+	}
+	type User struct {
+		Name   string      `json:"name" validate:"required,min=3,uri"`
+		Tags   []string    `json:"tags" validate:"omitempty,dive,max=10"`
+		Meta   interface{} `json:"meta"`
+		Config Config      `json:"config"`
+		secret string
+	}
+
+	c := NewConverterWithOpts(WithReport(), WithIgnoreTags("uri"), WithOpaqueTypes(Config{}))
+	c.AddType(User{})
+	report, err := c.ExportReport()
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "types": [
+    "User"
+  ],
+  "mappedConstraints": [
+    {
+      "field": "github.com/hypersequent/zen.User.Name",
+      "tag": "required"
+    },
+    {
+      "field": "github.com/hypersequent/zen.User.Name",
+      "tag": "min=3"
+    },
+    {
+      "field": "github.com/hypersequent/zen.User.Tags",
+      "tag": "max=10"
+    }
+  ],
+  "droppedConstraints": [
+    {
+      "field": "github.com/hypersequent/zen.User.Name",
+      "tag": "uri"
+    }
+  ],
+  "anyFields": [
+    "github.com/hypersequent/zen.User.Meta"
+  ],
+  "warnings": [
+    "github.com/hypersequent/zen.User.Config: opaque type zen.Config is converted to z.unknown()",
+    "github.com/hypersequent/zen.User.secret: unexported field is converted, while encoding/json ignores it"
+  ]
+}
+`, report)
+
+	c = NewConverterWithOpts(WithReport())
+	report, err = c.ExportReport()
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "types": [],
+  "mappedConstraints": [],
+  "droppedConstraints": [],
+  "anyFields": [],
+  "warnings": []
+}
+`, report)
+
+	c = NewConverterWithOpts()
+	_, err = c.ExportReport()
+	assert.EqualError(t, err, "report is not recorded, see WithReport")
+}
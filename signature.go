@@ -0,0 +1,97 @@
+package zen
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// signatureAlias is the type under which the names of the pairs registered with
+// Signature are registered, so that structs of the same name are reported as a
+// NameCollisionError, whichever comes first.
+type signatureAlias string
+
+// procedure is a pair registered with Signature, see ExportProcedures.
+type procedure struct {
+	name     string
+	request  string
+	response string
+}
+
+// Signature registers the parameter and result structs of an RPC-style
+// function and emits them as a named pair, eg. GetUserRequestSchema and
+// GetUserResponseSchema for `zen.Signature[GetUserParams, User](c, "GetUser")`,
+// so that endpoints can be wired without naming each of their structs. The
+// structs, or the structs pointed to, are converted as if they were passed to
+// AddType, and the pair aliases their schemas and types unless the structs are
+// already named after it. The pair is listed by ExportProcedures. Signature
+// panics if In or Out is not a struct, and with a *NameCollisionError if a name
+// of the pair is taken by another type. Structs converted later under a name of
+// the pair are reported as well.
+func Signature[In, Out any](c *Converter, name string) {
+	for _, p := range c.procedures {
+		if p.name == name {
+			panic(fmt.Sprintf("duplicate signature: %s", name))
+		}
+	}
+
+	c.procedures = append(c.procedures, procedure{
+		name:     name,
+		request:  c.addSignatureType(name+"Request", reflect.TypeOf((*In)(nil)).Elem()),
+		response: c.addSignatureType(name+"Response", reflect.TypeOf((*Out)(nil)).Elem()),
+	})
+}
+
+// addSignatureType converts a struct of a signature and aliases it with the
+// given name, see Signature. It returns the name.
+func (c *Converter) addSignatureType(alias string, t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("signature type %s must be a struct", t))
+	}
+
+	c.AddType(reflect.New(t).Elem().Interface())
+	name := c.structName(t)
+	if name == alias {
+		return alias
+	}
+	c.checkCollision(alias, reflect.TypeOf(signatureAlias("")))
+	c.addSchema(alias, fmt.Sprintf("export const %s = %s\nexport type %s%s = %s%s",
+		schemaName(c.prefix, alias), schemaName(c.prefix, name), c.prefix, alias, c.prefix, name))
+
+	return alias
+}
+
+// ExportProcedures returns a TS module with a procedures object mapping the
+// names of the pairs registered with Signature to their input and output
+// schemas, in registration order, along with a Procedures type mapping them to
+// their types, eg. for a thin RPC client in the style of tRPC. schemasModule
+// is the import path of the exported schemas relative to the module, eg.
+// "./schemas".
+func (c *Converter) ExportProcedures(schemasModule string) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("import * as schemas from %s\n\n", strconv.Quote(schemasModule)))
+
+	output.WriteString("export const procedures = {\n")
+	for _, p := range c.procedures {
+		output.WriteString(fmt.Sprintf("%s%s: {\n", indentation(1), propertyKey(p.name)))
+		output.WriteString(fmt.Sprintf("%sinput: schemas.%s,\n", indentation(2), schemaName(c.prefix, p.request)))
+		output.WriteString(fmt.Sprintf("%soutput: schemas.%s,\n", indentation(2), schemaName(c.prefix, p.response)))
+		output.WriteString(fmt.Sprintf("%s},\n", indentation(1)))
+	}
+	output.WriteString("} as const\n\n")
+
+	output.WriteString("export type Procedures = {\n")
+	for _, p := range c.procedures {
+		output.WriteString(fmt.Sprintf("%s%s: {\n", indentation(1), propertyKey(p.name)))
+		output.WriteString(fmt.Sprintf("%sinput: schemas.%s%s,\n", indentation(2), c.prefix, p.request))
+		output.WriteString(fmt.Sprintf("%soutput: schemas.%s%s,\n", indentation(2), c.prefix, p.response))
+		output.WriteString(fmt.Sprintf("%s},\n", indentation(1)))
+	}
+	output.WriteString("}\n")
+
+	return output.String()
+}
@@ -0,0 +1,132 @@
+package zen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignature(t *testing.T) {
+	type GetUserParams struct {
+		ID int `json:"id" validate:"gt=0"`
+	}
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	c := NewConverterWithOpts()
+	Signature[GetUserParams, *User](&c, "GetUser")
+	assert.Equal(t, `export const GetUserParamsSchema = z.object({
+  id: z.number().int().gt(0),
+})
+export type GetUserParams = z.infer<typeof GetUserParamsSchema>
+
+export const GetUserRequestSchema = GetUserParamsSchema
+export type GetUserRequest = GetUserParams
+
+export const UserSchema = z.object({
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+export const GetUserResponseSchema = UserSchema
+export type GetUserResponse = User
+
+`, c.Export())
+	assert.Equal(t, `export const UserSchema = z.object({
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+export const GetUserResponseSchema = UserSchema
+export type GetUserResponse = User
+
+`, c.ExportTypes("GetUserResponse"))
+
+	// structs already named after the pair are not aliased
+	type DeleteUserRequest struct {
+		ID int `json:"id"`
+	}
+	type DeleteUserResponse struct{}
+	c = NewConverterWithOpts(WithPrefix("API"))
+	Signature[DeleteUserRequest, DeleteUserResponse](&c, "DeleteUser")
+	assert.Equal(t, `export const APIDeleteUserRequestSchema = z.object({
+  id: z.number().int(),
+})
+export type APIDeleteUserRequest = z.infer<typeof APIDeleteUserRequestSchema>
+
+export const APIDeleteUserResponseSchema = z.object({
+})
+export type APIDeleteUserResponse = z.infer<typeof APIDeleteUserResponseSchema>
+
+`, c.Export())
+
+	assert.PanicsWithValue(t, "signature type string must be a struct", func() {
+		c := NewConverterWithOpts()
+		Signature[string, User](&c, "GetName")
+	})
+	type UserRequest struct{}
+	assert.PanicsWithError(t, "type name collision for UserRequest: "+
+		"github.com/hypersequent/zen.UserRequest and signature alias UserRequest", func() {
+		c := NewConverterWithOpts()
+		c.AddType(UserRequest{})
+		Signature[DeleteUserRequest, User](&c, "User")
+	})
+
+	// structs converted after the pair cannot take its names either
+	type GetUserRequest struct {
+		ID int `json:"id"`
+	}
+	c = NewConverterWithOpts()
+	Signature[GetUserParams, User](&c, "GetUser")
+	var collision *NameCollisionError
+	require.ErrorAs(t, c.TryAddType(GetUserRequest{}), &collision)
+	assert.Equal(t, "GetUserRequest", collision.Name)
+	assert.Contains(t, c.Export(), "export type GetUserRequest = GetUserParams\n")
+}
+
+func TestExportProcedures(t *testing.T) {
+	type GetUserParams struct {
+		ID int `json:"id"`
+	}
+	type User struct {
+		Name string `json:"name"`
+	}
+	type DeleteUserRequest struct {
+		ID int `json:"id"`
+	}
+	type DeleteUserResponse struct{}
+
+	c := NewConverterWithOpts(WithPrefix("API"))
+	Signature[GetUserParams, User](&c, "GetUser")
+	Signature[DeleteUserRequest, DeleteUserResponse](&c, "DeleteUser")
+	assert.Equal(t, `import * as schemas from "./schemas"
+
+export const procedures = {
+  GetUser: {
+    input: schemas.APIGetUserRequestSchema,
+    output: schemas.APIGetUserResponseSchema,
+  },
+  DeleteUser: {
+    input: schemas.APIDeleteUserRequestSchema,
+    output: schemas.APIDeleteUserResponseSchema,
+  },
+} as const
+
+export type Procedures = {
+  GetUser: {
+    input: schemas.APIGetUserRequest,
+    output: schemas.APIGetUserResponse,
+  },
+  DeleteUser: {
+    input: schemas.APIDeleteUserRequest,
+    output: schemas.APIDeleteUserResponse,
+  },
+}
+`, c.ExportProcedures("./schemas"))
+
+	assert.PanicsWithValue(t, "duplicate signature: GetUser", func() {
+		Signature[GetUserParams, User](&c, "GetUser")
+	})
+}
@@ -0,0 +1,68 @@
+package zen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ExportSpec returns a Vitest spec parsing the fixtures against the schemas
+// converted so far, as a starting point for contract tests. Valid fixtures are
+// expected to be accepted and the others to be rejected. Structs without
+// fixtures get a todo test. schemasModule is the import path of the exported
+// schemas relative to the spec, eg. "./schemas".
+func (c *Converter) ExportSpec(schemasModule string, fixtures []Fixture) (string, error) {
+	valid := map[string][]json.RawMessage{}
+	invalid := map[string][]json.RawMessage{}
+	for _, fixture := range fixtures {
+		name, payload, err := c.fixturePayload(fixture)
+		if err != nil {
+			return "", err
+		}
+		if fixture.Valid {
+			valid[name] = append(valid[name], payload)
+		} else {
+			invalid[name] = append(invalid[name], payload)
+		}
+	}
+
+	sorted := c.convertedStructs()
+
+	var output strings.Builder
+	output.WriteString("import { describe, expect, it } from \"vitest\"\n")
+	output.WriteString(fmt.Sprintf("import * as schemas from %s\n", strconv.Quote(schemasModule)))
+
+	for _, name := range sorted {
+		schema := schemaName(c.prefix, name)
+		var tests []string
+		if payloads := valid[name]; len(payloads) > 0 {
+			tests = append(tests, specCases(schema, "accepts valid fixture %#", payloads, true))
+		}
+		if payloads := invalid[name]; len(payloads) > 0 {
+			tests = append(tests, specCases(schema, "rejects invalid fixture %#", payloads, false))
+		}
+		if len(tests) == 0 {
+			tests = append(tests, fmt.Sprintf("%sit.todo(\"parses fixtures\")\n", indentation(1)))
+		}
+
+		output.WriteString(fmt.Sprintf("\ndescribe(%s, () => {\n%s})\n", strconv.Quote(schema), strings.Join(tests, "\n")))
+	}
+
+	return output.String(), nil
+}
+
+// specCases returns a parametrized test expecting the schema to accept or
+// reject each of the payloads.
+func specCases(schema, title string, payloads []json.RawMessage, accepted bool) string {
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("%sit.each([\n", indentation(1)))
+	for _, payload := range payloads {
+		output.WriteString(fmt.Sprintf("%s{ payload: %s },\n", indentation(2), payload))
+	}
+	output.WriteString(fmt.Sprintf("%s])(%s, ({ payload }) => {\n", indentation(1), strconv.Quote(title)))
+	output.WriteString(fmt.Sprintf("%sexpect(schemas.%s.safeParse(payload).success).toBe(%t)\n", indentation(2), schema, accepted))
+	output.WriteString(fmt.Sprintf("%s})\n", indentation(1)))
+
+	return output.String()
+}
@@ -0,0 +1,60 @@
+package zen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSpec(t *testing.T) {
+	type Post struct {
+		Title string `json:"title"`
+	}
+	type User struct {
+		Name  string `json:"name" validate:"required"`
+		Posts []Post `json:"posts"`
+	}
+
+	c := NewConverterWithOpts(WithPrefix("Api"))
+	c.AddType(User{})
+	spec, err := c.ExportSpec("./schemas", []Fixture{
+		{Type: User{}, Value: User{Name: "John", Posts: []Post{{Title: "Hello"}}}, Valid: true},
+		{Type: User{}, Value: json.RawMessage(`{"name":"","posts":null}`), Valid: false},
+		{Type: User{}, Value: User{Name: "Jane"}, Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `import { describe, expect, it } from "vitest"
+import * as schemas from "./schemas"
+
+describe("ApiPostSchema", () => {
+  it.todo("parses fixtures")
+})
+
+describe("ApiUserSchema", () => {
+  it.each([
+    { payload: {"name":"John","posts":[{"title":"Hello"}]} },
+    { payload: {"name":"Jane","posts":null} },
+  ])("accepts valid fixture %#", ({ payload }) => {
+    expect(schemas.ApiUserSchema.safeParse(payload).success).toBe(true)
+  })
+
+  it.each([
+    { payload: {"name":"","posts":null} },
+  ])("rejects invalid fixture %#", ({ payload }) => {
+    expect(schemas.ApiUserSchema.safeParse(payload).success).toBe(false)
+  })
+})
+`, spec)
+}
+
+func TestExportSpecUnconverted(t *testing.T) {
+	type User struct {
+		Name string
+	}
+
+	c := NewConverter(nil)
+	_, err := c.ExportSpec("./schemas", []Fixture{{Type: User{}, Value: User{}, Valid: true}})
+	assert.EqualError(t, err, "fixture type User has not been converted")
+}
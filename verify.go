@@ -0,0 +1,252 @@
+package zen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Fixture is a sample payload used by VerifyNode. Value is marshalled with
+// encoding/json and parsed with the schema of Type, which must have been
+// converted beforehand. Values produced by marshalling Go values are expected to
+// be Valid, while invalid payloads can be given as json.RawMessage.
+type Fixture struct {
+	Type  interface{}
+	Value interface{}
+	Valid bool
+}
+
+// VerifyFailure reports a fixture whose payload was rejected although valid, or
+// accepted although invalid, by the generated schema.
+type VerifyFailure struct {
+	Fixture Fixture
+	Schema  string
+	Payload string
+	// Error holds the zod error for rejected payloads.
+	Error string
+}
+
+// NodeCommand is the command VerifyNode uses to run node. Node must support
+// stripping TypeScript types, ie. node 22.6 or later.
+var NodeCommand = "node"
+
+// VerifyNode runs the schemas converted so far under node against the fixtures
+// and reports the fixtures for which the schemas disagree with the expected
+// validity. Temporary files are written to a directory created inside dir, so
+// dir must be able to resolve the zod package, eg. a frontend project root.
+func (c *Converter) VerifyNode(dir string, fixtures []Fixture) ([]VerifyFailure, error) {
+	return c.VerifyNodeContext(context.Background(), dir, fixtures)
+}
+
+// VerifyNodeContext is like VerifyNode, but kills node and returns the context
+// error once ctx is done.
+func (c *Converter) VerifyNodeContext(ctx context.Context, dir string, fixtures []Fixture) ([]VerifyFailure, error) {
+	nodeFixtures, err := c.nodeFixtures(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := "import { z } from \"zod\"\n\n" + c.ExportRegexes() + "\n" + c.exportSchemas(nil)
+	results, err := verifyNode(ctx, dir, "./schemas.mts", map[string]string{"schemas.mts": schemas}, nodeFixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []VerifyFailure
+	for i, result := range results {
+		if result.Accepted != fixtures[i].Valid {
+			failures = append(failures, VerifyFailure{
+				Fixture: fixtures[i],
+				Schema:  nodeFixtures[i].Schema,
+				Payload: string(nodeFixtures[i].Payload),
+				Error:   result.Error,
+			})
+		}
+	}
+
+	return failures, nil
+}
+
+// ExportFixtures returns the fixtures as a JSON corpus for the zen verify
+// command, see VerifyNodeFile.
+func (c *Converter) ExportFixtures(fixtures []Fixture) ([]byte, error) {
+	nodeFixtures, err := c.nodeFixtures(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(nodeFixtures, "", "  ")
+}
+
+// VerifyNodeFile is like VerifyNodeContext, but runs the schemas of a module
+// written beforehand against a corpus exported with ExportFixtures, as done by
+// `zen verify --node`. The module must import zod itself and is run in place,
+// so that its imports are resolved from its own directory. The Fixture of the
+// failures is left empty.
+func VerifyNodeFile(ctx context.Context, schemasFile string, corpus []byte) ([]VerifyFailure, error) {
+	var nodeFixtures []nodeFixture
+	if err := json.Unmarshal(corpus, &nodeFixtures); err != nil {
+		return nil, fmt.Errorf("parse fixtures: %w", err)
+	}
+	for i, fixture := range nodeFixtures {
+		var payload bytes.Buffer
+		if err := json.Compact(&payload, fixture.Payload); err != nil {
+			return nil, fmt.Errorf("parse fixtures: %w", err)
+		}
+		nodeFixtures[i].Payload = payload.Bytes()
+	}
+
+	path, err := filepath.Abs(schemasFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	// Windows paths start with a drive letter.
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	module := (&url.URL{Scheme: "file", Path: path}).String()
+	results, err := verifyNode(ctx, "", module, nil, nodeFixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []VerifyFailure
+	for i, result := range results {
+		if result.Accepted != nodeFixtures[i].Valid {
+			failures = append(failures, VerifyFailure{
+				Schema:  nodeFixtures[i].Schema,
+				Payload: string(nodeFixtures[i].Payload),
+				Error:   result.Error,
+			})
+		}
+	}
+
+	return failures, nil
+}
+
+// nodeFixture is a fixture as passed to node, see ExportFixtures.
+type nodeFixture struct {
+	Schema  string          `json:"schema"`
+	Payload json.RawMessage `json:"payload"`
+	Valid   bool            `json:"valid"`
+}
+
+// nodeFixtures marshals the payloads of the fixtures.
+func (c *Converter) nodeFixtures(fixtures []Fixture) ([]nodeFixture, error) {
+	nodeFixtures := make([]nodeFixture, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		name, payload, err := c.fixturePayload(fixture)
+		if err != nil {
+			return nil, err
+		}
+		nodeFixtures = append(nodeFixtures, nodeFixture{schemaName(c.prefix, name), payload, fixture.Valid})
+	}
+
+	return nodeFixtures, nil
+}
+
+// nodeResult is the outcome of parsing the payload of a fixture under node.
+type nodeResult struct {
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error"`
+}
+
+// verifyNode parses the payloads of the fixtures with the schemas exported by
+// module under node, and returns the results in fixture order. The script and
+// the given files are written to a temporary directory created inside dir, or
+// the default directory for temporary files if dir is empty.
+func verifyNode(ctx context.Context, dir, module string, files map[string]string, fixtures []nodeFixture) ([]nodeResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp(dir, ".zen-verify-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	fixturesJSON, err := json.Marshal(fixtures)
+	if err != nil {
+		return nil, err
+	}
+
+	all := map[string]string{
+		"fixtures.json": string(fixturesJSON),
+		"verify.mts":    fmt.Sprintf(verifyScript, strconv.Quote(module)),
+	}
+	for name, content := range files {
+		all[name] = content
+	}
+	for name, content := range all {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte(content), 0o600); err != nil {
+			return nil, err
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, NodeCommand, "--experimental-strip-types", "--no-warnings", filepath.Join(tmp, "verify.mts"))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("run node: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("run node: %w: %s", err, stderr.String())
+	}
+
+	var results []nodeResult
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return nil, fmt.Errorf("parse node output: %w", err)
+	}
+	if len(results) != len(fixtures) {
+		return nil, fmt.Errorf("node returned %d results for %d fixtures", len(results), len(fixtures))
+	}
+
+	return results, nil
+}
+
+// fixturePayload returns the name of the converted struct a fixture is for,
+// along with its marshalled value.
+func (c *Converter) fixturePayload(fixture Fixture) (string, json.RawMessage, error) {
+	t := reflect.TypeOf(fixture.Type)
+	if t == nil || t.Kind() != reflect.Struct {
+		return "", nil, fmt.Errorf("fixture type must be a struct, got %v", t)
+	}
+
+	name := c.structName(t)
+	if _, ok := c.outputs[name]; !ok {
+		return "", nil, fmt.Errorf("fixture type %s has not been converted", name)
+	}
+
+	payload, err := json.Marshal(fixture.Value)
+	if err != nil {
+		return "", nil, fmt.Errorf("marshal fixture for %s: %w", name, err)
+	}
+
+	return name, payload, nil
+}
+
+const verifyScript = `import { readFileSync } from "node:fs"
+import * as schemas from %s
+
+const fixtures = JSON.parse(readFileSync(new URL("./fixtures.json", import.meta.url), "utf8"))
+const results = fixtures.map(({ schema, payload }) => {
+  const result = schemas[schema].safeParse(payload)
+  return { accepted: result.success, error: result.success ? "" : result.error.message }
+})
+process.stdout.write(JSON.stringify(results))
+`
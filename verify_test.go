@@ -0,0 +1,118 @@
+package zen
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyNodeUnconverted(t *testing.T) {
+	type User struct {
+		Name string
+	}
+
+	c := NewConverter(nil)
+	_, err := c.VerifyNode(t.TempDir(), []Fixture{{Type: User{}, Value: User{}, Valid: true}})
+	assert.EqualError(t, err, "fixture type User has not been converted")
+
+	_, err = c.VerifyNode(t.TempDir(), []Fixture{{Type: "User", Value: User{}, Valid: true}})
+	assert.Error(t, err)
+}
+
+func TestVerifyNodeContext(t *testing.T) {
+	type User struct {
+		Name string
+	}
+
+	c := NewConverter(nil)
+	c.AddType(User{})
+	fixtures := []Fixture{{Type: User{}, Value: User{}, Valid: true}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.VerifyNodeContext(ctx, t.TempDir(), fixtures)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	if runtime.GOOS == "windows" {
+		t.Skip("no sleep command")
+	}
+	// sleep fails on the node flags in the arguments, so use a shell ignoring them
+	script := t.TempDir() + "/node"
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 10\n"), 0o700))
+	defer func(cmd string) { NodeCommand = cmd }(NodeCommand)
+	NodeCommand = script
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = c.VerifyNodeContext(ctx, t.TempDir(), fixtures)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+// TestVerifyNode requires node 22.6 or later and ZEN_VERIFY_DIR pointing to a
+// directory that can resolve the zod package.
+func TestVerifyNode(t *testing.T) {
+	dir := os.Getenv("ZEN_VERIFY_DIR")
+	if dir == "" {
+		t.Skip("ZEN_VERIFY_DIR not set")
+	}
+
+	type User struct {
+		Name  string   `json:"name" validate:"required"`
+		Age   int      `json:"age" validate:"gte=18"`
+		Email string   `json:"email" validate:"email"`
+		Tags  []string `json:"tags"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(User{})
+	failures, err := c.VerifyNode(dir, []Fixture{
+		{Type: User{}, Value: User{Name: "John", Age: 18, Email: "john@example.com"}, Valid: true},
+		// rejected by the schema, since Go allows an empty email without omitempty
+		{Type: User{}, Value: User{Name: "John", Age: 18}, Valid: true},
+		{Type: User{}, Value: json.RawMessage(`{"name":"","age":18,"email":"a@b.co","tags":null}`), Valid: false},
+		// accepted by the schema
+		{Type: User{}, Value: json.RawMessage(`{"name":"John","age":18,"email":"a@b.co","tags":[]}`), Valid: false},
+	})
+	require.NoError(t, err)
+	require.Len(t, failures, 2)
+
+	assert.Equal(t, "UserSchema", failures[0].Schema)
+	assert.Equal(t, `{"name":"John","age":18,"email":"","tags":null}`, failures[0].Payload)
+	assert.Contains(t, failures[0].Error, "Invalid email")
+	assert.Equal(t, `{"name":"John","age":18,"email":"a@b.co","tags":[]}`, failures[1].Payload)
+	assert.Empty(t, failures[1].Error)
+}
+
+// TestVerifyNodeCoercePrimitives requires node and ZEN_VERIFY_DIR, see
+// TestVerifyNode.
+func TestVerifyNodeCoercePrimitives(t *testing.T) {
+	dir := os.Getenv("ZEN_VERIFY_DIR")
+	if dir == "" {
+		t.Skip("ZEN_VERIFY_DIR not set")
+	}
+
+	type Query struct {
+		Active bool `json:"active" validate:"required"`
+		Page   int  `json:"page"`
+	}
+
+	c := NewConverterWithOpts(WithCoercePrimitives())
+	c.AddType(Query{})
+	failures, err := c.VerifyNode(dir, []Fixture{
+		{Type: Query{}, Value: json.RawMessage(`{"active":"true","page":"2"}`), Valid: true},
+		{Type: Query{}, Value: json.RawMessage(`{"active":true,"page":2}`), Valid: true},
+		// "false" is false, failing required
+		{Type: Query{}, Value: json.RawMessage(`{"active":"false","page":"2"}`), Valid: false},
+		{Type: Query{}, Value: json.RawMessage(`{"active":"yes","page":"2"}`), Valid: false},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, failures)
+}
@@ -1,12 +1,24 @@
 package zen
 
 import (
+	"context"
+	"encoding"
 	"fmt"
+	"go/token"
+	"maps"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // NewConverter initializes and returns a new converter instance. The custom handler
@@ -14,643 +26,3927 @@ import (
 // type arguments), ie. package.typename.
 func NewConverter(custom map[string]CustomFn) Converter {
 	c := Converter{
-		prefix:  "",
-		outputs: make(map[string]entry),
-		custom:  custom,
+		settings: settings{custom: custom},
+		outputs:  make(map[string]entry),
 	}
 
 	return c
 }
 
-// AddType converts a struct type to corresponding zod schema. AddType can be called
-// multiple times, followed by Export to get the corresonding zod schemas.
-func (c *Converter) AddType(input interface{}) {
-	t := reflect.TypeOf(input)
+// Opt is a functional option for NewConverterWithOpts.
+type Opt func(*Converter)
 
-	if t.Kind() != reflect.Struct {
-		panic("input must be a struct")
+// NewConverterWithOpts initializes and returns a new converter instance
+// configured with the given options.
+func NewConverterWithOpts(opts ...Opt) Converter {
+	c := Converter{
+		outputs: make(map[string]entry),
 	}
 
-	name := typeName(t)
-	if _, ok := c.outputs[name]; ok {
-		return
+	for _, opt := range opts {
+		opt(&c)
 	}
 
-	data := c.convertStructTopLevel(t)
-	order := c.structs
-	c.outputs[name] = entry{order, data}
-	c.structs = order + 1
+	return c
 }
 
-// Convert returns zod schema corresponding to a struct type. Its a shorthand for
-// call to AddType followed by Export. So calling Convert after other calls to
-// AddType/Convert/ConvertSlice, returns schemas from those previous calls as well.
-// Calling AddType followed by Export might be more appropriate in such scenarios.
-func (c *Converter) Convert(input interface{}) string {
-	c.AddType(input)
-
-	return c.Export()
+// WithCustomTypes sets the custom type handlers, keyed on the fully qualified
+// type name as described in NewConverter.
+func WithCustomTypes(custom map[string]CustomFn) Opt {
+	return func(c *Converter) {
+		c.custom = custom
+	}
 }
 
-// ConvertSlice returns zod schemas corresponding to multiple struct types passed
-// in the argument. Similar to Convert, calling ConvertSlice after other calls to
-// AddType/Convert/ConvertSlice, returns schemas from those previous calls as well.
-// Calling AddType followed by Export might be more appropriate in such scenarios.
-func (c *Converter) ConvertSlice(inputs []interface{}) string {
-	for _, input := range inputs {
-		c.AddType(input)
+// WithCustomTSTypes sets the handlers returning the TS types of custom types,
+// keyed like WithCustomTypes, for the explicit TS types of WithExplicitTypes and
+// self-referential structs. Custom types without one are typed as unknown.
+func WithCustomTSTypes(custom map[string]CustomFn) Opt {
+	return func(c *Converter) {
+		c.customTS = custom
 	}
-
-	return c.Export()
 }
 
-// StructToZodSchema returns zod schema corresponding to a struct type.
-func StructToZodSchema(input interface{}) string {
-	c := Converter{
-		prefix:  "",
-		outputs: make(map[string]entry),
+// WithPrefix sets the prefix added to the generated schema and type names.
+func WithPrefix(prefix string) Opt {
+	return func(c *Converter) {
+		c.prefix = prefix
 	}
-
-	return c.Convert(input)
 }
 
-// StructToZodSchemaWithPrefix returns zod schema corresponding to a struct type.
-// The prefix is added to the generated schema and type names.
-func StructToZodSchemaWithPrefix(prefix string, input interface{}) string {
-	c := Converter{
-		prefix:  prefix,
-		outputs: make(map[string]entry),
+// WithIgnoreTags sets the validation tags that are skipped during conversion,
+// same as SetIgnores.
+func WithIgnoreTags(ignores ...string) Opt {
+	return func(c *Converter) {
+		c.ignores = ignores
 	}
-
-	return c.Convert(input)
 }
 
-var typeMapping = map[reflect.Kind]string{
-	reflect.Bool:       "boolean",
-	reflect.Int:        "number",
-	reflect.Int8:       "number",
-	reflect.Int16:      "number",
-	reflect.Int32:      "number",
-	reflect.Int64:      "number",
-	reflect.Uint:       "number",
-	reflect.Uint8:      "number",
-	reflect.Uint16:     "number",
-	reflect.Uint32:     "number",
-	reflect.Uint64:     "number",
-	reflect.Uintptr:    "number",
-	reflect.Float32:    "number",
-	reflect.Float64:    "number",
-	reflect.Complex64:  "number",
-	reflect.Complex128: "number",
-	reflect.String:     "string",
-	reflect.Interface:  "any",
+// WithIgnoredTagComments adds a trailing comment to the fields with
+// validations skipped by WithIgnoreTags, eg. `// zen: ignored validation "uri"`,
+// so that reviewers of the schemas can see what is not enforced client-side.
+func WithIgnoredTagComments() Opt {
+	return func(c *Converter) {
+		c.ignoredComments = true
+	}
 }
 
-type entry struct {
-	order int
-	data  string
+// WithNameTag names fields after the struct tag with the given key instead of
+// the json tag, eg. "form" or "query" for the binding structs of Gin and Echo,
+// or "schema" for gorilla/schema, to validate query parameters and forms.
+// Fields tagged with "-" are left out, and fields without the tag keep their Go
+// name.
+func WithNameTag(key string) Opt {
+	return func(c *Converter) {
+		c.nameTag = key
+	}
 }
 
-type byOrder []entry
+// WithPackageQualifiedNames prefixes every struct schema and type name with the
+// name of the package the struct is declared in, ie. pkg_a.User becomes
+// PkgAUser. This disambiguates structs sharing a name across packages, which
+// otherwise results in a NameCollisionError.
+func WithPackageQualifiedNames() Opt {
+	return func(c *Converter) {
+		c.qualifyNames = true
+	}
+}
 
-func (a byOrder) Len() int           { return len(a) }
-func (a byOrder) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a byOrder) Less(i, j int) bool { return a[i].order < a[j].order }
+// WithExplicitTypes emits hand-written TS types along with z.ZodType annotated
+// schemas for every struct and event union, as is done for self-referential
+// structs, instead of inferring the types with z.infer. Explicit types keep TS compile times and IDE
+// responsiveness in check for large projects. The TS type of fields with custom
+// types is unknown.
+func WithExplicitTypes() Opt {
+	return func(c *Converter) {
+		c.explicitTypes = true
+	}
+}
 
-type CustomFn func(c *Converter, t reflect.Type, validate string, indent int) string
+// WithExportShapes exports the object shape of every struct as
+// <Name>SchemaShape, so that frontend code can extend schemas, eg.
+// z.object({...UserSchemaShape, extra: z.string()}). Embedded structs are
+// spread into the shape of the embedding struct instead of being merged.
+func WithExportShapes() Opt {
+	return func(c *Converter) {
+		c.exportShapes = true
+	}
+}
 
-type meta struct {
-	name    string
-	selfRef bool
+// WithOmitEmptyZeroValues changes the mapping of string and number fields tagged
+// with omitempty alongside other validations, eg. `validate:"omitempty,min=2"`.
+// go-validator skips the validations for zero values, so the schema also accepts
+// the zero value ("" or 0) with .or(z.literal(...)). The field is optional when
+// it is also tagged with `json:",omitempty"`, as the zero value is then omitted.
+// Without the option such fields reject their zero values.
+func WithOmitEmptyZeroValues() Opt {
+	return func(c *Converter) {
+		c.omitEmptyZeroValues = true
+	}
 }
 
-type Converter struct {
-	prefix  string
-	structs int
-	outputs map[string]entry
-	custom  map[string]CustomFn
-	stack   []meta
-	ignores []string
+// WithRequiredPresenceOnly maps `required` on string, number and bool fields to
+// a plain non-optional, non-nullable schema. By default `required` also rejects
+// the zero value, like go-validator does, with .min(1) for strings,
+// .refine((val) => val !== 0) for numbers and .refine((val) => val !== false)
+// for bools, except behind pointers, which only have to be non-nil. Use this
+// option for APIs where zero and the empty string are legitimate values and
+// required only asserts presence.
+func WithRequiredPresenceOnly() Opt {
+	return func(c *Converter) {
+		c.requiredPresenceOnly = true
+	}
 }
 
-func (c *Converter) addSchema(name string, data string) {
-	// First check if the object already exists. If it does do not replace. This is needed for second order
-	_, ok := c.outputs[name]
-	if !ok {
-		order := c.structs
-		c.outputs[name] = entry{order, data}
-		c.structs = order + 1
+// WithFieldOverride registers a function that can replace the generated schema
+// of specific fields, eg. a unix timestamp int that should be z.coerce.date(),
+// without registering a custom type. The returned snippet is used as is, so it
+// has to include .optional() or .nullable() when needed. The TS type of
+// overridden fields is unknown. The option can be given multiple times, in which
+// case the first function returning true wins.
+func WithFieldOverride(override FieldOverrideFn) Opt {
+	return func(c *Converter) {
+		c.fieldOverrides = append(c.fieldOverrides, override)
 	}
 }
 
-// Export returns the zod schemas corresponding to all types that have been
-// converted so far.
-func (c *Converter) Export() string {
-	output := strings.Builder{}
-	var sorted []entry
-	for _, ent := range c.outputs {
-		sorted = append(sorted, ent)
+// WithSkipFields registers a function leaving out the fields it returns true
+// for, like fields tagged `zen:"skip"`, eg. the associations of ORM models. The
+// option can be given multiple times, in which case the fields skipped by any
+// of the functions are left out.
+func WithSkipFields(skip func(field reflect.StructField) bool) Opt {
+	return func(c *Converter) {
+		c.skipFields = append(c.skipFields, skip)
 	}
+}
 
-	sort.Sort(byOrder(sorted))
+// WithAlwaysLazy emits every struct schema wrapped in z.lazy, annotated with
+// an explicit TS type, ie. export const UserSchema: z.ZodType<User> =
+// z.lazy(() => z.object({...})). Schemas can then reference each other in any
+// order, trading some readability for immunity to declaration order and
+// recursion issues in large, tangled type graphs. Embedded structs are inlined,
+// since there are no shapes to spread.
+func WithAlwaysLazy() Opt {
+	return func(c *Converter) {
+		c.alwaysLazy = true
+	}
+}
 
-	for _, ent := range sorted {
-		output.WriteString(ent.data)
-		output.WriteString("\n\n")
+// WithShapeSuffix sets the suffix of the names of the exported shapes, see
+// WithExportShapes, ie. "Shape" exports UserShape instead of UserSchemaShape.
+// It panics on suffixes that would name the shapes like the schemas.
+func WithShapeSuffix(suffix string) Opt {
+	if suffix == "" || suffix == "Schema" {
+		panic(fmt.Sprintf("invalid shape suffix: %q", suffix))
 	}
 
-	return output.String()
+	return func(c *Converter) {
+		c.shapeSuffix = suffix
+	}
 }
 
-func schemaName(prefix, name string) string {
-	return fmt.Sprintf("%s%sSchema", prefix, name)
+// RecursionStyle sets how self-referential structs are emitted.
+type RecursionStyle int
+
+const (
+	// ShapeRecursion declares the TS type of the struct, exports its shape and
+	// annotates its schema with z.ZodType, referencing the structs of the cycle
+	// through z.lazy().
+	ShapeRecursion RecursionStyle = iota
+	// InterfaceRecursion declares the TS type of the struct as an interface and
+	// annotates its schema with z.ZodType, without exporting its shape.
+	InterfaceRecursion
+	// GetterRecursion references schemas from getters, which Zod 4 infers
+	// recursive types from, eg. `get children() { return NodeSchema.array() }`,
+	// without declaring the TS type of the struct unless WithExplicitTypes is
+	// set, nor exporting its shape.
+	GetterRecursion
+)
+
+// WithRecursionStyle sets how self-referential structs are emitted, unless
+// WithAlwaysLazy is set. Without an exported shape, the fields of these
+// structs are inlined into embedding structs. The default is ShapeRecursion.
+func WithRecursionStyle(style RecursionStyle) Opt {
+	return func(c *Converter) {
+		c.recursionStyle = style
+	}
 }
 
-func fieldName(input reflect.StructField) string {
-	if json := input.Tag.Get("json"); json != "" {
-		args := strings.Split(json, ",")
-		if len(args[0]) > 0 {
-			return args[0]
+// WithExcludeTypes keeps referenced structs matching the given patterns from
+// being converted and exported. Patterns are matched with path.Match against
+// both pkg.TypeName, where pkg is the last element of the package path, and the
+// fully qualified type name, eg. "audit.Metadata" or "audit.*". Fields of an
+// excluded type become z.unknown() and excluded embedded structs are omitted.
+// Types passed to AddType directly are still converted.
+func WithExcludeTypes(patterns ...string) Opt {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("invalid exclude pattern %q: %v", pattern, err))
 		}
-		// This is also valid:
-		// json:",omitempty"
-		// so in this case, args[0] will be empty, so fall through to using the
-		// raw field name.
 	}
 
-	// When Golang marshals a struct to JSON, and it doesn't have any JSON tags
-	// that give the fields names, it defaults to just using the field's name.
-	return input.Name
+	return func(c *Converter) {
+		c.excludes = append(c.excludes, patterns...)
+	}
 }
 
-func typeName(t reflect.Type) string {
-	if t.Kind() == reflect.Struct {
-		return getTypeNameWithGenerics(t.Name())
-	}
-	if t.Kind() == reflect.Ptr {
-		return typeName(t.Elem())
-	}
-	if t.Kind() == reflect.Slice {
-		return typeName(t.Elem())
-	}
-	if t.Kind() == reflect.Map {
-		return typeName(t.Elem())
+// WithOpaqueTypes converts the given types, eg. `WithOpaqueTypes(aws.Config{})`,
+// to z.unknown() and unknown in TS wherever they are referenced instead of
+// converting them, for large third-party types passed through untouched.
+// Unlike WithExcludeTypes, any named type can be opaque and the validations of
+// fields referencing it are ignored. Embedded opaque structs are omitted.
+func WithOpaqueTypes(types ...interface{}) Opt {
+	return func(c *Converter) {
+		if c.opaque == nil {
+			c.opaque = make(map[reflect.Type]bool)
+		}
+		for _, t := range types {
+			c.opaque[reflect.TypeOf(t)] = true
+		}
 	}
-
-	return "UNKNOWN"
 }
 
-func (c *Converter) convertStructTopLevel(t reflect.Type) string {
-	output := strings.Builder{}
+// Target is what is exported for a struct, see WithTarget.
+type Target int
 
-	name := typeName(t)
-	c.stack = append(c.stack, meta{name, false})
+const (
+	// ZodTarget exports the zod schema of a struct along with its TS type.
+	ZodTarget Target = iota
+	// TypeTarget exports only the TS type of a struct, for consumers that do
+	// not validate, unless the schema of a struct exported with ZodTarget
+	// references its schema.
+	TypeTarget
+)
 
-	data := c.convertStruct(t, 0)
-	fullName := c.prefix + name
+// WithTarget sets what is exported for the structs matching the given
+// patterns, matched like those of WithExcludeTypes, eg.
+// `WithTarget(TypeTarget, "admin.*")`, so that one converter serves consumers
+// needing schemas and consumers needing types only. Structs not matching any
+// pattern get ZodTarget, and the last matching option wins.
+func WithTarget(target Target, patterns ...string) Opt {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			panic(fmt.Sprintf("invalid target pattern %q: %v", pattern, err))
+		}
+	}
 
-	top := c.stack[len(c.stack)-1]
-	if top.selfRef {
-		output.WriteString(fmt.Sprintf(`export type %s = %s
-`, fullName, c.getTypeStruct(t, 0)))
+	return func(c *Converter) {
+		c.targets = append(c.targets, targetPatterns{target, patterns})
+	}
+}
 
-		output.WriteString(fmt.Sprintf(
-			`export const %s: z.ZodType<%s> = %s`, schemaName(c.prefix, name), fullName, data))
-	} else {
-		output.WriteString(fmt.Sprintf(
-			`export const %s = %s
-`,
-			schemaName(c.prefix, name), data))
+// targetPatterns holds the patterns given to WithTarget.
+type targetPatterns struct {
+	target   Target
+	patterns []string
+}
 
-		output.WriteString(fmt.Sprintf(`export type %s = z.infer<typeof %s>`,
-			fullName, schemaName(c.prefix, name)))
+// target returns what is exported for a struct, see WithTarget.
+func (c *Converter) target(t reflect.Type) Target {
+	for i := len(c.targets) - 1; i >= 0; i-- {
+		if matchesTypePatterns(t, c.targets[i].patterns) {
+			return c.targets[i].target
+		}
 	}
 
-	c.stack = c.stack[:len(c.stack)-1]
+	return ZodTarget
+}
 
-	return output.String()
+// WithMaxDepth bounds the nesting of struct schemas, counting both named and
+// anonymous structs. Converting a type nested deeper than n panics, see
+// TryAddType. Combined with WithLazyDepth only anonymous structs count towards
+// the limit, as named structs are referenced lazily beyond the lazy depth.
+func WithMaxDepth(n int) Opt {
+	return func(c *Converter) {
+		c.maxDepth = n
+	}
 }
 
-func (c *Converter) convertStruct(input reflect.Type, indent int) string {
-	output := strings.Builder{}
+// WithLazyDepth references named structs nested n or more levels deep as
+// z.lazy(() => XSchema) instead of converting them in place. Their schemas are
+// converted once the current type is done and are exported after it. This
+// keeps the conversion of very deep type graphs shallow.
+func WithLazyDepth(n int) Opt {
+	return func(c *Converter) {
+		c.lazyDepth = n
+	}
+}
 
-	output.WriteString(`z.object({
-`)
+// WithObjectChunkSize splits the schemas of structs with more than n fields
+// into objects of at most n fields merged together, ie. z.object({...}).merge(
+// z.object({...})). Monolithic z.object literals with hundreds of fields can
+// blow up TS inference, which chunking avoids. Exported shapes, see
+// WithExportShapes and WithExplicitTypes, are never split.
+func WithObjectChunkSize(n int) Opt {
+	return func(c *Converter) {
+		c.chunkSize = n
+	}
+}
 
-	merges := []string{}
+// TimeFormat selects the schema of time.Time fields, see WithTimeFormat.
+type TimeFormat int
+
+const (
+	// CoerceDate maps time.Time to z.coerce.date(), typed as Date.
+	CoerceDate TimeFormat = iota
+	// ISOStringDatetime maps time.Time to z.string().datetime({ offset: true }),
+	// typed as string, matching the RFC 3339 strings encoding/json produces.
+	ISOStringDatetime
+	// UnixNumber maps time.Time to z.number(), for APIs serializing times as
+	// unix timestamps. Map keys are still RFC 3339 strings, as encoding/json
+	// encodes them with MarshalText, and use the schema of ISOStringDatetime.
+	UnixNumber
+)
 
-	fields := input.NumField()
-	for i := 0; i < fields; i++ {
-		field := input.Field(i)
-		optional := isOptional(field)
-		nullable := isNullable(field)
+// WithTimeFormat sets the schema of time.Time fields. The default is
+// CoerceDate.
+func WithTimeFormat(format TimeFormat) Opt {
+	return func(c *Converter) {
+		c.timeFormat = format
+	}
+}
 
-		line, shouldMerge := c.convertField(field, indent+1, optional, nullable, field.Anonymous)
+// ConversionStats describes the conversion of a single named struct, as
+// reported to the function given to WithProfiler.
+type ConversionStats struct {
+	Type reflect.Type
+	Name string
+	// Fields is the number of struct fields, including skipped ones.
+	Fields int
+	// Duration includes the conversion of the structs referenced for the
+	// first time, which are reported before the struct referencing them.
+	Duration time.Duration
+}
 
-		if !shouldMerge {
-			output.WriteString(line)
-		} else {
-			merges = append(merges, line)
-		}
+// WithProfiler registers a function called after every named struct has been
+// converted, to help diagnose slow generation of large type graphs.
+func WithProfiler(profile func(ConversionStats)) Opt {
+	return func(c *Converter) {
+		c.profile = profile
 	}
+}
 
-	output.WriteString(indentation(indent))
-	output.WriteString(`})`)
-	if len(merges) > 0 {
-		for _, merge := range merges {
-			output.WriteString(merge)
-		}
+// WithInt64AsBigInt maps int64 and uint64 to z.bigint(), typed as bigint, so
+// that IDs above 2^53 don't silently lose precision. z.bigint() rejects the
+// numbers of JSON.parse, so payloads have to be parsed with a JSON parser
+// producing bigints. Map keys are not affected.
+func WithInt64AsBigInt() Opt {
+	return func(c *Converter) {
+		c.int64Type = "bigint"
 	}
-
-	return output.String()
 }
 
-func (c *Converter) getTypeStruct(input reflect.Type, indent int) string {
-	output := strings.Builder{}
-
-	output.WriteString(`{
-`)
-
-	fields := input.NumField()
-	for i := 0; i < fields; i++ {
-		field := input.Field(i)
-		optional := isOptional(field)
-		nullable := isNullable(field)
-
-		line := c.getTypeField(field, indent+1, optional, nullable)
+// WithInt64AsString maps int64 and uint64 to a z.string() of digits, for APIs
+// encoding them as strings, eg. with the `json:",string"` option. Map keys are
+// not affected.
+func WithInt64AsString() Opt {
+	return func(c *Converter) {
+		c.int64Type = "string"
+	}
+}
 
-		output.WriteString(line)
+// WithoutIntChecks maps integer kinds to a plain z.number(). By default they
+// are mapped to z.number().int(), rejecting fractional values like encoding/json
+// does when unmarshalling into integers. Unsigned kinds still reject negative
+// values with .nonnegative().
+func WithoutIntChecks() Opt {
+	return func(c *Converter) {
+		c.noIntChecks = true
 	}
+}
 
-	output.WriteString(indentation(indent))
-	output.WriteString(`}`)
+// WithIntegerBounds adds the range of 8, 16 and 32 bit integer kinds to their
+// schemas, eg. .gte(-128).lte(127) for int8, so that overflows are caught
+// before reaching the server.
+func WithIntegerBounds() Opt {
+	return func(c *Converter) {
+		c.integerBounds = true
+	}
+}
 
-	return output.String()
+// WithCoercePrimitives coerces number and bigint fields, including unix
+// timestamps, with z.coerce, eg. z.coerce.number(), for schemas validating URL
+// query parameters and form payloads, whose values all arrive as strings.
+// Boolean fields accept the strings "true" and "false", which z.coerce.boolean()
+// would both map to true.
+func WithCoercePrimitives() Opt {
+	return func(c *Converter) {
+		c.coercePrimitives = true
+	}
 }
 
-var matchGenericTypeName = regexp.MustCompile(`(.+)\[(.+)]`)
+// WithComplexAsObject maps complex64 and complex128 to z.object({ re, im }).
+// encoding/json cannot marshal complex numbers, so by default converting them
+// panics, while with this option the API is expected to encode them as objects
+// of their real and imaginary parts.
+func WithComplexAsObject() Opt {
+	return func(c *Converter) {
+		c.complexAsObject = true
+	}
+}
 
-// Checking if a reflected type is a generic isn't supported as far as I can see.
-// So this simple check looks for a `[` character in the type name: `T1[T2]`.
-func isGeneric(t reflect.Type) bool {
-	return strings.Contains(t.Name(), "[")
+// WithAudience sets the audiences the schemas are generated for. Fields tagged
+// `zen-only:"admin,support"` are only converted for converters with one of the
+// listed audiences and are left out otherwise, so that public and internal
+// frontends can share the same structs.
+func WithAudience(audiences ...string) Opt {
+	return func(c *Converter) {
+		c.audiences = append(c.audiences, audiences...)
+	}
 }
 
-// Gets the full type name (package+type), stripping out generic type arguments.
-func getFullName(t reflect.Type) string {
-	var typename string
+// SensitiveMode sets how fields tagged `sensitive:"true"` are converted.
+type SensitiveMode int
+
+const (
+	// SensitiveInclude converts sensitive fields like any other field.
+	SensitiveInclude SensitiveMode = iota
+	// SensitiveOmit leaves sensitive fields out of the schemas.
+	SensitiveOmit
+	// SensitiveNever maps sensitive fields to z.never().optional(), typed as
+	// never, so that payloads leaking them are rejected. Embedded sensitive
+	// structs are omitted.
+	SensitiveNever
+)
 
-	if isGeneric(t) {
-		m := matchGenericTypeName.FindAllStringSubmatch(t.Name(), 1)[0]
-		typename = m[1]
-	} else {
-		typename = t.Name()
+// WithSensitiveFields sets how fields tagged `sensitive:"true"` are converted,
+// to keep internal-only fields out of public schemas. The default is
+// SensitiveInclude.
+func WithSensitiveFields(mode SensitiveMode) Opt {
+	return func(c *Converter) {
+		c.sensitiveMode = mode
 	}
-
-	return fmt.Sprintf("%s.%s", t.PkgPath(), typename)
 }
 
-func (c *Converter) handleCustomType(t reflect.Type, validate string, indent int) (string, bool) {
-	fullName := getFullName(t)
+// UnexportedMode sets how unexported struct fields are converted.
+type UnexportedMode int
+
+const (
+	// UnexportedInclude converts unexported fields like any other field.
+	UnexportedInclude UnexportedMode = iota
+	// UnexportedSkip leaves the unexported fields ignored by encoding/json out
+	// of the schemas.
+	UnexportedSkip
+	// UnexportedError panics on the unexported fields ignored by encoding/json,
+	// see TryAddType, unless they are skipped, eg. with `json:"-"`.
+	UnexportedError
+)
 
-	custom, ok := c.custom[fullName]
-	if ok {
-		return custom(c, t, validate, indent), true
+// WithUnexportedFields sets how unexported fields are converted. encoding/json
+// ignores them, except for embedded structs and pointers to structs, whose
+// exported fields are promoted. With UnexportedSkip and UnexportedError, these fields are inlined
+// into the embedding struct, keeping the unexported struct out of the output.
+// The default is UnexportedInclude.
+func WithUnexportedFields(mode UnexportedMode) Opt {
+	return func(c *Converter) {
+		c.unexportedMode = mode
 	}
-
-	return "", false
 }
 
-// ConvertType should be called from custom converter functions.
-func (c *Converter) ConvertType(t reflect.Type, validate string, indent int) string {
-	if t.Kind() == reflect.Ptr {
-		inner := t.Elem()
-		validate = strings.TrimPrefix(validate, "omitempty")
-		validate = strings.TrimPrefix(validate, ",")
-		return c.ConvertType(inner, validate, indent)
+// WithErrorSchema maps fields of the error interface type, which are otherwise
+// mapped to z.any(), to the union of the given error structs, exported as
+// <name>Schema. Without error structs, errors are mapped to z.string(), for
+// APIs encoding errors as their messages. Unlike other interfaces, nil errors
+// are treated like nil pointers, ie. nullable unless required or omitted.
+func WithErrorSchema(name string, errs ...interface{}) Opt {
+	types := make([]reflect.Type, 0, len(errs))
+	for _, err := range errs {
+		t := reflect.TypeOf(err)
+		if t != nil {
+			t = elemType(t)
+		}
+		if t == nil || t.Kind() != reflect.Struct ||
+			!t.Implements(errorType) && !reflect.PointerTo(t).Implements(errorType) {
+			panic(fmt.Sprintf("error schema type must be a struct implementing error, got %T", err))
+		}
+		types = append(types, t)
 	}
 
-	// Custom types should be handled before maps/slices, as we might have
-	// custom types that are maps/slices.
-	if custom, ok := c.handleCustomType(t, validate, indent); ok {
-		return custom
+	return func(c *Converter) {
+		c.errorName = name
+		c.errorTypes = types
 	}
+}
 
-	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
-		return c.convertSliceAndArray(t, validate, indent)
+// WithInterfaceUnion maps fields of an interface type, which are otherwise
+// mapped to z.any(), to the union of the given structs implementing it, eg.
+// WithInterfaceUnion((*Payload)(nil), Created{}, Deleted{}) maps Payload and
+// []Payload to z.union([CreatedSchema, DeletedSchema]) and its array. Like
+// errors mapped with WithErrorSchema, nil interfaces are treated like nil
+// pointers, ie. nullable unless required or omitted.
+func WithInterfaceUnion(iface interface{}, impls ...interface{}) Opt {
+	t := reflect.TypeOf(iface)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("interface union must be given a pointer to an interface, got %T", iface))
 	}
-
-	if t.Kind() == reflect.Map {
-		return c.convertMap(t, validate, indent)
+	t = t.Elem()
+	if len(impls) == 0 {
+		panic(fmt.Sprintf("interface union of %s must have at least one struct", t))
 	}
 
-	if t.Kind() == reflect.Struct {
-		name := typeName(t)
-
-		if name == "" {
-			// Handle fields with non-defined types - these are inline.
-			return c.convertStruct(t, indent)
-		} else if name == "Time" {
-			var validateStr string
-			if validate != "" {
-				// We compare with both the zero value from go and the zero value that zod coerces to
-				if validate == "required" {
-					validateStr = ".refine((val) => val.getTime() !== new Date('0001-01-01T00:00:00Z').getTime() && val.getTime() !== new Date(0).getTime(), 'Invalid date')"
-				}
-			}
-			// timestamps are to be coerced to date by zod. JSON.parse only serializes to string
-			return "z.coerce.date()" + validateStr
-		} else {
-			if c.stack[len(c.stack)-1].name == name {
-				c.stack[len(c.stack)-1].selfRef = true
-				return fmt.Sprintf("z.lazy(() => %s)", schemaName(c.prefix, name))
-			}
-			// throws panic if there is a cycle
-			detectCycle(name, c.stack)
-			c.addSchema(name, c.convertStructTopLevel(t))
-			return schemaName(c.prefix, name)
+	types := make([]reflect.Type, 0, len(impls))
+	for _, impl := range impls {
+		it := reflect.TypeOf(impl)
+		if it != nil {
+			it = elemType(it)
 		}
+		if it == nil || it.Kind() != reflect.Struct ||
+			!it.Implements(t) && !reflect.PointerTo(it).Implements(t) {
+			panic(fmt.Sprintf("interface union type must be a struct implementing %s, got %T", t, impl))
+		}
+		types = append(types, it)
 	}
 
-	// boolean, number, string, any
-	zodType, ok := typeMapping[t.Kind()]
-	if !ok {
-		panic(fmt.Sprint("cannot handle: ", t.Kind()))
+	return func(c *Converter) {
+		if c.unions == nil {
+			c.unions = make(map[reflect.Type][]reflect.Type)
+		}
+		c.unions[t] = types
 	}
+}
 
-	var validateStr string
-	if validate != "" {
-		switch zodType {
-		case "string":
-			validateStr = c.validateString(validate)
-			if strings.Contains(validateStr, ".enum(") {
-				return "z" + validateStr
-			}
-		case "number":
-			validateStr = c.validateNumber(validate)
+// WithTagAliases expands validation aliases, as registered with go-validator's
+// RegisterAlias, before translating validate tags, eg. {"name": "min=2,max=50"}.
+// Aliases may expand to other aliases.
+func WithTagAliases(aliases map[string]string) Opt {
+	return func(c *Converter) {
+		if c.aliases == nil {
+			c.aliases = make(map[string]string, len(aliases))
+		}
+		for alias, tags := range aliases {
+			c.aliases[alias] = tags
 		}
 	}
-
-	return fmt.Sprintf("z.%s()%s", zodType, validateStr)
 }
 
-func (c *Converter) getType(t reflect.Type, indent int) string {
-	if t.Kind() == reflect.Ptr {
-		inner := t.Elem()
-		return c.getType(inner, indent)
+// WithZeroTimes sets the sentinel times that required time.Time fields reject,
+// eg. 1970-01-01 database defaults, replacing the defaults: Go's zero time and
+// the unix epoch that zod coerces empty values to for dates, Go's zero time for
+// ISOStringDatetime and 0 for UnixNumber. With UnixNumber the sentinels are
+// compared as unix timestamps in seconds. Without times, required time.Time
+// fields accept any time.
+func WithZeroTimes(times ...time.Time) Opt {
+	return func(c *Converter) {
+		c.zeroTimes = append([]time.Time{}, times...)
 	}
+}
 
-	// TODO: handle types for custom types
+// TagSet selects the go-validator tags that are translated, see WithTagSet.
+type TagSet int
+
+const (
+	// BaselineTags are the tags translated by default. Outputs are kept stable for
+	// them, so that users pinned to older go-validator versions are unaffected.
+	BaselineTags TagSet = iota
+	// RecentTags adds the tags of recent go-validator releases that older
+	// versions of zen did not translate: credit_card, luhn_checksum,
+	// mongodb_connection_string and spicedb.
+	RecentTags
+)
 
-	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
-		return c.getTypeSliceAndArray(t, indent)
+// WithTagSet sets the go-validator tags that are translated. Tags outside the
+// set panic as unknown validations. The default is BaselineTags.
+func WithTagSet(set TagSet) Opt {
+	return func(c *Converter) {
+		c.tagSet = set
 	}
+}
 
-	if t.Kind() == reflect.Map {
-		return c.getTypeMap(t, indent)
+// WithValidatorTranslation registers the translation of an app-specific
+// validation tag, the analog of registering its validation function with
+// go-validator's RegisterValidation. fn is given the tag, its parameter, the
+// type of the validated value, the field and the struct holding it, and returns
+// the checks to append to the schema of the values with the tag.
+// Registering a tag that zen translates overrides the built-in translation.
+func WithValidatorTranslation(tag string, fn TranslationFn) Opt {
+	return func(c *Converter) {
+		if c.translations == nil {
+			c.translations = make(map[string]TranslationFn)
+		}
+		c.translations[tag] = fn
 	}
+}
 
-	if t.Kind() == reflect.Struct {
+// TranslationStage sets where the checks of a validation registered with
+// WithValidatorTranslation are placed in the schema of the validated value,
+// see WithTranslationStage.
+type TranslationStage int
+
+const (
+	// RefineStage places the checks after the built-in checks, eg. .min() or
+	// .regex(), along with the built-in refines in the order of the
+	// validations. It is the default, as refines cannot be followed by checks.
+	RefineStage TranslationStage = iota
+	// CheckStage places the checks along with the built-in checks in the order
+	// of the validations, before any refine. The checks must be methods of the
+	// schema, eg. .startsWith("SKU-"), rather than refines or transforms.
+	CheckStage
+	// TransformStage places the checks after all the others, eg. for
+	// .transform(), whose output the other checks are not meant for.
+	TransformStage
+)
+
+// WithTranslationStage sets where the checks of a validation registered with
+// WithValidatorTranslation are placed in the schema, so that the schema stays
+// valid regardless of the order of the validations in the struct tag. The
+// default is RefineStage.
+func WithTranslationStage(tag string, stage TranslationStage) Opt {
+	return func(c *Converter) {
+		if c.translationStages == nil {
+			c.translationStages = make(map[string]TranslationStage)
+		}
+		c.translationStages[tag] = stage
+	}
+}
+
+// WithObjectValidatorTranslation registers the translation of an app-specific
+// cross-field validation tag, whose checks are appended to the schema of the
+// struct holding the field with the tag rather than to the schema of the field,
+// eg. `.refine((val) => val.confirm === val.password, { path: ["confirm"] })`.
+// fn is given the same Validation as with WithValidatorTranslation, along with
+// the JSON name of the field and the name of the schema of the struct. The
+// checks of embedded structs apply to the embedding structs, while derived
+// schemas, see WithDerivedSchemas, leave them out.
+func WithObjectValidatorTranslation(tag string, fn TranslationFn) Opt {
+	return func(c *Converter) {
+		if c.objectTranslations == nil {
+			c.objectTranslations = make(map[string]TranslationFn)
+		}
+		c.objectTranslations[tag] = fn
+	}
+}
+
+// WithValidatorSemantics makes schemas follow the go-validator release with the
+// given version, eg. "v10.30.5", where releases disagree: whether required
+// pointers may point to zero values, and the regexes of hexadecimal, hexcolor,
+// url_encoded, e164 and uuid. Use the version of go-validator in the go.mod of
+// the service validating the structs. The default follows v10.10.0 to v10.28.0.
+func WithValidatorSemantics(version string) Opt {
+	return func(c *Converter) {
+		c.validatorVersion = parseValidatorVersion(version)
+	}
+}
+
+// WithSharedRegexes emits the regexes of validations such as uuid or alpha once,
+// as constants exported by ExportRegexes, instead of repeating them in every
+// schema. module is the import path of the regexes relative to the schemas,
+// eg. "./regexes", imported at the top of Export.
+func WithSharedRegexes(module string) Opt {
+	return func(c *Converter) {
+		c.regexModule = module
+	}
+}
+
+// WithSharedRefinements defines the functions of refinements repeated across
+// schemas, such as the size checks of maps and the Luhn checksum, once at the
+// top of Export and calls them instead of inlining them in every refine.
+func WithSharedRefinements() Opt {
+	return func(c *Converter) {
+		c.sharedRefinements = true
+	}
+}
+
+// WithInputOutputSchemas converts every struct into two schemas, suffixed
+// Input and Output, eg. UserInputSchema and UserOutputSchema, so that request
+// payloads and responses can be validated differently from the same struct.
+// Fields tagged `zen:"readonly"`, such as IDs and timestamps set by the server,
+// are left out of the Input schemas, while fields tagged `zen:"writeonly"`,
+// such as passwords, are left out of the Output schemas. Fields tagged
+// `json:"-"` are left out of both, as they are never encoded.
+func WithInputOutputSchemas() Opt {
+	return func(c *Converter) {
+		c.inputOutput = true
+	}
+}
+
+// WithCodeLists validates iso3166_1 and iso4217 codes with a z.enum of the
+// actual codes, as go-validator does, instead of a regex matching their format.
+// iso3166_2 codes are always matched by format, as there are thousands of them.
+func WithCodeLists() Opt {
+	return func(c *Converter) {
+		c.codeLists = true
+	}
+}
+
+// WithAudit checks that the explicit TS type of every field, see
+// WithExplicitTypes, agrees with the type derived from its schema, including
+// the optionality and nullability of map values and slice elements.
+// Disagreements panic with an *AuditError, see TryAddType. The audit is meant to
+// be enabled in tests.
+func WithAudit() Opt {
+	return func(c *Converter) {
+		c.audit = true
+	}
+}
+
+// WithMaxAnyFields fails conversion once more than n fields, counted across all
+// converted types, fall back to z.any(), ie. interfaces without a custom type or
+// a zen tag, by panicking with an *AnyFieldsError, see TryAddType. This keeps a
+// growing codebase from silently losing type safety.
+func WithMaxAnyFields(n int) Opt {
+	return func(c *Converter) {
+		c.maxAnyFields = n
+		c.limitAnyFields = true
+	}
+}
+
+// AnyFieldsError is raised with WithMaxAnyFields when too many fields fall back
+// to z.any(). Fields holds the qualified names of all of them, eg.
+// "example.com/pkg.User.Meta".
+type AnyFieldsError struct {
+	Max    int
+	Fields []string
+}
+
+func (e *AnyFieldsError) Error() string {
+	return fmt.Sprintf("%d fields fall back to z.any(), more than %d: %s",
+		len(e.Fields), e.Max, strings.Join(e.Fields, ", "))
+}
+
+// NameCollisionError is raised when two distinct struct types map to the same
+// schema name, typically because they share a name across packages.
+type NameCollisionError struct {
+	Name   string
+	First  reflect.Type
+	Second reflect.Type
+}
+
+func (e *NameCollisionError) Error() string {
+	return fmt.Sprintf("type name collision for %s: %s and %s",
+		e.Name, describeNamed(e.First, e.Name), describeNamed(e.Second, e.Name))
+}
+
+// describeNamed describes the type a schema name is registered under, naming
+// the declarations registered under marker types, eg. "event union Events".
+func describeNamed(t reflect.Type, name string) string {
+	switch t {
+	case reflect.TypeOf(eventUnion("")):
+		return "event union " + name
+	case reflect.TypeOf(signatureAlias("")):
+		return "signature alias " + name
+	case reflect.TypeOf(topicGroup("")):
+		return "topic " + name
+	}
+
+	return qualifiedTypeName(t)
+}
+
+// AddType converts a struct type to corresponding zod schema. AddType can be called
+// multiple times, followed by Export to get the corresonding zod schemas.
+// AddType panics if the type cannot be converted, see TryAddType for a variant
+// returning an error instead.
+//
+// Options given to AddType, eg. `c.AddType(User{}, WithPrefix("Admin"))`,
+// apply to the structs converted by the call only, so that one export can
+// contain schemas generated with different settings. Structs already converted
+// under the same name are reused as is. A prefix is added to the names after
+// the converter's own, eg. AdminUserSchema, keeping them distinct from the
+// schemas of the same structs converted without it. Exports, such as
+// OpenAPISchemas, follow the options each struct was converted with.
+func (c *Converter) AddType(input interface{}, opts ...Opt) {
+	t := reflect.TypeOf(input)
+
+	if t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+
+	if len(opts) > 0 {
+		defer c.withOptions(opts)()
+	}
+	if c.docsErr != nil {
+		panic(c.docsErr)
+	}
+
+	if c.inputOutput {
+		for _, variant := range []string{"Input", "Output"} {
+			c.variant = variant
+			c.addStruct(t)
+		}
+		c.variant = ""
+		return
+	}
+
+	c.addStruct(t)
+}
+
+// withOptions applies options to the settings of the converter until the
+// returned function is called, which restores the previous settings. The
+// schemas and other state of the converter are kept. A prefix is added to the
+// names of the schemas instead, see AddType.
+func (c *Converter) withOptions(opts []Opt) func() {
+	saved := c.settings
+	// Options add to these maps and slices in place.
+	c.opaque = maps.Clone(c.opaque)
+	c.docs = maps.Clone(c.docs)
+	c.unions = maps.Clone(c.unions)
+	c.aliases = maps.Clone(c.aliases)
+	c.translations = maps.Clone(c.translations)
+	c.translationStages = maps.Clone(c.translationStages)
+	c.objectTranslations = maps.Clone(c.objectTranslations)
+	c.fieldOverrides = slices.Clip(c.fieldOverrides)
+	c.skipFields = slices.Clip(c.skipFields)
+	c.excludes = slices.Clip(c.excludes)
+	c.targets = slices.Clip(c.targets)
+	c.audiences = slices.Clip(c.audiences)
+	c.derivations = slices.Clip(c.derivations)
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.prefix != saved.prefix {
+		c.namePrefix = saved.namePrefix + c.prefix
+		c.prefix = saved.prefix
+	}
+
+	return func() {
+		c.settings = saved
+	}
+}
+
+// convertedAs returns a copy of the converter with the settings a schema name
+// was converted with, so that exports follow the options given to AddType.
+func (c *Converter) convertedAs(name string) *Converter {
+	conv := *c
+	if s, ok := c.nameSettings[name]; ok {
+		conv.settings = s
+	}
+
+	return &conv
+}
+
+// scratch returns a copy of the converter with the settings a schema name was
+// converted with, see convertedAs, whose conversions leave the converter
+// unchanged.
+func (c *Converter) scratch(name string) *Converter {
+	conv := c.convertedAs(name)
+	conv.outputs, conv.names, conv.nameSettings = maps.Clone(c.outputs), maps.Clone(c.names), maps.Clone(c.nameSettings)
+	conv.shapes, conv.shapeless, conv.typeDecls = maps.Clone(c.shapes), maps.Clone(c.shapeless), maps.Clone(c.typeDecls)
+	conv.refinements, conv.sharedRegexes, conv.structChecks = maps.Clone(c.refinements), maps.Clone(c.sharedRegexes), maps.Clone(c.structChecks)
+	conv.intersections, conv.recursiveCollections = maps.Clone(c.intersections), maps.Clone(c.recursiveCollections)
+	conv.stack, conv.inlined, conv.anyFields = slices.Clip(c.stack), slices.Clip(c.inlined), slices.Clip(c.anyFields)
+	conv.deferred, conv.collections, conv.procedures = slices.Clip(c.deferred), slices.Clip(c.collections), slices.Clip(c.procedures)
+	conv.report = nil
+
+	return conv
+}
+
+// TypeName returns the name of the TS type of a struct, including the prefix,
+// eg. "User" for the UserSchema schema.
+func (c *Converter) TypeName(input interface{}) string {
+	t := reflect.TypeOf(input)
+	if t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+
+	return c.prefix + c.structName(t)
+}
+
+// Intersect declares that the schema of a struct is intersected with the
+// schemas of other structs, eg. `.and(AuditFieldsSchema)` for
+// `c.Intersect(User{}, AuditFields{})`, to model mixins that are not embedded
+// in Go. The other structs are converted along with the struct, and structs
+// embedding it are intersected as well. Intersect panics if the struct has
+// already been converted.
+func (c *Converter) Intersect(input interface{}, others ...interface{}) {
+	t := reflect.TypeOf(input)
+	if t.Kind() != reflect.Struct {
+		panic("input must be a struct")
+	}
+	if _, ok := c.outputs[c.structName(t)]; ok {
+		panic(fmt.Sprintf("%s has already been converted", c.structName(t)))
+	}
+
+	if c.intersections == nil {
+		c.intersections = make(map[reflect.Type][]reflect.Type)
+	}
+	for _, other := range others {
+		o := reflect.TypeOf(other)
+		if o.Kind() != reflect.Struct {
+			panic("intersected types must be structs")
+		}
+		c.intersections[t] = append(c.intersections[t], o)
+	}
+}
+
+// addStruct converts a struct type to its schema, see AddType.
+func (c *Converter) addStruct(t reflect.Type) {
+	name := c.structName(t)
+	c.checkCollision(name, t)
+	if _, ok := c.outputs[name]; ok {
+		return
+	}
+
+	data := c.convertStructTopLevel(t)
+	order := c.structs
+	c.outputs[name] = entry{order, data}
+	c.structs = order + 1
+
+	c.convertDeferred()
+}
+
+// convertDeferred converts the structs referenced lazily beyond the depth set
+// with WithLazyDepth, each starting from depth zero.
+func (c *Converter) convertDeferred() {
+	for len(c.deferred) > 0 {
+		t := c.deferred[0]
+		c.deferred = c.deferred[1:]
+		if _, ok := c.outputs[c.structName(t)]; !ok {
+			c.addSchema(c.structName(t), c.convertStructTopLevel(t))
+		}
+	}
+}
+
+// TryAddType is like AddType, but returns an error instead of panicking when
+// the type cannot be converted. Name collisions are returned as a
+// *NameCollisionError. The schemas and names of a failed call are discarded.
+func (c *Converter) TryAddType(input interface{}, opts ...Opt) (err error) {
+	stack := len(c.stack)
+	anyFields := len(c.anyFields)
+	structs, outputs, names, nameSettings := c.structs, maps.Clone(c.outputs), maps.Clone(c.names), maps.Clone(c.nameSettings)
+	shapes, shapeless, typeDecls := maps.Clone(c.shapes), maps.Clone(c.shapeless), maps.Clone(c.typeDecls)
+	defer func() {
+		if r := recover(); r != nil {
+			c.structs, c.outputs, c.names, c.nameSettings = structs, outputs, names, nameSettings
+			c.shapes, c.shapeless, c.typeDecls = shapes, shapeless, typeDecls
+			c.stack = c.stack[:stack]
+			c.anyFields = c.anyFields[:anyFields]
+			c.field = ""
+			c.parent = nil
+			c.structField = reflect.StructField{}
+			c.variant = ""
+			c.depth = 0
+			c.deferred = nil
+			c.collections = nil
+			if e, ok := r.(error); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("%v", r)
+			}
+		}
+	}()
+
+	c.AddType(input, opts...)
+
+	return nil
+}
+
+// Convert returns zod schema corresponding to a struct type. Its a shorthand for
+// call to AddType followed by Export. So calling Convert after other calls to
+// AddType/Convert/ConvertSlice, returns schemas from those previous calls as well.
+// Calling AddType followed by Export might be more appropriate in such scenarios.
+func (c *Converter) Convert(input interface{}) string {
+	c.AddType(input)
+
+	return c.Export()
+}
+
+// ConvertSlice returns zod schemas corresponding to multiple struct types passed
+// in the argument. Similar to Convert, calling ConvertSlice after other calls to
+// AddType/Convert/ConvertSlice, returns schemas from those previous calls as well.
+// Calling AddType followed by Export might be more appropriate in such scenarios.
+func (c *Converter) ConvertSlice(inputs []interface{}) string {
+	for _, input := range inputs {
+		c.AddType(input)
+	}
+
+	return c.Export()
+}
+
+// StructToZodSchema returns zod schema corresponding to a struct type.
+func StructToZodSchema(input interface{}) string {
+	c := Converter{
+		outputs: make(map[string]entry),
+	}
+
+	return c.Convert(input)
+}
+
+// StructToZodSchemaWithPrefix returns zod schema corresponding to a struct type.
+// The prefix is added to the generated schema and type names.
+func StructToZodSchemaWithPrefix(prefix string, input interface{}) string {
+	c := Converter{
+		settings: settings{prefix: prefix},
+		outputs:  make(map[string]entry),
+	}
+
+	return c.Convert(input)
+}
+
+var typeMapping = map[reflect.Kind]string{
+	reflect.Bool:      "boolean",
+	reflect.Int:       "number",
+	reflect.Int8:      "number",
+	reflect.Int16:     "number",
+	reflect.Int32:     "number",
+	reflect.Int64:     "number",
+	reflect.Uint:      "number",
+	reflect.Uint8:     "number",
+	reflect.Uint16:    "number",
+	reflect.Uint32:    "number",
+	reflect.Uint64:    "number",
+	reflect.Uintptr:   "number",
+	reflect.Float32:   "number",
+	reflect.Float64:   "number",
+	reflect.String:    "string",
+	reflect.Interface: "any",
+}
+
+type entry struct {
+	order int
+	data  string
+}
+
+type byOrder []entry
+
+func (a byOrder) Len() int           { return len(a) }
+func (a byOrder) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byOrder) Less(i, j int) bool { return a[i].order < a[j].order }
+
+type CustomFn func(c *Converter, t reflect.Type, validate string, indent int) string
+
+// FieldOverrideFn returns the schema for a field of the given struct type, or
+// false to keep the generated schema.
+type FieldOverrideFn func(structType reflect.Type, field reflect.StructField) (string, bool)
+
+type meta struct {
+	name    string
+	selfRef bool
+}
+
+type Converter struct {
+	settings
+
+	structs              int
+	outputs              map[string]entry
+	stack                []meta
+	names                map[string]reflect.Type
+	nameSettings         map[string]settings
+	shapes               map[string]bool
+	inlined              []string
+	typeDecls            map[string]string
+	depth                int
+	deferred             []reflect.Type
+	refinements          map[string]bool
+	sharedRegexes        map[string]sharedRegex
+	intersections        map[reflect.Type][]reflect.Type
+	structChecks         map[string][]string
+	parent               reflect.Type
+	structField          reflect.StructField
+	optionalFields       bool
+	anyFields            []string
+	report               *report
+	procedures           []procedure
+	shapeless            map[string]bool
+	collections          []collectionExpansion
+	recursiveCollections map[string]bool
+	unbranded            bool
+	field                string
+}
+
+// settings are the options of a converter, which options given to AddType
+// override for the structs converted by the call, see withOptions.
+type settings struct {
+	prefix       string
+	custom       map[string]CustomFn
+	customTS     map[string]CustomFn
+	ignores      []string
+	qualifyNames bool
+	nameTag      string
+	namePrefix   string
+	variant      string
+
+	explicitTypes        bool
+	omitEmptyZeroValues  bool
+	exportShapes         bool
+	requiredPresenceOnly bool
+	ignoredComments      bool
+	fieldOverrides       []FieldOverrideFn
+	skipFields           []func(field reflect.StructField) bool
+	alwaysLazy           bool
+	excludes             []string
+	opaque               map[reflect.Type]bool
+	targets              []targetPatterns
+	maxDepth             int
+	lazyDepth            int
+	chunkSize            int
+	timeFormat           TimeFormat
+	profile              func(ConversionStats)
+	int64Type            string
+	noIntChecks          bool
+	audit                bool
+	integerBounds        bool
+	complexAsObject      bool
+	coercePrimitives     bool
+	audiences            []string
+	sensitiveMode        SensitiveMode
+	unexportedMode       UnexportedMode
+	errorName            string
+	errorTypes           []reflect.Type
+	codeLists            bool
+	inputOutput          bool
+	derivations          []Derivation
+	describe             bool
+	docs                 map[string]string
+	docsErr              error
+	sharedRefinements    bool
+	regexModule          string
+	unions               map[reflect.Type][]reflect.Type
+	aliases              map[string]string
+	zeroTimes            []time.Time
+	tagSet               TagSet
+	translations         map[string]TranslationFn
+	translationStages    map[string]TranslationStage
+	objectTranslations   map[string]TranslationFn
+	validatorVersion     [3]int
+	maxAnyFields         int
+	limitAnyFields       bool
+	shapeSuffix          string
+	recursionStyle       RecursionStyle
+	brandedTypes         bool
+	namedCollections     bool
+}
+
+// sharedRegex is a regex shared with WithSharedRegexes, along with the module
+// it is imported from.
+type sharedRegex struct {
+	module string
+	regex  string
+}
+
+func (c *Converter) addSchema(name string, data string) {
+	// First check if the object already exists. If it does do not replace. This is needed for second order
+	_, ok := c.outputs[name]
+	if !ok {
+		order := c.structs
+		c.outputs[name] = entry{order, data}
+		c.structs = order + 1
+	}
+}
+
+// Export returns the zod schemas corresponding to all types that have been
+// converted so far.
+func (c *Converter) Export() string {
+	return c.export(nil)
+}
+
+// ExportFile writes the zod schemas converted so far, along with the import of
+// zod, to the named file, replacing it at once so that readers never see a
+// partial file.
+func (c *Converter) ExportFile(name string) error {
+	return c.ExportFileContext(context.Background(), name)
+}
+
+// ExportFileContext is like ExportFile, but leaves the file untouched and
+// returns the context error once ctx is done.
+func (c *Converter) ExportFileContext(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString("import { z } from \"zod\"\n\n" + c.Export())
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+// ExportTypes returns the zod schemas of the named types converted so far, eg.
+// "User", along with the schemas they reference, so that one converter can back
+// several smaller files. It panics on names that have not been converted.
+func (c *Converter) ExportTypes(names ...string) string {
+	return c.export(c.dependencies(names))
+}
+
+// DependenciesOf returns the sorted names of the types converted so far that
+// the schema of the named type references, directly or through other schemas.
+// It panics on names that have not been converted.
+func (c *Converter) DependenciesOf(name string) []string {
+	deps := make([]string, 0)
+	for dep := range c.dependencies([]string{name}) {
+		if dep != name {
+			deps = append(deps, dep)
+		}
+	}
+	sort.Strings(deps)
+
+	return deps
+}
+
+// dependencies returns the set of the named types and the types their schemas
+// reference transitively.
+func (c *Converter) dependencies(names []string) map[string]bool {
+	refs := c.schemaRefs()
+	selected := make(map[string]bool)
+	var visit func(name string)
+	visit = func(name string) {
+		if selected[name] {
+			return
+		}
+		selected[name] = true
+		for _, ref := range refs[name] {
+			visit(ref)
+		}
+	}
+	for _, name := range names {
+		if _, ok := c.outputs[name]; !ok {
+			panic(fmt.Sprintf("unknown type: %s", name))
+		}
+		visit(name)
+	}
+
+	return selected
+}
+
+// export returns the selected schemas, or all of them if selected is nil,
+// preceded by the import of the regexes and the refinement helpers they use.
+func (c *Converter) export(selected map[string]bool) string {
+	schemas := c.exportSchemas(selected)
+	names := c.sharedRegexNames(schemas)
+	if len(names) == 0 {
+		return schemas
+	}
+
+	modules := make(map[string][]string)
+	for _, name := range names {
+		module := c.sharedRegexes[name].module
+		modules[module] = append(modules[module], name)
+	}
+	sorted := make([]string, 0, len(modules))
+	for module := range modules {
+		sorted = append(sorted, module)
+	}
+	sort.Strings(sorted)
+
+	imports := strings.Builder{}
+	for _, module := range sorted {
+		imports.WriteString(fmt.Sprintf("import { %s } from %s\n", strings.Join(modules[module], ", "), strconv.Quote(module)))
+	}
+
+	return imports.String() + "\n" + schemas
+}
+
+// schemaRefs returns the names of the schemas referenced by each schema.
+func (c *Converter) schemaRefs() map[string][]string {
+	schemas := make(map[string]string, 2*len(c.outputs))
+	for name := range c.outputs {
+		schemas[schemaName(c.prefix, name)] = name
+		schemas[c.convertedAs(name).shapeName(name)] = name
+	}
+
+	refs := make(map[string][]string, len(c.outputs))
+	for name, ent := range c.outputs {
+		for ident := range identifiers(ent.data) {
+			if ref, ok := schemas[ident]; ok && ref != name {
+				refs[name] = append(refs[name], ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// identifiers returns the set of JS identifiers in the given code, including
+// those in string literals.
+func identifiers(code string) map[string]bool {
+	idents := make(map[string]bool)
+	for _, ident := range matchIdentifierToken.FindAllString(code, -1) {
+		idents[ident] = true
+	}
+
+	return idents
+}
+
+var matchIdentifierToken = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*`)
+
+// ExportRegexes returns the module of the regexes shared with
+// WithSharedRegexes by the schemas converted so far.
+func (c *Converter) ExportRegexes() string {
+	output := strings.Builder{}
+	for _, name := range c.sharedRegexNames(c.exportSchemas(nil)) {
+		output.WriteString(fmt.Sprintf("export const %s = /%s/\n", name, c.sharedRegexes[name].regex))
+	}
+
+	return output.String()
+}
+
+// sharedRegexNames returns the names of the shared regexes used by the given
+// schemas.
+func (c *Converter) sharedRegexNames(schemas string) []string {
+	idents := identifiers(schemas)
+	var names []string
+	for name := range c.sharedRegexes {
+		if idents[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// regexCheck returns the check of a validation matching the given regex, which
+// refers to a shared constant with WithSharedRegexes.
+func (c *Converter) regexCheck(tag, regex string) string {
+	if c.regexModule == "" {
+		return fmt.Sprintf(".regex(/%s/)", regex)
+	}
+
+	name := pascalCase(tag) + "Regex"
+	if c.sharedRegexes == nil {
+		c.sharedRegexes = make(map[string]sharedRegex)
+	}
+	if _, ok := c.sharedRegexes[name]; !ok {
+		c.sharedRegexes[name] = sharedRegex{c.regexModule, regex}
+	}
+	return fmt.Sprintf(".regex(%s)", name)
+}
+
+// exportSchemas returns the selected schemas, or all of them if selected is
+// nil, preceded by the refinement helpers they use, see WithSharedRefinements.
+func (c *Converter) exportSchemas(selected map[string]bool) string {
+	typeDecls := c.exportedTypeDecls()
+	var sorted []entry
+	for name, ent := range c.outputs {
+		if selected == nil || selected[name] {
+			if decl, ok := typeDecls[name]; ok {
+				ent.data = decl
+			}
+			sorted = append(sorted, ent)
+		}
+	}
+
+	sort.Sort(byOrder(sorted))
+
+	schemas := strings.Builder{}
+	for _, ent := range sorted {
+		schemas.WriteString(ent.data)
+		schemas.WriteString("\n\n")
+	}
+
+	idents := identifiers(schemas.String())
+	var helpers []string
+	for name := range c.refinements {
+		if idents[name] {
+			helpers = append(helpers, name)
+		}
+	}
+	if len(helpers) == 0 {
+		return schemas.String()
+	}
+	sort.Strings(helpers)
+
+	output := strings.Builder{}
+	for _, name := range helpers {
+		output.WriteString(fmt.Sprintf("const %s = %s\n", name, refinementHelpers[name]))
+	}
+	output.WriteString("\n")
+	output.WriteString(schemas.String())
+
+	return output.String()
+}
+
+// exportedTypeDecls returns the TS types exported instead of the schemas of
+// the structs with TypeTarget, leaving out those whose schemas are referenced
+// by the schemas of other structs, see WithTarget.
+func (c *Converter) exportedTypeDecls() map[string]string {
+	if len(c.typeDecls) == 0 {
+		return nil
+	}
+
+	var schemas []string
+	for name := range c.outputs {
+		if _, ok := c.typeDecls[name]; !ok {
+			schemas = append(schemas, name)
+		}
+	}
+	referenced := c.dependencies(schemas)
+
+	decls := make(map[string]string, len(c.typeDecls))
+	for name, decl := range c.typeDecls {
+		if !referenced[name] {
+			decls[name] = decl
+		}
+	}
+
+	return decls
+}
+
+func schemaName(prefix, name string) string {
+	return fmt.Sprintf("%s%sSchema", prefix, name)
+}
+
+// shapeName returns the name of the exported shape of a struct, see
+// WithShapeSuffix.
+func (c *Converter) shapeName(name string) string {
+	if c.shapeSuffix != "" {
+		return c.prefix + name + c.shapeSuffix
+	}
+
+	return fmt.Sprintf("%s%sSchemaShape", c.prefix, name)
+}
+
+// isSkipped checks whether a field is left out of the JSON encoding, ie. tagged
+// with `json:"-"`, or of the tag set with WithNameTag, or left out of the schema
+// with `zen:"skip"`, by WithSkipFields or by WithUnexportedFields.
+func (c *Converter) isSkipped(input reflect.StructField) bool {
+	if input.Tag.Get(c.nameTagKey()) == "-" || parseZenTag(input).skip {
+		return true
+	}
+	if c.unexportedMode == UnexportedSkip && isUnexported(input) {
+		return true
+	}
+
+	for _, skip := range c.skipFields {
+		if skip(input) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isUnexported checks whether encoding/json ignores a field as it is
+// unexported, ie. unexported fields other than embedded structs and pointers to
+// structs, whose exported fields are promoted.
+func isUnexported(field reflect.StructField) bool {
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return !field.IsExported() && (!field.Anonymous || t.Kind() != reflect.Struct)
+}
+
+// inlinesEmbedded checks whether the fields of an embedded struct are inlined
+// into the embedding struct, as the struct is unexported, see
+// WithUnexportedFields.
+func (c *Converter) inlinesEmbedded(t reflect.Type) bool {
+	return c.unexportedMode != UnexportedInclude && !token.IsExported(t.Name())
+}
+
+// isOmitted checks whether a field is left out of the schema, either as it is
+// skipped, redacted, see WithSensitiveFields, restricted to other audiences,
+// see WithAudience, or to the other direction, see WithInputOutputSchemas.
+func (c *Converter) isOmitted(input reflect.StructField) bool {
+	if c.isSkipped(input) {
+		return true
+	}
+
+	if tag := parseZenTag(input); tag.readonly && c.variant == "Input" || tag.writeonly && c.variant == "Output" {
+		return true
+	}
+
+	if isSensitive(input) && (c.sensitiveMode == SensitiveOmit || c.sensitiveMode == SensitiveNever && input.Anonymous) {
+		return true
+	}
+
+	only, ok := input.Tag.Lookup("zen-only")
+	if !ok {
+		return false
+	}
+
+	for _, audience := range strings.Split(only, ",") {
+		for _, a := range c.audiences {
+			if strings.TrimSpace(audience) == a {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isSensitive checks whether a field is tagged `sensitive:"true"`.
+func isSensitive(input reflect.StructField) bool {
+	sensitive, _ := strconv.ParseBool(input.Tag.Get("sensitive"))
+	return sensitive
+}
+
+// isNever checks whether a field is mapped to z.never(), see SensitiveNever.
+func (c *Converter) isNever(input reflect.StructField) bool {
+	return c.sensitiveMode == SensitiveNever && isSensitive(input) && !c.isOmitted(input)
+}
+
+// fieldName returns the JSON name of a field, which is "-" for fields tagged
+// with `json:"-,"`. Omitted fields should be checked with isOmitted.
+func fieldName(input reflect.StructField) string {
+	return taggedFieldName(input, "json")
+}
+
+// fieldName returns the name of a field, taken from the tag set with
+// WithNameTag, or else from the json tag.
+func (c *Converter) fieldName(input reflect.StructField) string {
+	return taggedFieldName(input, c.nameTagKey())
+}
+
+// nameTagKey returns the key of the struct tag naming fields, see WithNameTag.
+func (c *Converter) nameTagKey() string {
+	if c.nameTag == "" {
+		return "json"
+	}
+
+	return c.nameTag
+}
+
+// taggedFieldName returns the name of a field given by the struct tag with the
+// given key, eg. "json", unless overridden with `zen:"name=..."`.
+func taggedFieldName(input reflect.StructField, key string) string {
+	if tag := parseZenTag(input); tag.name != "" {
+		return tag.name
+	}
+
+	if tag := input.Tag.Get(key); tag != "" {
+		args := strings.Split(tag, ",")
+		if len(args[0]) > 0 {
+			return args[0]
+		}
+		// This is also valid:
+		// json:",omitempty"
+		// so in this case, args[0] will be empty, so fall through to using the
+		// raw field name.
+	}
+
+	// When Golang marshals a struct to JSON, and it doesn't have any JSON tags
+	// that give the fields names, it defaults to just using the field's name.
+	// Gin, Echo and gorilla/schema do the same when binding forms.
+	return input.Name
+}
+
+// fieldValidate returns the validate tag of a field, with the aliases set with
+// WithTagAliases expanded, up to structonly or nostructlevel, after which
+// go-validator ignores the remaining validations, including dives. Validations
+// registered with WithObjectValidatorTranslation are left out, as they are
+// translated on the struct holding the field, see objectChecks.
+func (c *Converter) fieldValidate(input reflect.StructField) string {
+	validate := c.expandAliases(input.Tag.Get("validate"))
+	parts := strings.Split(validate, ",")
+	for i, part := range parts {
+		if part = strings.TrimSpace(part); part == "structonly" || part == "nostructlevel" {
+			parts = parts[:i]
+			break
+		}
+	}
+
+	if len(c.objectTranslations) == 0 {
+		return strings.Join(parts, ",")
+	}
+
+	kept := make([]string, 0, len(parts))
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "dive" {
+			kept = append(kept, parts[i:]...)
+			break
+		}
+		if !c.isObjectTranslated(part) {
+			kept = append(kept, part)
+		}
+	}
+
+	return strings.Join(kept, ",")
+}
+
+// expandAliases replaces the aliases set with WithTagAliases by their tags. As
+// in go-validator, aliases are matched against whole validations and against
+// the alternatives of validations separated by |.
+func (c *Converter) expandAliases(validate string) string {
+	if len(c.aliases) == 0 {
+		return validate
+	}
+
+	parts := strings.Split(validate, ",")
+	for i, part := range parts {
+		if tags, ok := c.aliases[strings.TrimSpace(part)]; ok {
+			parts[i] = c.expandAliases(tags)
+			continue
+		}
+
+		alternatives := strings.Split(part, "|")
+		for j, alternative := range alternatives {
+			if tags, ok := c.aliases[strings.TrimSpace(alternative)]; ok {
+				alternatives[j] = c.expandAliases(tags)
+			}
+		}
+		parts[i] = strings.Join(alternatives, "|")
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// propertyKey returns a field name as an object key, quoted unless it is a
+// valid identifier, eg. the name "-" given by `json:"-,"` or names containing
+// dots, dashes, spaces or non-ASCII characters. The same key is used in
+// schemas and TS types.
+func propertyKey(name string) string {
+	if matchIdentifier.MatchString(name) {
+		return name
+	}
+
+	return strconv.Quote(name)
+}
+
+var matchIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// zenTag holds the options of a `zen:"..."` struct tag, which overrides the
+// inferred schema of a field. Options are separated by commas. The type option
+// takes the rest of the tag as its value, so it must come last, ie.
+// `zen:"optional,type=z.enum(['a', 'b'])"`.
+type zenTag struct {
+	skip      bool
+	optional  bool
+	nullable  bool
+	readonly  bool
+	writeonly bool
+	name      string
+	typ       string
+}
+
+func parseZenTag(field reflect.StructField) zenTag {
+	var tag zenTag
+
+	rest := field.Tag.Get("zen")
+	for rest != "" {
+		var part string
+		if strings.HasPrefix(rest, "type=") {
+			part, rest = rest, ""
+		} else {
+			part, rest, _ = strings.Cut(rest, ",")
+		}
+
+		switch {
+		case part == "skip":
+			tag.skip = true
+		case part == "optional":
+			tag.optional = true
+		case part == "nullable":
+			tag.nullable = true
+		case part == "readonly":
+			tag.readonly = true
+		case part == "writeonly":
+			tag.writeonly = true
+		case strings.HasPrefix(part, "name="):
+			tag.name = part[5:]
+		case strings.HasPrefix(part, "type="):
+			tag.typ = part[5:]
+		default:
+			panic(fmt.Sprintf("unknown zen tag option: %s", part))
+		}
+	}
+
+	return tag
+}
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Struct {
+		return getTypeNameWithGenerics(t.Name())
+	}
+	if t.Kind() == reflect.Ptr {
+		return typeName(t.Elem())
+	}
+	if t.Kind() == reflect.Slice {
+		return typeName(t.Elem())
+	}
+	if t.Kind() == reflect.Map {
+		return typeName(t.Elem())
+	}
+
+	return "UNKNOWN"
+}
+
+// structName returns the schema and type name of a named struct, qualified with
+// its package name when WithPackageQualifiedNames is set, suffixed with the
+// variant being converted with WithInputOutputSchemas and prefixed with the
+// prefix given to AddType.
+func (c *Converter) structName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := typeName(t) + c.variant
+	if c.qualifyNames && t.PkgPath() != "" {
+		return c.namePrefix + packageName(t.PkgPath()) + name
+	}
+
+	return c.namePrefix + name
+}
+
+// checkCollision records the struct type backing a schema name and panics with
+// a NameCollisionError if the name already belongs to a different type.
+func (c *Converter) checkCollision(name string, t reflect.Type) {
+	if c.names == nil {
+		c.names = make(map[string]reflect.Type)
+	}
+
+	if prev, ok := c.names[name]; ok {
+		if prev != t {
+			panic(&NameCollisionError{Name: name, First: prev, Second: t})
+		}
+		return
+	}
+	c.names[name] = t
+	if c.nameSettings == nil {
+		c.nameSettings = make(map[string]settings)
+	}
+	c.nameSettings[name] = c.settings
+}
+
+// packageName converts the last element of a package path into a PascalCase
+// identifier, ie. github.com/org/pkg_a becomes PkgA. Major version suffixes
+// (/v2, gopkg.in style .v3) are skipped.
+func packageName(pkgPath string) string {
+	elems := strings.Split(pkgPath, "/")
+	last := elems[len(elems)-1]
+	if len(elems) > 1 && matchMajorVersion.MatchString(last) {
+		last = elems[len(elems)-2]
+	}
+	last = matchGopkgVersion.ReplaceAllString(last, "")
+
+	return pascalCase(last)
+}
+
+// pascalCase joins the words of s, separated by any characters other than
+// letters and digits, capitalizing the first letter of each word.
+func pascalCase(s string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		r, size := utf8.DecodeRuneInString(part)
+		sb.WriteRune(unicode.ToUpper(r))
+		sb.WriteString(part[size:])
+	}
+
+	return sb.String()
+}
+
+var (
+	matchMajorVersion = regexp.MustCompile(`^v[0-9]+$`)
+	matchGopkgVersion = regexp.MustCompile(`\.v[0-9]+$`)
+)
+
+func qualifiedTypeName(t reflect.Type) string {
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+
+	return fmt.Sprintf("%s.%s", t.PkgPath(), t.Name())
+}
+
+// isExcluded checks whether a named struct matches one of the patterns given to
+// WithExcludeTypes, or is opaque, see WithOpaqueTypes.
+func (c *Converter) isExcluded(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.Name() == "" {
+		return false
+	}
+	if c.opaque[t] {
+		return true
+	}
+
+	return matchesTypePatterns(t, c.excludes)
+}
+
+// matchesTypePatterns checks whether a type matches one of the given patterns,
+// see WithExcludeTypes.
+func matchesTypePatterns(t reflect.Type, patterns []string) bool {
+	fullName := getFullName(t)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, path.Base(fullName)); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, fullName); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// elemType dereferences pointer types.
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func (c *Converter) convertStructTopLevel(t reflect.Type) string {
+	output := strings.Builder{}
+
+	name := c.structName(t)
+	c.checkCollision(name, t)
+	if c.profile != nil {
+		start := time.Now()
+		defer func() {
+			c.profile(ConversionStats{t, name, t.NumField(), time.Since(start)})
+		}()
+	}
+	c.stack = append(c.stack, meta{name, false})
+
+	shape := c.convertStructShape(t, 0)
+	fullName := c.prefix + name
+
+	// Intersections cannot be merged either, so they are propagated to
+	// embedding structs like the object checks, which they precede.
+	shape.checks = append(c.intersectionChecks(t), shape.checks...)
+
+	top := c.stack[len(c.stack)-1]
+	if c.audit && (c.alwaysLazy || top.selfRef || c.explicitTypes) {
+		c.auditStruct(t, shape.fields)
+	}
+
+	if c.target(t) == TypeTarget {
+		if c.typeDecls == nil {
+			c.typeDecls = make(map[string]string)
+		}
+		c.typeDecls[name] = fmt.Sprintf("export type %s = %s%s", fullName, c.getTypeStruct(t, 0), c.intersectionTypes(t))
+	}
+
+	if len(shape.checks) > 0 {
+		if c.structChecks == nil {
+			c.structChecks = make(map[string][]string)
+		}
+		c.structChecks[name] = shape.checks
+	}
+
+	if c.alwaysLazy {
+		if len(c.derivations) > 0 {
+			panic("derived schemas are not supported with WithAlwaysLazy")
+		}
+
+		output.WriteString(fmt.Sprintf(`export type %s = %s%s
+`, fullName, c.getTypeStruct(t, 0), c.intersectionTypes(t)))
+
+		output.WriteString(fmt.Sprintf(
+			`export const %s: z.ZodType<%s> = z.lazy(() => %s)`,
+			schemaName(c.prefix, name), fullName, c.object(shape)))
+	} else if top.selfRef && c.recursionStyle == InterfaceRecursion {
+		c.markShapeless(name)
+
+		output.WriteString(fmt.Sprintf("%s\n", c.interfaceDecl(t, fullName)))
+		output.WriteString(fmt.Sprintf(`export const %s: z.ZodType<%s> = %s`,
+			schemaName(c.prefix, name), fullName, c.object(shape)))
+
+		output.WriteString(c.derivedSchemas(t, fmt.Sprintf("z.object(%s)%s", shape.literal(), strings.Join(shape.merges, ""))))
+	} else if top.selfRef && c.recursionStyle == GetterRecursion {
+		c.markShapeless(name)
+		shape.fields = getterFields(shape.fields, shape.indent+1)
+
+		if c.explicitTypes {
+			output.WriteString(fmt.Sprintf(`export type %s = %s%s
+`, fullName, c.getTypeStruct(t, 0), c.intersectionTypes(t)))
+			output.WriteString(fmt.Sprintf(`export const %s: z.ZodType<%s> = %s`,
+				schemaName(c.prefix, name), fullName, c.object(shape)))
+		} else {
+			output.WriteString(fmt.Sprintf(`export const %s = %s
+`, schemaName(c.prefix, name), c.object(shape)))
+			output.WriteString(fmt.Sprintf(`export type %s = z.infer<typeof %s>`,
+				fullName, schemaName(c.prefix, name)))
+		}
+
+		output.WriteString(c.derivedSchemas(t, fmt.Sprintf("z.object(%s)%s", shape.literal(), strings.Join(shape.merges, ""))))
+	} else if top.selfRef || c.explicitTypes {
+		c.markShape(name)
+
+		output.WriteString(fmt.Sprintf(`export type %s = %s%s
+`, fullName, c.getTypeStruct(t, 0), c.intersectionTypes(t)))
+
+		// The shape is exported so that structs embedding this one can spread
+		// it, as z.ZodType does not support merge.
+		output.WriteString(fmt.Sprintf(`export const %s = %s
+`, c.shapeName(name), shape.literal()))
+
+		output.WriteString(fmt.Sprintf(
+			`export const %s: z.ZodType<%s> = z.object(%s)%s%s`,
+			schemaName(c.prefix, name), fullName, c.shapeName(name), strings.Join(shape.merges, ""),
+			strings.Join(shape.checks, "")))
+
+		// The schema is typed as z.ZodType, so derived schemas start from the
+		// shape.
+		output.WriteString(c.derivedSchemas(t, fmt.Sprintf("z.object(%s)%s", c.shapeName(name), strings.Join(shape.merges, ""))))
+	} else if c.exportShapes || len(shape.checks) > 0 {
+		// Refined schemas cannot be merged, so structs with object checks
+		// export their shape for embedding structs to spread.
+		c.markShape(name)
+
+		output.WriteString(fmt.Sprintf(`export const %s = %s
+`, c.shapeName(name), shape.literal()))
+
+		output.WriteString(fmt.Sprintf(
+			`export const %s = z.object(%s)%s%s
+`,
+			schemaName(c.prefix, name), c.shapeName(name), strings.Join(shape.merges, ""),
+			strings.Join(shape.checks, "")))
+
+		output.WriteString(fmt.Sprintf(`export type %s = z.infer<typeof %s>`,
+			fullName, schemaName(c.prefix, name)))
+	} else {
+		output.WriteString(fmt.Sprintf(
+			`export const %s = %s
+`,
+			schemaName(c.prefix, name), c.object(shape)))
+
+		output.WriteString(fmt.Sprintf(`export type %s = z.infer<typeof %s>`,
+			fullName, schemaName(c.prefix, name)))
+	}
+
+	if !c.alwaysLazy && !top.selfRef && !c.explicitTypes {
+		base := schemaName(c.prefix, name)
+		if len(shape.checks) > 0 {
+			base = fmt.Sprintf("z.object(%s)%s", c.shapeName(name), strings.Join(shape.merges, ""))
+		}
+		output.WriteString(c.derivedSchemas(t, base))
+	}
+
+	c.stack = c.stack[:len(c.stack)-1]
+
+	return output.String()
+}
+
+// intersectionChecks returns the intersections of a struct declared with
+// Intersect, converting the intersected structs.
+func (c *Converter) intersectionChecks(t reflect.Type) []string {
+	var ands []string
+	for _, other := range c.intersections[t] {
+		ands = append(ands, fmt.Sprintf(".and(%s)", c.convertNamedStruct(other, false)))
+	}
+
+	return ands
+}
+
+// intersectionTypes returns the TS intersection with the types of the structs
+// intersected with Intersect, eg. " & AuditFields".
+func (c *Converter) intersectionTypes(t reflect.Type) string {
+	output := strings.Builder{}
+	for _, other := range c.intersections[t] {
+		output.WriteString(" & " + c.prefix + c.structName(other))
+	}
+
+	return output.String()
+}
+
+// markShapeless records that the named self-referential struct is emitted
+// without a shape, so that structs embedding it inline its fields, see
+// WithRecursionStyle.
+func (c *Converter) markShapeless(name string) {
+	if c.shapeless == nil {
+		c.shapeless = make(map[string]bool)
+	}
+	c.shapeless[name] = true
+}
+
+// interfaceDecl returns the TS interface of a struct, extending the types of
+// its embedded structs and of the structs it is intersected with, see
+// InterfaceRecursion.
+func (c *Converter) interfaceDecl(t reflect.Type, fullName string) string {
+	fields, embedded := c.getTypeStructFields(t, 0)
+	for _, other := range c.intersections[t] {
+		embedded = append(embedded, c.prefix+c.structName(other))
+	}
+
+	var extends []string
+	for _, e := range embedded {
+		// custom types are typed as unknown, which adds nothing
+		if e != "unknown" {
+			extends = append(extends, e)
+		}
+	}
+
+	output := strings.Builder{}
+	output.WriteString("export interface " + fullName)
+	if len(extends) > 0 {
+		output.WriteString(" extends " + strings.Join(extends, ", "))
+	}
+	output.WriteString(" {\n")
+	output.WriteString(strings.Join(fields, ""))
+	output.WriteString("}")
+
+	return output.String()
+}
+
+// getterFields turns the fields of a self-referential struct referencing
+// schemas into getters, which reference the schemas directly as they are only
+// called once the schemas are defined, see GetterRecursion.
+func getterFields(fields []string, indent int) []string {
+	getters := make([]string, 0, len(fields))
+	for _, field := range fields {
+		key, value := splitEntry(field)
+		references := false
+		for ident := range identifiers(value) {
+			if strings.HasSuffix(ident, "Schema") {
+				references = true
+			}
+		}
+		if !references {
+			getters = append(getters, field)
+			continue
+		}
+
+		value = matchLazySchema.ReplaceAllString(value, "$1")
+		getters = append(getters, fmt.Sprintf("%sget %s() {\n%sreturn %s\n%s},\n",
+			indentation(indent), key, indentation(indent+1),
+			strings.ReplaceAll(value, "\n", "\n"+indentation(1)), indentation(indent)))
+	}
+
+	return getters
+}
+
+var matchLazySchema = regexp.MustCompile(`z\.lazy\(\(\) => ([A-Za-z_$][A-Za-z0-9_$]*)\)`)
+
+// markShape records that the shape of the named struct is exported, so that
+// structs embedding it spread the shape instead of merging the schema.
+func (c *Converter) markShape(name string) {
+	if c.shapes == nil {
+		c.shapes = make(map[string]bool)
+	}
+	c.shapes[name] = true
+}
+
+func (c *Converter) convertStruct(input reflect.Type, indent int) string {
+	return c.object(c.convertStructShape(input, indent))
+}
+
+// structShape holds the converted fields of a struct, see convertStructFields.
+type structShape struct {
+	spreads string
+	fields  []string
+	merges  []string
+	checks  []string
+	indent  int
+}
+
+// literal returns the object literal holding the zod schemas of the fields.
+func (s structShape) literal() string {
+	return fmt.Sprintf("{\n%s%s%s}", s.spreads, strings.Join(s.fields, ""), indentation(s.indent))
+}
+
+// object returns the z.object schema of a struct shape. Shapes with more fields
+// than the chunk size set with WithObjectChunkSize are split into objects
+// merged together. The object checks come last, see
+// WithObjectValidatorTranslation.
+func (c *Converter) object(s structShape) string {
+	if c.chunkSize <= 0 || len(s.fields) <= c.chunkSize {
+		return fmt.Sprintf("z.object(%s)%s%s", s.literal(), strings.Join(s.merges, ""), strings.Join(s.checks, ""))
+	}
+
+	output := strings.Builder{}
+	for i := 0; i < len(s.fields); i += c.chunkSize {
+		end := i + c.chunkSize
+		if end > len(s.fields) {
+			end = len(s.fields)
+		}
+
+		chunk := structShape{fields: s.fields[i:end], indent: s.indent}
+		if i == 0 {
+			chunk.spreads = s.spreads
+			output.WriteString(fmt.Sprintf("z.object(%s)", chunk.literal()))
+		} else {
+			output.WriteString(fmt.Sprintf(".merge(z.object(%s))", chunk.literal()))
+		}
+	}
+	output.WriteString(strings.Join(s.merges, ""))
+	output.WriteString(strings.Join(s.checks, ""))
+
+	return output.String()
+}
+
+// convertStructShape converts the fields of a struct, along with the merge
+// calls for embedded structs.
+func (c *Converter) convertStructShape(input reflect.Type, indent int) structShape {
+	c.depth++
+	defer func() { c.depth-- }()
+	if c.maxDepth > 0 && c.depth > c.maxDepth {
+		panic(fmt.Sprintf("max depth %d exceeded converting %s", c.maxDepth, input))
+	}
+
+	shape := c.convertStructFields(input, indent+1)
+	shape.indent = indent
+
+	return shape
+}
+
+// convertStructFields converts the fields of a struct. The schemas of embedded
+// structs are returned separately as spreads, so that they come before the
+// struct's own fields, and the fields of inlined embedded structs come first as
+// well. Promoted fields hidden by other fields in JSON are left out, see
+// jsonFields.
+func (c *Converter) convertStructFields(input reflect.Type, indent int) structShape {
+	spreads := strings.Builder{}
+	var output, inlined, checks []string
+	merges := []string{}
+
+	optionalFields := c.optionalFields
+	c.optionalFields = false
+	hidden := c.hiddenFields(input)
+
+	fields := input.NumField()
+	for i := 0; i < fields; i++ {
+		field := input.Field(i)
+
+		if c.unexportedMode == UnexportedError && isUnexported(field) && !c.isSkipped(field) {
+			panic(fmt.Sprintf("unexported field %s.%s", input, field.Name))
+		}
+
+		if t, pointer, ok := c.embeddedStruct(field); ok {
+			embedded := c.convertEmbedded(t, indent, optionalFields || pointer, hidden[i])
+			spreads.WriteString(embedded.spreads)
+			inlined = append(inlined, embedded.fields...)
+			merges = append(merges, embedded.merges...)
+			checks = append(checks, embedded.checks...)
+			continue
+		}
+
+		if field.Anonymous && c.isExcluded(elemType(field.Type)) {
+			continue
+		}
+
+		if !c.isOmitted(field) {
+			checks = append(checks, c.objectChecks(input, field)...)
+		}
+
+		if c.isNever(field) {
+			output = append(output, fmt.Sprintf("%s%s: z.never().optional(),\n", indentation(indent), propertyKey(c.fieldName(field))))
+			continue
+		}
+
+		if snippet, ok := c.overrideField(input, field); ok {
+			output = append(output, fmt.Sprintf("%s%s: %s,\n", indentation(indent), propertyKey(c.fieldName(field)), snippet))
+			continue
+		}
+
+		optional, nullable := c.fieldPresence(field)
+		optional = optional || optionalFields
+
+		// fields of anonymous structs are named after the field holding them
+		parent, parentType, structField := c.field, c.parent, c.structField
+		if input.Name() != "" {
+			c.field = qualifiedTypeName(input) + "." + field.Name
+		} else {
+			c.field = parent + "." + field.Name
+		}
+		c.parent, c.structField = input, field
+		c.reportConstraints(c.fieldValidate(field))
+		if c.unexportedMode == UnexportedInclude && isUnexported(field) {
+			c.warn("unexported field is converted, while encoding/json ignores it")
+		}
+
+		// encoding/json only flattens embedded structs, other embedded types, eg.
+		// interfaces or named primitives, are fields named after their type.
+		line, shouldMerge := c.convertField(field, indent, optional, nullable, field.Anonymous && field.Type.Kind() == reflect.Struct && !c.isTagged(field))
+		c.field, c.parent, c.structField = parent, parentType, structField
+
+		if shouldMerge {
+			merges = append(merges, line)
+		} else if line != "" {
+			output = append(output, line)
+		}
+	}
+
+	return structShape{spreads: spreads.String(), fields: append(inlined, output...), merges: merges, checks: checks}
+}
+
+// overrideField returns the schema of a field as given by the first field
+// override accepting it, see WithFieldOverride.
+func (c *Converter) overrideField(structType reflect.Type, field reflect.StructField) (string, bool) {
+	if c.isOmitted(field) {
+		return "", false
+	}
+
+	for _, override := range c.fieldOverrides {
+		if snippet, ok := override(structType, field); ok {
+			return snippet, true
+		}
+	}
+
+	return "", false
+}
+
+// convertEmbedded converts an embedded named struct. The shape of regular
+// structs is spread into the embedding struct, without the hidden fields, see
+// jsonFields. Structs with an exported shape, ie. self-referential structs
+// which are typed as z.ZodType, have their exported shape spread instead, so
+// that the shape of the embedding struct is complete. Structs that are still
+// being converted, ie. embedded from within their own cycle, have no shape to
+// spread yet, so their fields are inlined, as are those of self-referential
+// structs emitted without a shape, see WithRecursionStyle. Custom types are
+// merged. The object checks of the embedded struct, see
+// WithObjectValidatorTranslation, apply to the embedding struct as well.
+//
+// encoding/json promotes the fields of embedded pointers only when they are not
+// nil, so these fields are inlined as optional, along with those of the
+// structs embedded in turn.
+func (c *Converter) convertEmbedded(t reflect.Type, indent int, optional bool, hidden []string) structShape {
+	if c.isExcluded(t) {
+		return structShape{}
+	}
+
+	if optional || c.inlinesEmbedded(t) {
+		return c.inlineEmbedded(t, indent, optional, hidden)
+	}
+
+	name := c.structName(t)
+	c.checkCollision(name, t)
+
+	// With WithAlwaysLazy there are no shapes, so the fields are always inlined.
+	// The embedded struct is still converted to be exported on its own.
+	cycle := c.markCycle(name)
+	if !cycle && c.alwaysLazy {
+		c.ConvertType(t, "", indent)
+	}
+
+	if cycle || c.alwaysLazy {
+		return c.inlineEmbedded(t, indent, false, hidden)
+	}
+
+	// Embedded structs are never deferred, as their shape or schema is needed
+	// in place.
+	if custom, ok := c.handleCustomType(t, "", indent); ok {
+		return structShape{merges: []string{fmt.Sprintf(".merge(%s)", custom)}}
+	}
+
+	schema := c.convertNamedStruct(t, false)
+	if c.shapeless[name] {
+		return c.inlineEmbedded(t, indent, false, hidden)
+	}
+	if c.shapes[name] {
+		spread := c.shapeName(name)
+		if len(hidden) > 0 {
+			spread = fmt.Sprintf("z.object(%s).omit(%s).shape", spread, omitMask(hidden))
+		}
+		return structShape{
+			spreads: fmt.Sprintf("%s...%s,\n", indentation(indent), spread),
+			checks:  c.structChecks[name],
+		}
+	}
+
+	if len(hidden) > 0 {
+		schema += fmt.Sprintf(".omit(%s)", omitMask(hidden))
+	}
+	return structShape{spreads: fmt.Sprintf("%s...%s.shape,\n", indentation(indent), schema)}
+}
+
+// inlineEmbedded returns the fields of an embedded struct to inline into the
+// embedding struct, without the hidden ones, see convertEmbedded. Structs
+// embedded from within their own fields are left out.
+func (c *Converter) inlineEmbedded(t reflect.Type, indent int, optional bool, hidden []string) structShape {
+	name := c.structName(t)
+	for _, inlined := range c.inlined {
+		if inlined == name {
+			return structShape{}
+		}
+	}
+
+	c.inlined = append(c.inlined, name)
+	defer func() { c.inlined = c.inlined[:len(c.inlined)-1] }()
+
+	c.optionalFields = optional
+	shape := c.convertStructFields(t, indent)
+	shape.fields = withoutFields(shape.fields, hidden)
+	shape.checks = append(c.intersectionChecks(t), shape.checks...)
+
+	return shape
+}
+
+// embeddedStruct returns the named struct embedded by a field, directly or
+// through a pointer, whose fields are promoted into the embedding struct.
+// Embedded pointers to custom types and embedded structs named by their tag
+// are converted as fields instead.
+func (c *Converter) embeddedStruct(field reflect.StructField) (reflect.Type, bool, bool) {
+	t, pointer := field.Type, false
+	if t.Kind() == reflect.Ptr {
+		t, pointer = t.Elem(), true
+		if _, ok := c.custom[getFullName(t)]; ok {
+			return nil, false, false
+		}
+	}
+
+	if !field.Anonymous || t.Kind() != reflect.Struct || t.Name() == "" || c.isOmitted(field) || c.isTagged(field) {
+		return nil, false, false
+	}
+
+	return t, pointer, true
+}
+
+func (c *Converter) getTypeStruct(input reflect.Type, indent int) string {
+	fields, embedded := c.getTypeStructFields(input, indent)
+
+	output := strings.Builder{}
+
+	output.WriteString(`{
+`)
+	output.WriteString(strings.Join(fields, ""))
+	output.WriteString(indentation(indent))
+	output.WriteString(`}`)
+
+	for _, e := range embedded {
+		output.WriteString(" & ")
+		output.WriteString(e)
+	}
+
+	return output.String()
+}
+
+// getTypeStructFields returns the TS types of the fields of a struct, along
+// with the types of its embedded structs.
+func (c *Converter) getTypeStructFields(input reflect.Type, indent int) ([]string, []string) {
+	var output, embedded []string
+
+	optionalFields := c.optionalFields
+	c.optionalFields = false
+	hidden := c.hiddenFields(input)
+
+	fields := input.NumField()
+	for i := 0; i < fields; i++ {
+		field := input.Field(i)
+
+		if t, pointer, ok := c.embeddedStruct(field); ok {
+			if c.isExcluded(t) {
+				continue
+			}
+			if optionalFields || pointer || c.inlinesEmbedded(t) {
+				output, embedded = c.getTypeInlined(t, indent, optionalFields || pointer, hidden[i], output, embedded)
+				continue
+			}
+			if len(hidden[i]) > 0 {
+				embedded = append(embedded, fmt.Sprintf("Omit<%s, %s>", c.getType(t, "", indent), omitKeys(hidden[i])))
+				continue
+			}
+			embedded = append(embedded, c.getType(t, "", indent))
+			continue
+		}
+
+		if field.Anonymous && c.isExcluded(elemType(field.Type)) {
+			continue
+		}
+
+		if c.isNever(field) {
+			output = append(output, fmt.Sprintf("%s%s?: never,\n", indentation(indent+1), propertyKey(c.fieldName(field))))
+			continue
+		}
+
+		// The TS type of an overridden field cannot be derived from its schema.
+		if _, ok := c.overrideField(input, field); ok {
+			output = append(output, fmt.Sprintf("%s%s?: unknown,\n", indentation(indent+1), propertyKey(c.fieldName(field))))
+			continue
+		}
+
+		optional, nullable := c.fieldPresence(field)
+		optional = optional || optionalFields
+
+		if line := c.getTypeField(field, indent+1, optional, nullable); line != "" {
+			output = append(output, line)
+		}
+	}
+
+	return output, embedded
+}
+
+// getTypeInlined appends the TS types of the fields of an embedded struct, and
+// of the structs it embeds, to those of the embedding struct, see
+// inlineEmbedded.
+func (c *Converter) getTypeInlined(t reflect.Type, indent int, optional bool, hidden, output, embedded []string) ([]string, []string) {
+	name := c.structName(t)
+	for _, inlined := range c.inlined {
+		if inlined == name {
+			return output, embedded
+		}
+	}
+
+	c.inlined = append(c.inlined, name)
+	defer func() { c.inlined = c.inlined[:len(c.inlined)-1] }()
+
+	c.optionalFields = optional
+	fields, nested := c.getTypeStructFields(t, indent)
+	output = append(output, withoutFields(fields, hidden)...)
+	embedded = append(embedded, nested...)
+	for _, other := range c.intersections[t] {
+		embedded = append(embedded, c.prefix+c.structName(other))
+	}
+
+	return output, embedded
+}
+
+// Gets the full type name (package+type), stripping out generic type arguments.
+// The arguments may be generic themselves, ie. the name of
+// Option[pkg.List[*pkg.Node]] is pkg.Option, so that custom types match
+// wrappers nested in one another.
+func getFullName(t reflect.Type) string {
+	typename, _, _ := strings.Cut(t.Name(), "[")
+
+	return fmt.Sprintf("%s.%s", t.PkgPath(), typename)
+}
+
+func (c *Converter) handleCustomType(t reflect.Type, validate string, indent int) (string, bool) {
+	fullName := getFullName(t)
+
+	custom, ok := c.custom[fullName]
+	if ok {
+		return custom(c, t, validate, indent), true
+	}
+
+	return "", false
+}
+
+// ConvertType should be called from custom converter functions.
+func (c *Converter) ConvertType(t reflect.Type, validate string, indent int) string {
+	if t.Kind() == reflect.Ptr {
+		return c.ConvertType(t.Elem(), c.pointeeValidate(t, validate), indent)
+	}
+
+	if c.opaque[t] {
+		c.warn("opaque type %s is converted to z.unknown()", t)
+		return "z.unknown()"
+	}
+
+	// Custom types should be handled before maps/slices, as we might have
+	// custom types that are maps/slices.
+	if custom, ok := c.handleCustomType(t, validate, indent); ok {
+		return custom
+	}
+
+	if c.isMappedError(t) {
+		return c.convertError()
+	}
+
+	if impls, ok := c.unions[t]; ok {
+		return c.convertUnion(impls, indent)
+	}
+
+	if c.isNamedCollection(t) {
+		if name, ok := c.namedCollection(t, validate, indent); ok {
+			if c.expandingCollection(name) {
+				return fmt.Sprintf("z.lazy(() => %s)", schemaName(c.prefix, name))
+			}
+			return schemaName(c.prefix, name)
+		}
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return c.convertSliceAndArray(t, validate, indent)
+	}
+
+	if t.Kind() == reflect.Map {
+		return c.convertMap(t, validate, indent)
+	}
+
+	if t.Kind() == reflect.Struct {
 		name := typeName(t)
 
-		if t.Name() == "" {
-			// Handle fields with non-defined types - these are inline.
-			return c.getTypeStruct(t, indent)
-		} else if t.Name() == "Time" {
-			return "date"
-		} else {
-			return c.prefix + name
+		if name == "" {
+			// Handle fields with non-defined types - these are inline.
+			return c.convertStruct(t, indent) + c.translateValidations(t, validate)
+		} else if name == "Time" {
+			return c.convertTime(validate)
+		} else if c.isExcluded(t) {
+			c.warn("excluded type %s is converted to z.unknown()", t)
+			return "z.unknown()" + c.translateValidations(t, validate)
+		} else {
+			return c.convertNamedStruct(t, true) + c.translateValidations(t, validate)
+		}
+	}
+
+	if c.isBranded(t) {
+		return c.convertBranded(t, validate, indent)
+	}
+
+	if c.isInt64(t) {
+		return c.convertInt64(t, validate)
+	}
+
+	if c.complexAsObject && (t.Kind() == reflect.Complex64 || t.Kind() == reflect.Complex128) {
+		return fmt.Sprintf("z.object({\n%sre: z.number(),\n%sim: z.number(),\n%s})",
+			indentation(indent+1), indentation(indent+1), indentation(indent))
+	}
+
+	// boolean, number, string, any
+	zodType, ok := typeMapping[t.Kind()]
+	if !ok {
+		panic(fmt.Sprint("cannot handle: ", t.Kind()))
+	}
+
+	if zodType == "any" {
+		c.countAnyField()
+	}
+
+	var validateStr string
+	if validate != "" {
+		switch zodType {
+		case "string":
+			validateStr = c.validateString(t, validate)
+			if strings.Contains(validateStr, ".enum(") {
+				return enumSchema(validateStr)
+			}
+		case "number":
+			validateStr = c.validateNumber(t, validate)
+		case "boolean":
+			validateStr = c.validateBoolean(t, validate)
+		case "any":
+			validateStr = validateAny(validate)
+		}
+	}
+
+	return fmt.Sprintf("%s%s%s", c.primitiveSchema(zodType), c.intChecks(t), validateStr)
+}
+
+// pointeeValidate returns the validations of a pointer that apply to the value
+// it points to, ie. without the omit marker and, for pointers to values that
+// go-validator checks for presence by the pointer, without required.
+func (c *Converter) pointeeValidate(t reflect.Type, validate string) string {
+	if marker, rest, _ := strings.Cut(validate, ","); isOmitMarker(strings.TrimSpace(marker)) {
+		validate = rest
+	}
+	// go-validator requires pointers to be non-nil since v9.2.1, before that
+	// the value pointed to had to be non-zero
+	if k := t.Elem().Kind(); k != reflect.Ptr && k != reflect.Slice && k != reflect.Map && k != reflect.Interface &&
+		!c.validatorBefore(9, 2, 1) {
+		validate = dropRequired(validate)
+	}
+
+	return validate
+}
+
+// primitiveSchema returns the schema of a primitive type, eg. z.number(), which
+// is coerced from strings with WithCoercePrimitives.
+func (c *Converter) primitiveSchema(zodType string) string {
+	// z.coerce.boolean() maps every non-empty string to true, including "false"
+	if c.coercePrimitives && zodType == "boolean" {
+		return `z.preprocess((val) => (val === "true" ? true : val === "false" ? false : val), z.boolean())`
+	}
+	if c.coercePrimitives && (zodType == "number" || zodType == "bigint") {
+		return fmt.Sprintf("z.coerce.%s()", zodType)
+	}
+
+	return fmt.Sprintf("z.%s()", zodType)
+}
+
+// countAnyField records the field being converted as falling back to z.any(),
+// see WithMaxAnyFields.
+func (c *Converter) countAnyField() {
+	c.reportAnyField()
+	if !c.limitAnyFields {
+		return
+	}
+
+	for _, field := range c.anyFields {
+		if field == c.field {
+			return
+		}
+	}
+	c.anyFields = append(c.anyFields, c.field)
+	if len(c.anyFields) > c.maxAnyFields {
+		panic(&AnyFieldsError{c.maxAnyFields, append([]string(nil), c.anyFields...)})
+	}
+}
+
+// intChecks returns the .int() check for integer kinds, unless disabled with
+// WithoutIntChecks, followed by .nonnegative() for unsigned kinds, which
+// encoding/json fails to unmarshal negative values into.
+func (c *Converter) intChecks(t reflect.Type) string {
+	intCall := ".int()"
+	if c.noIntChecks {
+		intCall = ""
+	}
+
+	if bounds, ok := integerBounds[t.Kind()]; ok && c.integerBounds {
+		return fmt.Sprintf("%s.gte(%d).lte(%d)", intCall, bounds[0], bounds[1])
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return intCall
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return intCall + ".nonnegative()"
+	}
+
+	return ""
+}
+
+// integerBounds holds the ranges of the integer kinds that fit in a JS number,
+// see WithIntegerBounds.
+var integerBounds = map[reflect.Kind][2]int64{
+	reflect.Int8:   {math.MinInt8, math.MaxInt8},
+	reflect.Int16:  {math.MinInt16, math.MaxInt16},
+	reflect.Int32:  {math.MinInt32, math.MaxInt32},
+	reflect.Uint8:  {0, math.MaxUint8},
+	reflect.Uint16: {0, math.MaxUint16},
+	reflect.Uint32: {0, math.MaxUint32},
+}
+
+// convertNamedStruct converts a named struct to its own schema and returns a
+// reference to it. Structs within a cycle, or deeper than the lazy depth when
+// deferrable, are referenced with z.lazy.
+func (c *Converter) convertNamedStruct(t reflect.Type, deferrable bool) string {
+	name := c.structName(t)
+	c.checkCollision(name, t)
+	if c.markCycle(name) {
+		return fmt.Sprintf("z.lazy(() => %s)", schemaName(c.prefix, name))
+	}
+
+	if _, ok := c.outputs[name]; !ok && deferrable && c.lazyDepth > 0 && c.depth >= c.lazyDepth {
+		c.deferred = append(c.deferred, t)
+		return fmt.Sprintf("z.lazy(() => %s)", schemaName(c.prefix, name))
+	}
+
+	c.addSchema(name, c.convertStructTopLevel(t))
+	return schemaName(c.prefix, name)
+}
+
+// isMappedError checks whether t is the error interface, mapped with
+// WithErrorSchema.
+func (c *Converter) isMappedError(t reflect.Type) bool {
+	return c.errorName != "" && t == errorType
+}
+
+// convertError returns the schema of errors mapped with WithErrorSchema,
+// exporting the union of the error structs the first time. The union is
+// registered under the error type, so that a struct of the same name is
+// reported as a NameCollisionError.
+func (c *Converter) convertError() string {
+	if len(c.errorTypes) == 0 {
+		return "z.string()"
+	}
+
+	c.checkCollision(c.errorName, errorType)
+	if _, ok := c.outputs[c.errorName]; ok {
+		return schemaName(c.prefix, c.errorName)
+	}
+
+	schemas := make([]string, 0, len(c.errorTypes))
+	types := make([]string, 0, len(c.errorTypes))
+	for _, t := range c.errorTypes {
+		schemas = append(schemas, c.ConvertType(t, "", 0))
+		types = append(types, c.getType(t, "", 0))
+	}
+
+	schema := schemas[0]
+	if len(schemas) > 1 {
+		schema = fmt.Sprintf("z.union([%s])", strings.Join(schemas, ", "))
+	}
+
+	if c.explicitTypes || c.alwaysLazy {
+		c.addSchema(c.errorName, fmt.Sprintf("export type %s%s = %s\nexport const %s: z.ZodType<%s%s> = %s",
+			c.prefix, c.errorName, strings.Join(types, " | "),
+			schemaName(c.prefix, c.errorName), c.prefix, c.errorName, schema))
+	} else {
+		c.addSchema(c.errorName, fmt.Sprintf("export const %s = %s\nexport type %s%s = z.infer<typeof %s>",
+			schemaName(c.prefix, c.errorName), schema,
+			c.prefix, c.errorName, schemaName(c.prefix, c.errorName)))
+	}
+
+	return schemaName(c.prefix, c.errorName)
+}
+
+// convertUnion returns the schema of an interface mapped with
+// WithInterfaceUnion.
+func (c *Converter) convertUnion(impls []reflect.Type, indent int) string {
+	schemas := make([]string, 0, len(impls))
+	for _, t := range impls {
+		schemas = append(schemas, c.ConvertType(t, "", indent))
+	}
+	if len(schemas) == 1 {
+		return schemas[0]
+	}
+
+	return fmt.Sprintf("z.union([%s])", strings.Join(schemas, ", "))
+}
+
+// getTypeUnion returns the TS type of an interface mapped with
+// WithInterfaceUnion.
+func (c *Converter) getTypeUnion(impls []reflect.Type, indent int) string {
+	types := make([]string, 0, len(impls))
+	for _, t := range impls {
+		types = append(types, c.getType(t, "", indent))
+	}
+
+	return strings.Join(types, " | ")
+}
+
+// convertTime returns the schema of a time.Time field in the format set with
+// WithTimeFormat.
+func (c *Converter) convertTime(validate string) string {
+	switch c.timeFormat {
+	case ISOStringDatetime:
+		var validateStr string
+		if validate == "required" {
+			validateStr = c.zeroTimeRefine(func(zero time.Time) string {
+				return fmt.Sprintf("new Date(val).getTime() !== new Date('%s').getTime()", zero.UTC().Format(time.RFC3339Nano))
+			}, time.Time{})
+		}
+		return "z.string().datetime({ offset: true })" + validateStr
+	case UnixNumber:
+		var validateStr string
+		if validate == "required" {
+			validateStr = c.zeroTimeRefine(func(zero time.Time) string {
+				return fmt.Sprintf("val !== %d", zero.Unix())
+			}, time.Unix(0, 0))
+		}
+		return c.primitiveSchema("number") + validateStr
+	}
+
+	var validateStr string
+	// We compare with both the zero value from go and the zero value that zod coerces to
+	if validate == "required" {
+		validateStr = c.zeroTimeRefine(func(zero time.Time) string {
+			if zero.Equal(time.Unix(0, 0)) {
+				return "val.getTime() !== new Date(0).getTime()"
+			}
+			return fmt.Sprintf("val.getTime() !== new Date('%s').getTime()", zero.UTC().Format(time.RFC3339Nano))
+		}, time.Time{}, time.Unix(0, 0))
+	}
+	// timestamps are to be coerced to date by zod. JSON.parse only serializes to string
+	return "z.coerce.date()" + validateStr
+}
+
+// zeroTimeRefine returns the refine rejecting the zero times of a required
+// time.Time field, checked with check: the times given to WithZeroTimes, or
+// else the defaults of the time format. Times equal to an earlier one are
+// skipped. It returns "" when there are no zero times.
+func (c *Converter) zeroTimeRefine(check func(time.Time) string, defaults ...time.Time) string {
+	zeros := defaults
+	if c.zeroTimes != nil {
+		zeros = c.zeroTimes
+	}
+
+	var checked []time.Time
+	var checks []string
+	for _, zero := range zeros {
+		if !containsTime(checked, zero) {
+			checks = append(checks, check(zero))
+			checked = append(checked, zero)
+		}
+	}
+	if len(checks) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(".refine((val) => %s, 'Invalid date')", strings.Join(checks, " && "))
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ConvertTSType returns the TS type of a Go type, the counterpart of
+// ConvertType for the handlers of WithCustomTSTypes.
+func (c *Converter) ConvertTSType(t reflect.Type, validate string, indent int) string {
+	return c.getType(t, validate, indent)
+}
+
+func (c *Converter) getType(t reflect.Type, validate string, indent int) string {
+	if t.Kind() == reflect.Ptr {
+		inner := t.Elem()
+		return c.getType(inner, validate, indent)
+	}
+
+	if c.opaque[t] {
+		return "unknown"
+	}
+
+	// The TS type of a custom type cannot be derived from its schema.
+	if _, ok := c.custom[getFullName(t)]; ok {
+		if fn, ok := c.customTS[getFullName(t)]; ok {
+			return fn(c, t, validate, indent)
+		}
+		return "unknown"
+	}
+
+	if c.isMappedError(t) {
+		if len(c.errorTypes) == 0 {
+			return "string"
+		}
+		c.convertError()
+		return c.prefix + c.errorName
+	}
+
+	if impls, ok := c.unions[t]; ok {
+		return c.getTypeUnion(impls, indent)
+	}
+
+	if c.isNamedCollection(t) {
+		if name, ok := c.namedCollection(t, validate, indent); ok {
+			return c.prefix + name
+		}
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		return c.getTypeSliceAndArray(t, validate, indent)
+	}
+
+	if t.Kind() == reflect.Map {
+		return c.getTypeMap(t, validate, indent)
+	}
+
+	if t.Kind() == reflect.Struct {
+		if t.Name() == "" {
+			// Handle fields with non-defined types - these are inline.
+			return c.getTypeStruct(t, indent)
+		} else if t.Name() == "Time" {
+			switch c.timeFormat {
+			case ISOStringDatetime:
+				return "string"
+			case UnixNumber:
+				return "number"
+			}
+			return "Date"
+		} else if c.isExcluded(t) {
+			return "unknown"
+		} else {
+			return c.prefix + c.structName(t)
+		}
+	}
+
+	if c.isBranded(t) {
+		return c.prefix + c.addBranded(t)
+	}
+
+	if c.isInt64(t) {
+		return c.int64Type
+	}
+
+	if c.complexAsObject && (t.Kind() == reflect.Complex64 || t.Kind() == reflect.Complex128) {
+		return fmt.Sprintf("{\n%sre: number,\n%sim: number,\n%s}",
+			indentation(indent+1), indentation(indent+1), indentation(indent))
+	}
+
+	zodType, ok := typeMapping[t.Kind()]
+	if !ok {
+		panic(fmt.Sprint("cannot handle: ", t.Kind()))
+	}
+	return zodType
+}
+
+// isInt64 checks whether t is an int64 or uint64 mapped with WithInt64AsBigInt
+// or WithInt64AsString.
+func (c *Converter) isInt64(t reflect.Type) bool {
+	return c.int64Type != "" && (t.Kind() == reflect.Int64 || t.Kind() == reflect.Uint64)
+}
+
+// convertInt64 returns the schema of an int64 or uint64 as a bigint or as a
+// string of digits. Numeric validations are checked on the BigInt value.
+func (c *Converter) convertInt64(t reflect.Type, validate string) string {
+	var schema, value string
+	if c.int64Type == "bigint" {
+		schema, value = c.primitiveSchema("bigint"), "val"
+		if t.Kind() == reflect.Uint64 {
+			schema += ".nonnegative()"
+		}
+	} else {
+		schema, value = `z.string().regex(/^-?\d+$/)`, "BigInt(val)"
+		if t.Kind() == reflect.Uint64 {
+			schema = `z.string().regex(/^\d+$/)`
+		}
+	}
+
+	var validateStr strings.Builder
+	for _, part := range strings.Split(validate, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" || isOmitMarker(part) || c.checkIsIgnored(part) {
+			continue
+		}
+		if part == "required" {
+			if !c.requiredPresenceOnly {
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => %s !== 0n)", value))
+			}
+			continue
+		}
+		if check, ok := c.translateValidation(t, part); ok {
+			validateStr.WriteString(check)
+			continue
+		}
+
+		valName, valValue, ok := strings.Cut(part, "=")
+		if !ok || valValue == "" {
+			panic(fmt.Sprintf("unknown validation: %s", part))
+		}
+
+		switch valName {
+		case "gt":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s > %sn)", value, valValue))
+		case "gte", "min":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s >= %sn)", value, valValue))
+		case "lt":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s < %sn)", value, valValue))
+		case "lte", "max":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s <= %sn)", value, valValue))
+		case "eq", "len":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s === %sn)", value, valValue))
+		case "ne":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => %s !== %sn)", value, valValue))
+		case "oneof":
+			vals := numberOneofValues(part, valValue, true)
+			for i := range vals {
+				vals[i] += "n"
+			}
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => [%s].includes(%s))", strings.Join(vals, ", "), value))
+		default:
+			panic(fmt.Sprintf("unknown validation: %s", part))
+		}
+	}
+
+	// The validations of strings are piped, so that they don't run for values
+	// that BigInt cannot parse.
+	if c.int64Type == "string" && validateStr.Len() > 0 {
+		return fmt.Sprintf("%s.pipe(z.string()%s)", schema, validateStr.String())
+	}
+
+	return schema + validateStr.String()
+}
+
+func (c *Converter) convertField(f reflect.StructField, indent int, optional, nullable, anonymous bool) (string, bool) {
+	// fields tagged `json:"-"` are not exported to JSON so don't export zod types
+	if c.isOmitted(f) {
+		return "", false
+	}
+	name := propertyKey(c.fieldName(f))
+
+	// because nullability is processed before custom types, this makes sure
+	// the custom type has control over nullability.
+	fullName := getFullName(f.Type)
+	_, isCustom := c.custom[fullName]
+
+	optionalCall := ""
+	if optional {
+		optionalCall = ".optional()"
+	}
+	nullableCall := ""
+	if nullable && !isCustom {
+		nullableCall = ".nullable()"
+	}
+
+	var t string
+	if typ := parseZenTag(f).typ; typ != "" {
+		t = typ
+	} else if values, _, ok := parseZenValues(f); ok {
+		t = c.convertRecord(elemType(f.Type), c.fieldValidate(f), indent, values)
+	} else {
+		t = c.ConvertType(f.Type, c.fieldValidate(f), indent)
+	}
+	if zero, ok := c.omittedZeroValue(f); ok && !isCustom {
+		t = fmt.Sprintf("%s.or(z.literal(%s))", t, zero)
+	}
+	if !anonymous {
+		return fmt.Sprintf(
+			"%s%s: %s%s%s%s,%s\n",
+			indentation(indent),
+			name,
+			t,
+			optionalCall,
+			nullableCall+c.defaultCall(f),
+			c.describeField(c.parent, f),
+			c.ignoredComment(f)), false
+	} else {
+		return fmt.Sprintf(".merge(%s)", t), true
+	}
+}
+
+func (c *Converter) getTypeField(f reflect.StructField, indent int, optional, nullable bool) string {
+	// fields tagged `json:"-"` are not exported to JSON so don't export types
+	if c.isOmitted(f) {
+		return ""
+	}
+	name := propertyKey(c.fieldName(f))
+
+	// because nullability is processed before custom types, this makes sure
+	// the custom type has control over nullability.
+	fullName := getFullName(f.Type)
+	_, isCustom := c.custom[fullName]
+
+	optionalCallPre := ""
+	optionalCallUndef := ""
+	if optional {
+		optionalCallPre = "?"
+		optionalCallUndef = " | undefined"
+	}
+	nullableCall := ""
+	if nullable && !isCustom {
+		nullableCall = " | null"
+	}
+
+	// The TS type of a zen tag type cannot be derived from its schema.
+	typ := "unknown"
+	if _, values, ok := parseZenValues(f); ok {
+		typ = c.getTypeRecord(elemType(f.Type), c.fieldValidate(f), indent, values)
+	} else if parseZenTag(f).typ == "" {
+		typ = c.getType(f.Type, c.fieldValidate(f), indent)
+	}
+
+	// z.infer makes keys accepting undefined optional, so unknown fields, and
+	// custom types accepting undefined, are typed as optional for schemas
+	// annotated with z.ZodType to type check.
+	if typ == "unknown" || isCustom && strings.HasSuffix(typ, " | undefined") {
+		optionalCallPre = "?"
+	}
+	// Fields with a default accept undefined as input, which z.ZodType expects
+	// to match the type as well.
+	if _, ok := f.Tag.Lookup("default"); ok {
+		optionalCallPre, optionalCallUndef = "?", " | undefined"
+	}
+
+	return fmt.Sprintf(
+		"%s%s%s: %s%s%s,\n",
+		indentation(indent),
+		name,
+		optionalCallPre,
+		typ,
+		nullableCall,
+		optionalCallUndef)
+}
+
+// omittedZeroValue returns the JS zero value of a string or number field whose
+// validations are skipped by go-validator for zero values, ie. tagged with
+// omitempty alongside other validations, when WithOmitEmptyZeroValues is set.
+func (c *Converter) omittedZeroValue(f reflect.StructField) (string, bool) {
+	if !c.omitEmptyZeroValues {
+		return "", false
+	}
+
+	var omitEmpty, validated bool
+	for _, part := range strings.Split(getValidateCurrent(c.fieldValidate(f)), ",") {
+		part = strings.TrimSpace(part)
+		if part == "omitempty" || part == "omitzero" {
+			omitEmpty = true
+		} else if part != "" && !c.checkIsIgnored(part) {
+			validated = true
+		}
+	}
+	if !omitEmpty || !validated {
+		return "", false
+	}
+
+	if c.isInt64(f.Type) {
+		if c.int64Type == "bigint" {
+			return "0n", true
+		}
+		return `"0"`, true
+	}
+
+	switch typeMapping[f.Type.Kind()] {
+	case "string":
+		return `""`, true
+	case "number":
+		return "0", true
+	}
+
+	return "", false
+}
+
+// defaultCall returns the .default() call of a string, number or boolean field
+// tagged with `default:"..."`, as used by envconfig style loaders. The default
+// of a field validated with oneof must be one of its values.
+func (c *Converter) defaultCall(f reflect.StructField) string {
+	value, ok := f.Tag.Lookup("default")
+	if !ok {
+		return ""
+	}
+
+	for _, part := range strings.Split(getValidateCurrent(c.fieldValidate(f)), ",") {
+		vals, ok := strings.CutPrefix(strings.TrimSpace(part), "oneof=")
+		if ok && !containsString(oneofValues(vals), value) {
+			panic(fmt.Sprintf("default %s of %s is not one of %s", value, f.Name, vals))
+		}
+	}
+
+	t := f.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var literal string
+	var err error
+	switch {
+	case c.isInt64(t):
+		literal, err = intLiteral(t, value)
+		if c.int64Type == "bigint" {
+			literal += "n"
+		} else {
+			literal = strconv.Quote(literal)
+		}
+	case typeMapping[t.Kind()] == "string":
+		literal = strconv.Quote(value)
+	case typeMapping[t.Kind()] == "boolean":
+		var b bool
+		b, err = strconv.ParseBool(value)
+		literal = strconv.FormatBool(b)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(value, 64)
+		if err == nil && (math.IsInf(n, 0) || math.IsNaN(n)) {
+			err = fmt.Errorf("%s is not finite", value)
+		}
+		literal = strconv.FormatFloat(n, 'g', -1, 64)
+	case typeMapping[t.Kind()] == "number":
+		literal, err = intLiteral(t, value)
+	default:
+		panic(fmt.Sprintf("cannot handle default of %s: %s", f.Name, f.Type))
+	}
+	if err != nil {
+		panic(fmt.Sprintf("invalid default of %s: %v", f.Name, err))
+	}
+
+	return fmt.Sprintf(".default(%s)", literal)
+}
+
+// intLiteral parses an integer of the given type in base 10.
+func intLiteral(t reflect.Type, value string) (string, error) {
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(value, 10, t.Bits())
+		return strconv.FormatUint(n, 10), err
+	}
+
+	n, err := strconv.ParseInt(value, 10, t.Bits())
+	return strconv.FormatInt(n, 10), err
+}
+
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if other == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Converter) convertSliceAndArray(t reflect.Type, validate string, indent int) string {
+	if t.Kind() == reflect.Array {
+		elemValidate := getValidateAfterDive(validate)
+		elem := c.ConvertType(t.Elem(), elemValidate, indent)
+		if c.isNullableElem(t.Elem(), elemValidate) {
+			elem += ".nullable()"
+		}
+		return fmt.Sprintf("%s.array().length(%d)%s", elem, t.Len(), c.translateValidations(t, validate))
+	}
+
+	var validateStr strings.Builder
+	validateCurrent := getValidateCurrent(validate)
+	if validateCurrent != "" {
+		parts := strings.Split(validateCurrent, ",")
+
+		// eq and ne should be at the end since they output a refine function
+		sort.SliceStable(parts, func(i, j int) bool {
+			if strings.HasPrefix(parts[i], "ne") {
+				return false
+			}
+			if strings.HasPrefix(parts[j], "ne") {
+				return true
+			}
+			return i < j
+		})
+
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if isOmitMarker(part) {
+			} else if part == "dive" {
+				break
+			} else if part == "required" {
+			} else if strings.HasPrefix(part, "min=") {
+				validateStr.WriteString(fmt.Sprintf(".min(%s)", part[4:]))
+			} else if strings.HasPrefix(part, "max=") {
+				validateStr.WriteString(fmt.Sprintf(".max(%s)", part[4:]))
+			} else if strings.HasPrefix(part, "len=") {
+				validateStr.WriteString(fmt.Sprintf(".length(%s)", part[4:]))
+			} else if strings.HasPrefix(part, "eq=") {
+				validateStr.WriteString(fmt.Sprintf(".length(%s)", part[3:]))
+			} else if strings.HasPrefix(part, "ne=") {
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => val.length !== %s)", part[3:]))
+			} else if strings.HasPrefix(part, "gt=") {
+				val, err := strconv.Atoi(part[3:])
+				if err != nil || val < 0 {
+					panic(fmt.Sprintf("invalid gt value: %s", part[3:]))
+				}
+				validateStr.WriteString(fmt.Sprintf(".min(%d)", val+1))
+			} else if strings.HasPrefix(part, "gte=") {
+				validateStr.WriteString(fmt.Sprintf(".min(%s)", part[4:]))
+			} else if strings.HasPrefix(part, "lt=") {
+				val, err := strconv.Atoi(part[3:])
+				if err != nil || val <= 0 {
+					panic(fmt.Sprintf("invalid lt value: %s", part[3:]))
+				}
+				validateStr.WriteString(fmt.Sprintf(".max(%d)", val-1))
+			} else if strings.HasPrefix(part, "lte=") {
+				validateStr.WriteString(fmt.Sprintf(".max(%s)", part[4:]))
+			} else if c.isTranslated(part) {
+				if c.translationStage(part) == CheckStage {
+					check, _ := c.translateValidation(t, part)
+					validateStr.WriteString(check)
+				}
+			} else {
+				panic(fmt.Sprintf("unknown validation: %s", part))
+			}
+		}
+	}
+
+	return fmt.Sprintf(
+		"%s.array()%s%s",
+		c.ConvertType(t.Elem(), getValidateAfterDive(validate), indent), validateStr.String(),
+		c.translateStages(t, validate, RefineStage, TransformStage))
+}
+
+func (c *Converter) getTypeSliceAndArray(t reflect.Type, validate string, indent int) string {
+	elemValidate := getValidateAfterDive(validate)
+	if t.Kind() == reflect.Array && c.isNullableElem(t.Elem(), elemValidate) {
+		return fmt.Sprintf("(%s | null)[]", c.getType(t.Elem(), elemValidate, indent))
+	}
+
+	if impls := c.unions[elemType(t.Elem())]; len(impls) > 1 {
+		return fmt.Sprintf("(%s)[]", c.getType(t.Elem(), elemValidate, indent))
+	}
+	if _, ok := c.customTS[getFullName(elemType(t.Elem()))]; ok {
+		if elem := c.getType(t.Elem(), elemValidate, indent); strings.Contains(elem, " | ") {
+			return fmt.Sprintf("(%s)[]", elem)
+		}
+	}
+
+	return fmt.Sprintf("%s[]", c.getType(t.Elem(), elemValidate, indent))
+}
+
+func (c *Converter) convertKeyType(t reflect.Type, validate string) string {
+	if t.Name() == "Time" {
+		// encoding/json encodes time keys with MarshalText, whatever the format.
+		if c.timeFormat == ISOStringDatetime || c.timeFormat == UnixNumber {
+			return "z.string().datetime({ offset: true })"
 		}
+		return "z.coerce.date()"
+	}
+
+	if custom, ok := c.handleCustomType(t, validate, 0); ok {
+		return custom
+	}
+
+	// Keys implementing encoding.TextMarshaler are encoded with MarshalText,
+	// unless they are strings.
+	if t.Kind() != reflect.String && t.Implements(textMarshalerType) {
+		return "z.string()"
 	}
 
+	// boolean, number, string, any
 	zodType, ok := typeMapping[t.Kind()]
-	if !ok {
-		panic(fmt.Sprint("cannot handle: ", t.Kind()))
+	if !ok || (zodType != "string" && zodType != "number") {
+		panic(fmt.Sprint("cannot handle key type: ", t.Kind()))
 	}
-	return zodType
+
+	var validateStr string
+	if validate != "" {
+		switch zodType {
+		case "string":
+			validateStr = c.validateString(t, validate)
+			if strings.Contains(validateStr, ".enum(") {
+				return enumSchema(validateStr)
+			}
+		case "number":
+			validateStr = c.validateNumber(t, validate)
+		}
+	}
+
+	if zodType == "string" {
+		return fmt.Sprintf("z.%s()%s", zodType, validateStr)
+	}
+
+	// https://pkg.go.dev/encoding/json#Marshal
+	// Map values encode as JSON objects. The map's key type must either be a string, an integer type, or implement encoding.TextMarshaler.
+	return fmt.Sprintf("z.coerce.%s()%s%s", zodType, c.intChecks(t), validateStr)
 }
 
-func (c *Converter) convertField(f reflect.StructField, indent int, optional, nullable, anonymous bool) (string, bool) {
-	name := fieldName(f)
+func (c *Converter) convertMap(t reflect.Type, validate string, indent int) string {
+	return c.convertRecord(t, validate, indent, "")
+}
 
-	// fields named `-` are not exported to JSON so don't export zod types
-	if name == "-" {
-		return "", false
+// convertRecord converts a map, using the given schema for its values if set,
+// see parseZenValues.
+func (c *Converter) convertRecord(t reflect.Type, validate string, indent int, values string) string {
+	var validateStr strings.Builder
+	if validate != "" {
+		parts := strings.Split(getValidateCurrent(validate), ",")
+		c.transformsLast(parts)
+
+		for _, part := range parts {
+			part = strings.TrimSpace(part)
+			if isOmitMarker(part) || part == "" {
+			} else if part == "required" {
+				validateStr.WriteString(c.mapSizeCheck(">", "0", "Empty map"))
+			} else if strings.HasPrefix(part, "min=") {
+				validateStr.WriteString(c.mapSizeCheck(">=", part[4:], "Map too small"))
+			} else if strings.HasPrefix(part, "max=") {
+				validateStr.WriteString(c.mapSizeCheck("<=", part[4:], "Map too large"))
+			} else if strings.HasPrefix(part, "len=") {
+				validateStr.WriteString(c.mapSizeCheck("===", part[4:], "Map wrong size"))
+			} else if strings.HasPrefix(part, "eq=") {
+				validateStr.WriteString(c.mapSizeCheck("===", part[3:], "Map wrong size"))
+			} else if strings.HasPrefix(part, "ne=") {
+				validateStr.WriteString(c.mapSizeCheck("!==", part[3:], "Map wrong size"))
+			} else if strings.HasPrefix(part, "gt=") {
+				validateStr.WriteString(c.mapSizeCheck(">", part[3:], "Map too small"))
+			} else if strings.HasPrefix(part, "gte=") {
+				validateStr.WriteString(c.mapSizeCheck(">=", part[4:], "Map too small"))
+			} else if strings.HasPrefix(part, "lt=") {
+				validateStr.WriteString(c.mapSizeCheck("<", part[3:], "Map too large"))
+			} else if strings.HasPrefix(part, "lte=") {
+				validateStr.WriteString(c.mapSizeCheck("<=", part[4:], "Map too large"))
+			} else if check, ok := c.translateValidation(t, part); ok {
+				validateStr.WriteString(check)
+			} else {
+				panic(fmt.Sprintf("unknown validation: %s", part))
+			}
+		}
 	}
 
-	// because nullability is processed before custom types, this makes sure
-	// the custom type has control over nullability.
-	fullName := getFullName(f.Type)
-	_, isCustom := c.custom[fullName]
+	if values == "" {
+		valueValidate := getValidateValues(validate)
+		values = c.ConvertType(t.Elem(), valueValidate, indent)
+		if c.isNullableElem(t.Elem(), valueValidate) {
+			values += ".nullable()"
+		}
+	}
 
-	optionalCall := ""
-	if optional {
-		optionalCall = ".optional()"
+	return fmt.Sprintf(`z.record(%s, %s)%s`,
+		c.convertKeyType(t.Key(), getValidateKeys(validate)),
+		values,
+		validateStr.String())
+}
+
+func (c *Converter) getTypeMap(t reflect.Type, validate string, indent int) string {
+	return c.getTypeRecord(t, validate, indent, "")
+}
+
+// getTypeRecord returns the TS type of a map, using the given type for its
+// values if set, see parseZenValues.
+func (c *Converter) getTypeRecord(t reflect.Type, validate string, indent int, values string) string {
+	if values == "" {
+		valueValidate := getValidateValues(validate)
+		values = c.getType(t.Elem(), valueValidate, indent)
+		if c.isNullableElem(t.Elem(), valueValidate) {
+			values += " | null"
+		}
 	}
-	nullableCall := ""
-	if nullable && !isCustom {
-		nullableCall = ".nullable()"
+
+	// Records keyed by an enum don't need to hold every key, which z.infer
+	// models with Partial.
+	if keys := c.getTypeEnumKeys(t.Key(), getValidateKeys(validate)); keys != "" {
+		return fmt.Sprintf(`Partial<Record<%s, %s>>`, keys, values)
 	}
 
-	t := c.ConvertType(f.Type, f.Tag.Get("validate"), indent)
-	if !anonymous {
-		return fmt.Sprintf(
-			"%s%s: %s%s%s,\n",
-			indentation(indent),
-			name,
-			t,
-			optionalCall,
-			nullableCall), false
-	} else {
-		return fmt.Sprintf(".merge(%s)", t), true
+	return fmt.Sprintf(`Record<%s, %s>`,
+		c.getTypeKey(t.Key(), getValidateKeys(validate), indent),
+		values)
+}
+
+// getTypeKey returns the TS type of a map key, see convertKeyType. Keys with
+// custom types are assumed to be strings.
+func (c *Converter) getTypeKey(t reflect.Type, validate string, indent int) string {
+	if _, ok := c.custom[getFullName(t)]; ok && t.Name() != "Time" {
+		return "string"
+	}
+	if t.Name() != "Time" && t.Kind() != reflect.String && t.Implements(textMarshalerType) {
+		return "string"
 	}
+	if t.Name() == "Time" && c.timeFormat == UnixNumber {
+		return "string"
+	}
+
+	// Map keys are not branded, see WithBrandedTypes.
+	unbranded := c.unbranded
+	c.unbranded = true
+	defer func() { c.unbranded = unbranded }()
+
+	return c.getType(t, validate, indent)
 }
 
-func (c *Converter) getTypeField(f reflect.StructField, indent int, optional, nullable bool) string {
-	name := fieldName(f)
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+)
 
-	// fields named `-` are not exported to JSON so don't export types
-	if name == "-" {
+// getTypeEnumKeys returns the union of the string literals allowed by a oneof
+// validation, or a code validation with WithCodeLists, on string map keys, if
+// any.
+func (c *Converter) getTypeEnumKeys(t reflect.Type, validate string) string {
+	if t.Kind() != reflect.String {
 		return ""
 	}
 
-	// because nullability is processed before custom types, this makes sure
-	// the custom type has control over nullability.
-	fullName := getFullName(f.Type)
-	_, isCustom := c.custom[fullName]
+	for _, part := range strings.Split(validate, ",") {
+		part = strings.TrimSpace(part)
+		if c.checkIsIgnored(part) {
+			continue
+		}
 
-	optionalCallPre := ""
-	optionalCallUndef := ""
-	if optional {
-		optionalCallPre = "?"
-		optionalCallUndef = " | undefined"
+		var vals []string
+		if strings.ContainsRune(part, '|') {
+			continue
+		} else if strings.HasPrefix(part, "oneof=") {
+			vals = oneofValues(part[6:])
+		} else if codes, ok := codeListTags[part]; ok && c.codeLists {
+			vals = append(vals, codes...)
+		} else {
+			continue
+		}
+		for i := range vals {
+			vals[i] = strconv.Quote(vals[i])
+		}
+		return strings.Join(vals, " | ")
+	}
+
+	return ""
+}
+
+// Validation describes a validation registered with WithValidatorTranslation,
+// like go-validator's FieldLevel describes one registered with
+// RegisterValidation.
+type Validation struct {
+	// Tag is the name of the validation, eg. "sku" for `validate:"sku=ean"`.
+	Tag string
+	// Param is the parameter of the validation, eg. "ean", or "" if there is none.
+	Param string
+	// Type is the type of the validated value, eg. the element type after dive.
+	Type reflect.Type
+	// Parent is the struct holding the field, or nil when converting a type
+	// outside of a struct field.
+	Parent reflect.Type
+	// Field is the struct field with the validation, or the zero value when
+	// converting a type outside of a struct field.
+	Field reflect.StructField
+	// Key is the JSON name of Field, eg. "confirm", or "" when converting a
+	// type outside of a struct field.
+	Key string
+	// Schema is the name of the schema of Parent, eg. "UserSchema", or "" when
+	// Parent is nil or an anonymous struct.
+	Schema string
+}
+
+// TranslationFn returns the checks translating a validation, appended to the
+// schema of the validated value, eg. ".regex(/^[A-Z]{3}-[0-9]{4}$/)" for a
+// string or ".refine((val) => val.length % 2 === 0)" for a slice. Checks are
+// chained after the built-in string, number and slice checks, so refines can be
+// returned as well.
+type TranslationFn func(v Validation) string
+
+// translateValidation translates a validation registered with
+// WithValidatorTranslation.
+func (c *Converter) translateValidation(t reflect.Type, part string) (string, bool) {
+	tag, param, _ := strings.Cut(part, "=")
+	fn, ok := c.translations[tag]
+	if !ok {
+		return "", false
+	}
+
+	return fn(c.validation(tag, param, t, c.parent, c.structField)), true
+}
+
+// validation describes a validation of a struct field for the translations
+// registered with WithValidatorTranslation and WithObjectValidatorTranslation.
+func (c *Converter) validation(tag, param string, t, parent reflect.Type, field reflect.StructField) Validation {
+	v := Validation{Tag: tag, Param: param, Type: t, Parent: parent, Field: field}
+	if field.Name != "" {
+		v.Key = c.fieldName(field)
+	}
+	if parent != nil && parent.Name() != "" {
+		v.Schema = schemaName(c.prefix, c.structName(parent))
+	}
+
+	return v
+}
+
+// objectChecks returns the checks of the validations of a field registered
+// with WithObjectValidatorTranslation, appended to the schema of the struct
+// holding the field.
+func (c *Converter) objectChecks(parent reflect.Type, field reflect.StructField) []string {
+	if len(c.objectTranslations) == 0 {
+		return nil
+	}
+
+	var checks []string
+	for _, part := range strings.Split(getValidateCurrent(c.expandAliases(field.Tag.Get("validate"))), ",") {
+		tag, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if fn, ok := c.objectTranslations[tag]; ok {
+			checks = append(checks, fn(c.validation(tag, param, field.Type, parent, field)))
+		}
+	}
+
+	return checks
+}
+
+// translateValidations returns the checks of the validations registered with
+// WithValidatorTranslation up to dive, for types whose other validations are
+// translated elsewhere or not at all, such as structs.
+func (c *Converter) translateValidations(t reflect.Type, validate string) string {
+	return c.translateStages(t, validate, CheckStage, RefineStage, TransformStage)
+}
+
+// translateStages returns the checks of the validations registered with
+// WithValidatorTranslation up to dive at the given stages, in the order of the
+// stages.
+func (c *Converter) translateStages(t reflect.Type, validate string, stages ...TranslationStage) string {
+	var checks strings.Builder
+	for _, stage := range stages {
+		for _, part := range strings.Split(getValidateCurrent(validate), ",") {
+			part = strings.TrimSpace(part)
+			if !c.isTranslated(part) || c.translationStage(part) != stage {
+				continue
+			}
+			if check, ok := c.translateValidation(t, part); ok {
+				checks.WriteString(check)
+			}
+		}
+	}
+
+	return checks.String()
+}
+
+// translationStage returns the stage of a validation registered with
+// WithValidatorTranslation, see WithTranslationStage.
+func (c *Converter) translationStage(part string) TranslationStage {
+	tag, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+	return c.translationStages[tag]
+}
+
+// isRefineTranslation checks whether a validation is registered with
+// WithValidatorTranslation, and its checks are placed after the built-in
+// checks, ie. at RefineStage or TransformStage.
+func (c *Converter) isRefineTranslation(part string) bool {
+	return c.isTranslated(part) && c.translationStage(part) != CheckStage
+}
+
+// transformsLast moves the validations translated at TransformStage after the
+// other validations, keeping their order.
+func (c *Converter) transformsLast(parts []string) {
+	isTransform := func(part string) bool {
+		return c.isTranslated(part) && c.translationStage(part) == TransformStage
+	}
+	sort.SliceStable(parts, func(i, j int) bool {
+		return !isTransform(parts[i]) && isTransform(parts[j])
+	})
+}
+
+// isTranslated checks whether a validation is registered with
+// WithValidatorTranslation.
+func (c *Converter) isTranslated(part string) bool {
+	tag, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+	_, ok := c.translations[tag]
+	return ok
+}
+
+// isObjectTranslated checks whether a validation is registered with
+// WithObjectValidatorTranslation.
+func (c *Converter) isObjectTranslated(part string) bool {
+	tag, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+	_, ok := c.objectTranslations[tag]
+	return ok
+}
+
+// parseValidatorVersion parses a go-validator version, eg. "v10.30.5" or
+// "v9.31.0+incompatible", into its major, minor and patch numbers.
+func parseValidatorVersion(version string) [3]int {
+	core, _, _ := strings.Cut(strings.TrimPrefix(version, "v"), "+")
+	core, _, _ = strings.Cut(core, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		panic(fmt.Sprintf("invalid validator version: %s", version))
+	}
+
+	var v [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			panic(fmt.Sprintf("invalid validator version: %s", version))
+		}
+		v[i] = n
+	}
+
+	return v
+}
+
+// validatorBefore checks whether schemas follow a go-validator release earlier
+// than the given one.
+func (c *Converter) validatorBefore(major, minor, patch int) bool {
+	v := c.validatorVersion
+	if v == ([3]int{}) {
+		v = [3]int{10, 28, 0}
+	}
+
+	for i, n := range [3]int{major, minor, patch} {
+		if v[i] != n {
+			return v[i] < n
+		}
+	}
+
+	return false
+}
+
+// versionedRegex returns the regex of a validation that changed across
+// go-validator releases, as used by the release set with
+// WithValidatorSemantics.
+func (c *Converter) versionedRegex(tag string) string {
+	switch tag {
+	case "hexadecimal":
+		if c.validatorBefore(10, 4, 1) {
+			return hexadecimalUnprefixedRegexString
+		}
+		return hexadecimalRegexString
+	case "hexcolor":
+		if c.validatorBefore(10, 10, 0) {
+			return hexColorOpaqueRegexString
+		}
+		return hexColorRegexString
+	case "url_encoded":
+		if c.validatorBefore(10, 6, 1) {
+			return uRLEncodedUnanchoredRegexString
+		}
+		return uRLEncodedRegexString
+	case "e164":
+		if c.validatorBefore(10, 29, 0) {
+			return e164RegexString
+		}
+		if c.validatorBefore(10, 30, 1) {
+			return e164ShortRegexString
+		}
+		return e164PlusOptionalRegexString
+	case "uuid":
+		if c.validatorBefore(10, 30, 3) {
+			return uUIDRegexString
+		}
+		return uUIDMixedCaseRegexString
+	}
+
+	panic(fmt.Sprintf("unversioned validation: %s", tag))
+}
+
+// luhnCheck formats the check that a string of digits, counted by the given
+// quantifier, has a valid Luhn checksum, doubling every second digit from the
+// right as go-validator does.
+const luhnCheck = `/^[0-9]%[2]s$/.test(%[1]s) && [...%[1]s].reverse().reduce((sum, d, i) => sum + (i %% 2 ? [0, 2, 4, 6, 8, 1, 3, 5, 7, 9][d] : +d), 0) %% 10 === 0`
+
+// refinementHelpers are the functions shared with WithSharedRefinements, keyed
+// on their name.
+var refinementHelpers = map[string]string{
+	"luhn":    "(val: string, digits: RegExp) => digits.test(val) && [...val].reverse().reduce((sum, d, i) => sum + (i % 2 ? [0, 2, 4, 6, 8, 1, 3, 5, 7, 9][+d] : +d), 0) % 10 === 0",
+	"mapSize": "(min: number, max: number) => (val: object) => Object.keys(val).length >= min && Object.keys(val).length <= max",
+}
+
+// refinementHelper records a helper as used and returns its name, see
+// WithSharedRefinements.
+func (c *Converter) refinementHelper(name string) string {
+	if c.refinements == nil {
+		c.refinements = make(map[string]bool)
+	}
+	c.refinements[name] = true
+	return name
+}
+
+// luhn returns the check of a Luhn checksum, see luhnCheck.
+func (c *Converter) luhn(val, quantifier string) string {
+	if !c.sharedRefinements {
+		return fmt.Sprintf(luhnCheck, val, quantifier)
+	}
+
+	return fmt.Sprintf("%s(%s, /^[0-9]%s$/)", c.refinementHelper("luhn"), val, quantifier)
+}
+
+// mapSizeCheck returns the refine comparing the number of keys of a map with n
+// using the given operator, eg. ">=".
+func (c *Converter) mapSizeCheck(op, n, message string) string {
+	if val, err := strconv.Atoi(n); err == nil && c.sharedRefinements && op != "!==" {
+		min, max := "0", "Infinity"
+		switch op {
+		case ">":
+			min = strconv.Itoa(val + 1)
+		case ">=":
+			min = n
+		case "<":
+			max = strconv.Itoa(val - 1)
+		case "<=":
+			max = n
+		case "===":
+			min, max = n, n
+		}
+		return fmt.Sprintf(".refine(%s(%s, %s), '%s')", c.refinementHelper("mapSize"), min, max, message)
+	}
+
+	return fmt.Sprintf(".refine((val) => Object.keys(val).length %s %s, '%s')", op, n, message)
+}
+
+// checkRecentTag panics on validations introduced in recent go-validator
+// releases unless they are enabled with WithTagSet(RecentTags).
+func (c *Converter) checkRecentTag(part string) {
+	if c.tagSet < RecentTags {
+		panic(fmt.Sprintf("unknown validation: %s", part))
+	}
+}
+
+// spicedbCheck returns the check of spicedb=kind, where kind is id,
+// permission or type, as go-validator panics on other kinds.
+func (c *Converter) spicedbCheck(kind string) string {
+	switch kind {
+	case "id":
+		return c.regexCheck("spicedb_id", spicedbIDRegexString)
+	case "permission":
+		return c.regexCheck("spicedb_permission", spicedbPermissionRegexString)
+	case "type":
+		return c.regexCheck("spicedb_type", spicedbTypeRegexString)
+	}
+
+	panic(fmt.Sprintf("invalid validation: spicedb=%s", kind))
+}
+
+// isStringRefine checks whether a string validation is translated into a
+// refine rather than a string check. Validations registered with
+// WithValidatorTranslation are taken to be refines.
+func (c *Converter) isStringRefine(part string) bool {
+	if strings.ContainsRune(part, '|') || c.isRefineTranslation(part) {
+		return true
+	}
+
+	name, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+	switch name {
+	case "eq", "ne", "containsany", "excludes", "excludesall", "excludesrune", "lowercase", "uppercase", "json",
+		"credit_card", "luhn_checksum":
+		return true
+	}
+
+	return false
+}
+
+// validateOr returns the check of validations separated by |, of which
+// go-validator requires one to pass. check translates a single alternative into
+// a check of val.
+func validateOr(part string, check func(alternative string) string) string {
+	alternatives := strings.Split(part, "|")
+	checks := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		if alternative = strings.TrimSpace(alternative); alternative == "" {
+			panic(fmt.Sprintf("invalid validation: %s", part))
+		}
+		checks = append(checks, check(alternative))
+	}
+
+	return fmt.Sprintf(".refine((val) => %s)", strings.Join(checks, " || "))
+}
+
+// stringCheck returns the check that val passes the given string validations.
+// Enums are checked with includes, so that validations with a | alternative are
+// not taken for an enum, see enumSchema.
+func stringCheck(validateStr string) string {
+	if !strings.Contains(validateStr, ".enum(") {
+		return fmt.Sprintf("z.string()%s.safeParse(val).success", validateStr)
 	}
-	nullableCall := ""
-	if nullable && !isCustom {
-		nullableCall = " | null"
+
+	enum, rest := splitEnum(validateStr)
+	check := strings.TrimSuffix(strings.TrimPrefix(enum, ".enum("), " as const)") + ".includes(val)"
+	if rest == "" {
+		return check
 	}
 
-	return fmt.Sprintf(
-		"%s%s%s: %s%s%s,\n",
-		indentation(indent),
-		name,
-		optionalCallPre,
-		c.getType(f.Type, indent),
-		nullableCall,
-		optionalCallUndef)
+	return fmt.Sprintf("(z.string()%s.safeParse(val).success && %s)", rest, check)
 }
 
-func (c *Converter) convertSliceAndArray(t reflect.Type, validate string, indent int) string {
-	if t.Kind() == reflect.Array {
-		return fmt.Sprintf(
-			"%s.array()%s",
-			c.ConvertType(t.Elem(), getValidateAfterDive(validate), indent), fmt.Sprintf(".length(%d)", t.Len()))
+// enumSchema returns the schema of a string with validations including an
+// enum. ZodEnum has no string checks, so any other validations are applied to a
+// string piped into the enum.
+func enumSchema(validateStr string) string {
+	enum, rest := splitEnum(validateStr)
+	if rest == "" {
+		return "z" + enum
 	}
 
-	var validateStr strings.Builder
-	validateCurrent := getValidateCurrent(validate)
-	if validateCurrent != "" {
-		parts := strings.Split(validateCurrent, ",")
+	return fmt.Sprintf("z.string()%s.pipe(z%s)", rest, enum)
+}
 
-		// eq and ne should be at the end since they output a refine function
-		sort.SliceStable(parts, func(i, j int) bool {
-			if strings.HasPrefix(parts[i], "ne") {
-				return false
+// splitEnum splits string validations into the .enum(...) call and the other
+// checks.
+func splitEnum(validateStr string) (string, string) {
+	start := strings.Index(validateStr, ".enum(")
+	end, depth, quote, escaped := start+len(".enum"), 0, rune(0), false
+	for i, r := range validateStr[end:] {
+		if quote != 0 {
+			if escaped {
+				escaped = false
+			} else if r == '\\' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
 			}
-			if strings.HasPrefix(parts[j], "ne") {
-				return true
-			}
-			return i < j
-		})
-
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "omitempty" {
-			} else if part == "dive" {
+			continue
+		}
+		if r == '"' || r == '\'' {
+			quote = r
+		} else if r == '(' {
+			depth++
+		} else if r == ')' {
+			depth--
+			if depth == 0 {
+				end += i + 1
 				break
-			} else if part == "required" {
-			} else if strings.HasPrefix(part, "min=") {
-				validateStr.WriteString(fmt.Sprintf(".min(%s)", part[4:]))
-			} else if strings.HasPrefix(part, "max=") {
-				validateStr.WriteString(fmt.Sprintf(".max(%s)", part[4:]))
-			} else if strings.HasPrefix(part, "len=") {
-				validateStr.WriteString(fmt.Sprintf(".length(%s)", part[4:]))
-			} else if strings.HasPrefix(part, "eq=") {
-				validateStr.WriteString(fmt.Sprintf(".length(%s)", part[3:]))
-			} else if strings.HasPrefix(part, "ne=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => val.length !== %s)", part[3:]))
-			} else if strings.HasPrefix(part, "gt=") {
-				val, err := strconv.Atoi(part[3:])
-				if err != nil || val < 0 {
-					panic(fmt.Sprintf("invalid gt value: %s", part[3:]))
-				}
-				validateStr.WriteString(fmt.Sprintf(".min(%d)", val+1))
-			} else if strings.HasPrefix(part, "gte=") {
-				validateStr.WriteString(fmt.Sprintf(".min(%s)", part[4:]))
-			} else if strings.HasPrefix(part, "lt=") {
-				val, err := strconv.Atoi(part[3:])
-				if err != nil || val <= 0 {
-					panic(fmt.Sprintf("invalid lt value: %s", part[3:]))
-				}
-				validateStr.WriteString(fmt.Sprintf(".max(%d)", val-1))
-			} else if strings.HasPrefix(part, "lte=") {
-				validateStr.WriteString(fmt.Sprintf(".max(%s)", part[4:]))
-			} else {
-				panic(fmt.Sprintf("unknown validation: %s", part))
 			}
 		}
 	}
 
-	return fmt.Sprintf(
-		"%s.array()%s",
-		c.ConvertType(t.Elem(), getValidateAfterDive(validate), indent), validateStr.String())
+	return validateStr[start:end], validateStr[:start] + validateStr[end:]
 }
 
-func (c *Converter) getTypeSliceAndArray(t reflect.Type, indent int) string {
-	return fmt.Sprintf(
-		"%s[]",
-		c.getType(t.Elem(), indent))
+// firstRune returns the rune a containsrune or excludesrune validation checks
+// for, which go-validator decodes from the start of the parameter.
+func firstRune(part, valValue string) string {
+	r, size := utf8.DecodeRuneInString(valValue)
+	if r == utf8.RuneError && size <= 1 {
+		panic(fmt.Sprintf("%s must be followed by a rune", part))
+	}
+
+	return string(r)
 }
 
-func (c *Converter) convertKeyType(t reflect.Type, validate string) string {
-	if t.Name() == "Time" {
-		return "z.coerce.date()"
+// oneofValues splits the values of a oneof validation, which may be quoted with
+// single quotes to include spaces, as go-validator does. Like in any parameter,
+// commas and pipes are given as 0x2C and 0x7C. There is no escape for quotes.
+func oneofValues(valValue string) []string {
+	valValue = strings.ReplaceAll(strings.ReplaceAll(valValue, "0x2C", ","), "0x7C", "|")
+	vals := splitParamsRegex.FindAllString(valValue, -1)
+	for i := 0; i < len(vals); i++ {
+		vals[i] = strings.Replace(vals[i], "'", "", -1)
 	}
 
-	// boolean, number, string, any
-	zodType, ok := typeMapping[t.Kind()]
-	if !ok || (zodType != "string" && zodType != "number") {
-		panic(fmt.Sprint("cannot handle key type: ", t.Kind()))
+	return vals
+}
+
+// numberOneofValues returns the values of a oneof validation on numbers as JS
+// literals. go-validator compares them to the formatted number, so values that
+// are not formatted the same, eg. 01, would never match and panic instead.
+func numberOneofValues(part, valValue string, integer bool) []string {
+	vals := oneofValues(valValue)
+	if len(vals) == 0 {
+		panic(fmt.Sprintf("invalid oneof validation: %s", part))
 	}
 
-	var validateStr string
-	if validate != "" {
-		switch zodType {
-		case "string":
-			validateStr = c.validateString(validate)
-			if strings.Contains(validateStr, ".enum(") {
-				return "z" + validateStr
-			}
-		case "number":
-			validateStr = c.validateNumber(validate)
+	for _, val := range vals {
+		i, intErr := strconv.ParseInt(val, 10, 64)
+		u, uintErr := strconv.ParseUint(val, 10, 64)
+		f, floatErr := strconv.ParseFloat(val, 64)
+		switch {
+		case intErr == nil && strconv.FormatInt(i, 10) == val:
+		case uintErr == nil && strconv.FormatUint(u, 10) == val:
+		case !integer && floatErr == nil && strconv.FormatFloat(f, 'f', -1, 64) == val:
+		default:
+			panic(fmt.Sprintf("invalid oneof validation: %s", part))
 		}
 	}
 
-	if zodType == "string" {
-		return fmt.Sprintf("z.%s()%s", zodType, validateStr)
-	}
+	return vals
+}
 
-	// https://pkg.go.dev/encoding/json#Marshal
-	// Map values encode as JSON objects. The map's key type must either be a string, an integer type, or implement encoding.TextMarshaler.
-	return fmt.Sprintf("z.coerce.%s()%s", zodType, validateStr)
+var zenValueTypes = map[string]bool{
+	"string":  true,
+	"number":  true,
+	"boolean": true,
+	"null":    true,
 }
 
-func (c *Converter) convertMap(t reflect.Type, validate string, indent int) string {
-	var validateStr strings.Builder
-	if validate != "" {
-		parts := strings.Split(validate, ",")
+// parseZenValues parses the `zen-values:"string|number"` tag, which constrains
+// the values of a map with interface values to a union of primitive types. It
+// returns the zod schema and TS type of the values.
+func parseZenValues(field reflect.StructField) (string, string, bool) {
+	tag := field.Tag.Get("zen-values")
+	if tag == "" {
+		return "", "", false
+	}
 
-		for _, part := range parts {
-			part = strings.TrimSpace(part)
-			if part == "omitempty" {
-			} else if part == "dive" {
-				break
-			} else if part == "required" {
-				validateStr.WriteString(".refine((val) => Object.keys(val).length > 0, 'Empty map')")
-			} else if strings.HasPrefix(part, "min=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length >= %s, 'Map too small')", part[4:]))
-			} else if strings.HasPrefix(part, "max=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length <= %s, 'Map too large')", part[4:]))
-			} else if strings.HasPrefix(part, "len=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length === %s, 'Map wrong size')", part[4:]))
-			} else if strings.HasPrefix(part, "eq=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length === %s, 'Map wrong size')", part[3:]))
-			} else if strings.HasPrefix(part, "ne=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length !== %s, 'Map wrong size')", part[3:]))
-			} else if strings.HasPrefix(part, "gt=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length > %s, 'Map too small')", part[3:]))
-			} else if strings.HasPrefix(part, "gte=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length >= %s, 'Map too small')", part[4:]))
-			} else if strings.HasPrefix(part, "lt=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length < %s, 'Map too large')", part[3:]))
-			} else if strings.HasPrefix(part, "lte=") {
-				validateStr.WriteString(fmt.Sprintf(".refine((val) => Object.keys(val).length <= %s, 'Map too large')", part[4:]))
-			} else {
-				panic(fmt.Sprintf("unknown validation: %s", part))
-			}
+	t := elemType(field.Type)
+	if t.Kind() != reflect.Map || t.Elem().Kind() != reflect.Interface {
+		panic(fmt.Sprintf("zen-values requires a map with interface values, got %s", field.Type))
+	}
+
+	var schemas, types []string
+	for _, value := range strings.Split(tag, "|") {
+		value = strings.TrimSpace(value)
+		if !zenValueTypes[value] {
+			panic(fmt.Sprintf("unknown zen-values type: %s", value))
 		}
+		schemas = append(schemas, fmt.Sprintf("z.%s()", value))
+		types = append(types, value)
 	}
 
-	return fmt.Sprintf(`z.record(%s, %s)%s`,
-		c.convertKeyType(t.Key(), getValidateKeys(validate)),
-		c.ConvertType(t.Elem(), getValidateValues(validate), indent),
-		validateStr.String())
-}
+	if len(schemas) == 1 {
+		return schemas[0], types[0], true
+	}
 
-func (c *Converter) getTypeMap(t reflect.Type, indent int) string {
-	return fmt.Sprintf(`Record<%s, %s>`,
-		c.getType(t.Key(), indent),
-		c.getType(t.Elem(), indent))
+	return fmt.Sprintf("z.union([%s])", strings.Join(schemas, ", ")), strings.Join(types, " | "), true
 }
 
 // Select part of validate string after dive, if it exists.
@@ -688,25 +3984,17 @@ func getValidateKeys(validate string) string {
 	return validateKeys
 }
 
+// getValidateValues selects the part of the validate string applying to map
+// values, ie. everything after dive or after endkeys. Further dives are kept, as
+// they apply to the elements of the values.
 func getValidateValues(validate string) string {
 	var validateValues string
 
 	if strings.Contains(validate, "dive,keys") {
-		removedPrefix := strings.SplitN(validate, ",endkeys", 2)[1]
-
-		if strings.Contains(removedPrefix, ",dive") {
-			validateValues = strings.SplitN(removedPrefix, ",dive", 2)[0]
-		} else {
-			validateValues = removedPrefix
-		}
+		validateValues = strings.SplitN(validate, ",endkeys", 2)[1]
 		validateValues = strings.TrimPrefix(validateValues, ",")
-	} else if strings.Contains(validate, "dive") {
-		removedPrefix := strings.SplitN(validate, "dive,", 2)[1]
-		if strings.Contains(removedPrefix, ",dive") {
-			validateValues = strings.SplitN(removedPrefix, ",dive", 2)[0]
-		} else {
-			validateValues = removedPrefix
-		}
+	} else if strings.Contains(validate, "dive,") {
+		validateValues = strings.SplitN(validate, "dive,", 2)[1]
 	}
 
 	return validateValues
@@ -725,9 +4013,36 @@ func (c *Converter) checkIsIgnored(part string) bool {
 	return false
 }
 
+// ignoredCommentPrefix starts the comments added by WithIgnoredTagComments.
+const ignoredCommentPrefix = " // zen: ignored validation"
+
+// ignoredComment returns the trailing comment listing the validations of a
+// field skipped by WithIgnoreTags, if any, see WithIgnoredTagComments.
+func (c *Converter) ignoredComment(f reflect.StructField) string {
+	if !c.ignoredComments {
+		return ""
+	}
+
+	var ignored []string
+	for _, part := range strings.Split(c.fieldValidate(f), ",") {
+		if part = strings.TrimSpace(part); part != "" && c.checkIsIgnored(part) {
+			ignored = append(ignored, strconv.Quote(part))
+		}
+	}
+
+	switch len(ignored) {
+	case 0:
+		return ""
+	case 1:
+		return ignoredCommentPrefix + " " + ignored[0]
+	default:
+		return ignoredCommentPrefix + "s " + strings.Join(ignored, ", ")
+	}
+}
+
 // not implementing omitempty for numbers and strings
 // could support unusual cases like `validate:"omitempty,min=3,max=5"`
-func (c *Converter) validateNumber(validate string) string {
+func (c *Converter) validateNumber(t reflect.Type, validate string) string {
 	var validateStr strings.Builder
 	parts := strings.Split(validate, ",")
 
@@ -735,22 +4050,35 @@ func (c *Converter) validateNumber(validate string) string {
 	sort.SliceStable(parts, func(i, j int) bool {
 		if strings.HasPrefix(parts[i], "eq") || strings.HasPrefix(parts[i], "len") ||
 			strings.HasPrefix(parts[i], "ne") || strings.HasPrefix(parts[i], "oneof") ||
-			strings.HasPrefix(parts[i], "required") {
+			strings.HasPrefix(parts[i], "required") || strings.HasPrefix(parts[i], "luhn_checksum") ||
+			strings.ContainsRune(parts[i], '|') || c.isRefineTranslation(parts[i]) {
 			return false
 		}
 		if strings.HasPrefix(parts[j], "eq") || strings.HasPrefix(parts[j], "len") ||
 			strings.HasPrefix(parts[j], "ne") || strings.HasPrefix(parts[j], "oneof") ||
-			strings.HasPrefix(parts[j], "required") {
+			strings.HasPrefix(parts[j], "required") || strings.HasPrefix(parts[j], "luhn_checksum") ||
+			strings.ContainsRune(parts[j], '|') || c.isRefineTranslation(parts[j]) {
 			return true
 		}
 		return i < j
 	})
+	c.transformsLast(parts)
 
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if c.checkIsIgnored(part) {
 			continue
 		}
+		if strings.ContainsRune(part, '|') {
+			validateStr.WriteString(validateOr(part, func(alternative string) string {
+				return fmt.Sprintf("z.number()%s.safeParse(val).success", c.validateNumber(t, alternative))
+			}))
+			continue
+		}
+		if check, ok := c.translateValidation(t, part); ok {
+			validateStr.WriteString(check)
+			continue
+		}
 
 		if strings.ContainsRune(part, '=') {
 			idx := strings.Index(part, "=")
@@ -775,10 +4103,7 @@ func (c *Converter) validateNumber(validate string) string {
 			case "ne":
 				validateStr.WriteString(fmt.Sprintf(".refine((val) => val !== %s)", valValue))
 			case "oneof":
-				vals := strings.Fields(valValue)
-				if len(vals) == 0 {
-					panic(fmt.Sprintf("invalid oneof validation: %s", part))
-				}
+				vals := numberOneofValues(part, valValue, false)
 				validateStr.WriteString(fmt.Sprintf(".refine((val) => [%s].includes(val))", strings.Join(vals, ", ")))
 
 			default:
@@ -786,9 +4111,14 @@ func (c *Converter) validateNumber(validate string) string {
 			}
 		} else {
 			switch part {
-			case "omitempty":
+			case "omitempty", "omitnil", "omitzero":
 			case "required":
-				validateStr.WriteString(".refine((val) => val !== 0)")
+				if !c.requiredPresenceOnly {
+					validateStr.WriteString(".refine((val) => val !== 0)")
+				}
+			case "luhn_checksum":
+				c.checkRecentTag(part)
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => %s)", c.luhn("String(val)", "{2,}")))
 			default:
 				panic(fmt.Sprintf("unknown validation: %s", part))
 			}
@@ -798,26 +4128,89 @@ func (c *Converter) validateNumber(validate string) string {
 	return validateStr.String()
 }
 
-func (c *Converter) validateString(validate string) string {
+// validateAny returns the checks of the validations of an interface mapped to
+// z.any(), which includes null and undefined. Only required is checked, the
+// other validations depend on the dynamic type.
+func validateAny(validate string) string {
+	for _, part := range strings.Split(validate, ",") {
+		if part = strings.TrimSpace(part); part == "dive" {
+			break
+		} else if part == "required" {
+			return ".refine((val) => val !== null && val !== undefined)"
+		}
+	}
+
+	return ""
+}
+
+// validateBoolean returns the checks of the validations of a bool. Like
+// go-validator, required rejects false unless WithRequiredPresenceOnly is set,
+// and eq and ne accept the values of strconv.ParseBool.
+func (c *Converter) validateBoolean(t reflect.Type, validate string) string {
 	var validateStr strings.Builder
 	parts := strings.Split(validate, ",")
+	c.transformsLast(parts)
 
-	// eq and ne should be at the end since they output a refine function
-	sort.SliceStable(parts, func(i, j int) bool {
-		if strings.HasPrefix(parts[i], "eq") || strings.HasPrefix(parts[i], "ne") {
-			return false
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" || isOmitMarker(part) || c.checkIsIgnored(part) {
+			continue
 		}
-		if strings.HasPrefix(parts[j], "eq") || strings.HasPrefix(parts[j], "ne") {
-			return true
+		if part == "required" {
+			if !c.requiredPresenceOnly {
+				validateStr.WriteString(".refine((val) => val !== false)")
+			}
+			continue
 		}
-		return i < j
+		if check, ok := c.translateValidation(t, part); ok {
+			validateStr.WriteString(check)
+			continue
+		}
+
+		valName, valValue, _ := strings.Cut(part, "=")
+		val, err := strconv.ParseBool(valValue)
+		if err != nil && (valName == "eq" || valName == "ne") {
+			panic(fmt.Sprintf("%s= must be followed by a boolean", valName))
+		}
+
+		switch valName {
+		case "eq":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => val === %t)", val))
+		case "ne":
+			validateStr.WriteString(fmt.Sprintf(".refine((val) => val !== %t)", val))
+		default:
+			panic(fmt.Sprintf("unknown validation: %s", part))
+		}
+	}
+
+	return validateStr.String()
+}
+
+func (c *Converter) validateString(t reflect.Type, validate string) string {
+	var validateStr strings.Builder
+	parts := strings.Split(validate, ",")
+
+	// refines should be at the end since ZodEffects has no string checks
+	sort.SliceStable(parts, func(i, j int) bool {
+		return !c.isStringRefine(parts[i]) && c.isStringRefine(parts[j])
 	})
+	c.transformsLast(parts)
 
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
 		if c.checkIsIgnored(part) {
 			continue
 		}
+		if strings.ContainsRune(part, '|') {
+			validateStr.WriteString(validateOr(part, func(alternative string) string {
+				return stringCheck(c.validateString(t, alternative))
+			}))
+			continue
+		}
+		if check, ok := c.translateValidation(t, part); ok {
+			validateStr.WriteString(check)
+			continue
+		}
 		// We handle the parts which have = separately
 		if strings.ContainsRune(part, '=') {
 			idx := strings.Index(part, "=")
@@ -830,15 +4223,15 @@ func (c *Converter) validateString(validate string) string {
 
 			switch valName {
 			case "oneof":
-				vals := splitParamsRegex.FindAllString(part[6:], -1)
-				for i := 0; i < len(vals); i++ {
-					vals[i] = strings.Replace(vals[i], "'", "", -1)
-				}
+				vals := oneofValues(valValue)
 				if len(vals) == 0 {
 					panic("oneof= must be followed by a list of values")
 				}
 				// const FishEnum = z.enum(["Salmon", "Tuna", "Trout"]);
-				validateStr.WriteString(fmt.Sprintf(".enum([\"%s\"] as const)", strings.Join(vals, "\", \"")))
+				for i := range vals {
+					vals[i] = strconv.Quote(vals[i])
+				}
+				validateStr.WriteString(fmt.Sprintf(".enum([%s] as const)", strings.Join(vals, ", ")))
 			case "len":
 				validateStr.WriteString(fmt.Sprintf(".length(%s)", valValue))
 			case "min":
@@ -863,6 +4256,17 @@ func (c *Converter) validateString(validate string) string {
 				validateStr.WriteString(fmt.Sprintf(".max(%s)", valValue))
 			case "contains":
 				validateStr.WriteString(fmt.Sprintf(".includes(\"%s\")", valValue))
+			case "containsany":
+				// spreading iterates over code points, as go-validator does over runes
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => [...%s].some((c) => val.includes(c)))", strconv.Quote(valValue)))
+			case "containsrune":
+				validateStr.WriteString(fmt.Sprintf(".includes(%s)", strconv.Quote(firstRune(part, valValue))))
+			case "excludes":
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => !val.includes(%s))", strconv.Quote(valValue)))
+			case "excludesall":
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => ![...%s].some((c) => val.includes(c)))", strconv.Quote(valValue)))
+			case "excludesrune":
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => !val.includes(%s))", strconv.Quote(firstRune(part, valValue))))
 			case "endswith":
 				validateStr.WriteString(fmt.Sprintf(".endsWith(\"%s\")", valValue))
 			case "startswith":
@@ -871,15 +4275,36 @@ func (c *Converter) validateString(validate string) string {
 				validateStr.WriteString(fmt.Sprintf(".refine((val) => val === \"%s\")", valValue))
 			case "ne":
 				validateStr.WriteString(fmt.Sprintf(".refine((val) => val !== \"%s\")", valValue))
+			case "datetime":
+				validateStr.WriteString(datetimeChecks(valValue))
+			case "spicedb":
+				c.checkRecentTag(part)
+				validateStr.WriteString(c.spicedbCheck(valValue))
 
 			default:
 				panic(fmt.Sprintf("unknown validation: %s", part))
 			}
 		} else {
 			switch part {
-			case "omitempty":
+			case "omitempty", "omitnil", "omitzero":
 			case "required":
-				validateStr.WriteString(".min(1)")
+				if !c.requiredPresenceOnly {
+					validateStr.WriteString(".min(1)")
+				}
+			case "credit_card":
+				c.checkRecentTag(part)
+				// segments separated by spaces have at least 3 digits
+				validateStr.WriteString(fmt.Sprintf(`.refine((val) => val.split(" ").every((s) => s.length >= 3) && %s)`,
+					c.luhn(`val.replaceAll(" ", "")`, "{12,19}")))
+			case "luhn_checksum":
+				c.checkRecentTag(part)
+				validateStr.WriteString(fmt.Sprintf(".refine((val) => %s)", c.luhn("val", "{2,}")))
+			case "mongodb_connection_string":
+				c.checkRecentTag(part)
+				validateStr.WriteString(c.regexCheck(part, mongodbConnStringRegexString))
+			case "spicedb":
+				c.checkRecentTag(part)
+				validateStr.WriteString(c.spicedbCheck("id"))
 			case "email":
 				// email is more readable than copying the regex in regexes.go but could be incompatible
 				// Also there is an open issue https://github.com/go-playground/validator/issues/517
@@ -905,35 +4330,70 @@ func (c *Converter) validateString(validate string) string {
 				// url is more readable than copying the regex in regexes.go but could be incompatible
 				validateStr.WriteString(".url()")
 			case "url_encoded":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uRLEncodedRegexString))
+				validateStr.WriteString(c.regexCheck(part, c.versionedRegex(part)))
+			case "hostname":
+				validateStr.WriteString(c.regexCheck(part, hostnameRegexStringRFC952))
+			case "hostname_rfc1123":
+				validateStr.WriteString(c.regexCheck(part, hostnameRegexStringRFC1123))
+			case "fqdn":
+				validateStr.WriteString(c.regexCheck(part, fqdnRegexStringRFC1123))
+			case "mac":
+				validateStr.WriteString(c.regexCheck(part, macRegexString))
+			case "e164":
+				validateStr.WriteString(c.regexCheck(part, c.versionedRegex(part)))
+			case "hexcolor":
+				validateStr.WriteString(c.regexCheck(part, c.versionedRegex(part)))
+			case "rgb":
+				validateStr.WriteString(c.regexCheck(part, rgbRegexString))
+			case "rgba":
+				validateStr.WriteString(c.regexCheck(part, rgbaRegexString))
+			case "hsl":
+				validateStr.WriteString(c.regexCheck(part, hslRegexString))
+			case "hsla":
+				validateStr.WriteString(c.regexCheck(part, hslaRegexString))
+			case "iso3166_1_alpha2", "iso3166_1_alpha2_eu":
+				validateStr.WriteString(c.codeList(part, alpha2CodeRegexString))
+			case "iso3166_1_alpha3", "iso3166_1_alpha3_eu", "iso4217":
+				validateStr.WriteString(c.codeList(part, alpha3CodeRegexString))
+			case "iso3166_2":
+				validateStr.WriteString(c.codeList(part, iso31662RegexString))
+			case "semver":
+				validateStr.WriteString(c.regexCheck(part, semverRegexString))
+			case "ulid":
+				validateStr.WriteString(".ulid()")
+			case "cve":
+				validateStr.WriteString(c.regexCheck(part, cveRegexString))
+			case "cron":
+				// unanchored, as go-validator matches it anywhere in the string
+				validateStr.WriteString(c.regexCheck(part, cronRegexString))
 			case "alpha":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", alphaRegexString))
+				validateStr.WriteString(c.regexCheck(part, alphaRegexString))
 			case "alphanum":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", alphaNumericRegexString))
+				validateStr.WriteString(c.regexCheck(part, alphaNumericRegexString))
 			case "alphanumunicode":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", alphaUnicodeNumericRegexString))
+				validateStr.WriteString(c.regexCheck(part, alphaUnicodeNumericRegexString))
 			case "alphaunicode":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", alphaUnicodeRegexString))
+				validateStr.WriteString(c.regexCheck(part, alphaUnicodeRegexString))
 			case "ascii":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", aSCIIRegexString))
+				validateStr.WriteString(c.regexCheck(part, aSCIIRegexString))
 			case "boolean":
 				validateStr.WriteString(".enum(['true', 'false'])")
 			case "lowercase":
 				validateStr.WriteString(".refine((val) => val === val.toLowerCase())")
 			case "number":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", numberRegexString))
+				validateStr.WriteString(c.regexCheck(part, numberRegexString))
 			case "numeric":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", numericRegexString))
+				validateStr.WriteString(c.regexCheck(part, numericRegexString))
 			case "uppercase":
 				validateStr.WriteString(".refine((val) => val === val.toUpperCase())")
 			case "base64":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", base64RegexString))
+				validateStr.WriteString(c.regexCheck(part, base64RegexString))
 			case "mongodb":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", mongodbRegexString))
+				validateStr.WriteString(c.regexCheck(part, mongodbRegexString))
 			case "datetime":
 				validateStr.WriteString(".datetime()")
 			case "hexadecimal":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", hexadecimalRegexString))
+				validateStr.WriteString(c.regexCheck(part, c.versionedRegex(part)))
 			case "json":
 				// TODO: Better error messages with this
 				// const literalSchema = z.union([z.string(), z.number(), z.boolean(), z.null()]);
@@ -947,37 +4407,37 @@ func (c *Converter) validateString(validate string) string {
 
 				validateStr.WriteString(".refine((val) => { try { JSON.parse(val); return true } catch { return false } })")
 			case "jwt":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", jWTRegexString))
+				validateStr.WriteString(c.regexCheck(part, jWTRegexString))
 			case "latitude":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", latitudeRegexString))
+				validateStr.WriteString(c.regexCheck(part, latitudeRegexString))
 			case "longitude":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", longitudeRegexString))
+				validateStr.WriteString(c.regexCheck(part, longitudeRegexString))
 			case "uuid":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUIDRegexString))
+				validateStr.WriteString(c.regexCheck(part, c.versionedRegex(part)))
 			case "uuid3":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID3RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID3RegexString))
 			case "uuid3_rfc4122":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID3RFC4122RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID3RFC4122RegexString))
 			case "uuid4":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID4RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID4RegexString))
 			case "uuid4_rfc4122":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID4RFC4122RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID4RFC4122RegexString))
 			case "uuid5":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID5RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID5RegexString))
 			case "uuid5_rfc4122":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUID5RFC4122RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUID5RFC4122RegexString))
 			case "uuid_rfc4122":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", uUIDRFC4122RegexString))
+				validateStr.WriteString(c.regexCheck(part, uUIDRFC4122RegexString))
 			case "md4":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", md4RegexString))
+				validateStr.WriteString(c.regexCheck(part, md4RegexString))
 			case "md5":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", md5RegexString))
+				validateStr.WriteString(c.regexCheck(part, md5RegexString))
 			case "sha256":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", sha256RegexString))
+				validateStr.WriteString(c.regexCheck(part, sha256RegexString))
 			case "sha384":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", sha384RegexString))
+				validateStr.WriteString(c.regexCheck(part, sha384RegexString))
 			case "sha512":
-				validateStr.WriteString(fmt.Sprintf(".regex(/%s/)", sha512RegexString))
+				validateStr.WriteString(c.regexCheck(part, sha512RegexString))
 
 			default:
 				panic(fmt.Sprintf("unknown validation: %s", part))
@@ -988,8 +4448,30 @@ func (c *Converter) validateString(validate string) string {
 	return validateStr.String()
 }
 
-func isNullable(field reflect.StructField) bool {
-	validateCurrent := getValidateCurrent(field.Tag.Get("validate"))
+// fieldPresence returns whether a field is optional and whether it is
+// nullable. Errors mapped with WithErrorSchema and interfaces mapped with
+// WithInterfaceUnion are treated like pointers, as unlike z.any() their schemas
+// include neither undefined nor null.
+func (c *Converter) fieldPresence(field reflect.StructField) (bool, bool) {
+	if _, ok := c.unions[elemType(field.Type)]; !ok && !c.isMappedError(elemType(field.Type)) {
+		return c.isOptional(field), c.isNullable(field)
+	}
+
+	tag := parseZenTag(field)
+	if strings.Contains(getValidateCurrent(c.fieldValidate(field)), "required") {
+		return tag.optional, tag.nullable
+	}
+
+	omitEmpty := jsonOmitsEmpty(field)
+	return tag.optional || omitEmpty, tag.nullable || !omitEmpty
+}
+
+func (c *Converter) isNullable(field reflect.StructField) bool {
+	if parseZenTag(field).nullable {
+		return true
+	}
+
+	validateCurrent := getValidateCurrent(c.fieldValidate(field))
 
 	// interfaces are currently exported with "any" type, which already includes "null"
 	if isInterface(field) || strings.Contains(validateCurrent, "required") {
@@ -998,15 +4480,15 @@ func isNullable(field reflect.StructField) bool {
 
 	// If some comparison is present min=1 or max=2 or len=4 etc. then go-validator requires the value
 	// to be non-nil unless omitempty is also present
-	if strings.Contains(validateCurrent, "=") && !strings.Contains(validateCurrent, "omitempty") {
+	if strings.Contains(validateCurrent, "=") && !omitsEmpty(field.Type, validateCurrent) {
 		return false
 	}
 
 	// pointers can be nil, which are mapped to null in JS/TS.
 	if field.Type.Kind() == reflect.Ptr {
-		// However, if a pointer field is tagged with "omitempty", it usually cannot be exported as "null"
-		// since nil is a pointer's empty value.
-		if strings.Contains(field.Tag.Get("json"), "omitempty") {
+		// However, if a pointer field is tagged with "omitempty" or "omitzero", it usually cannot be
+		// exported as "null" since nil is a pointer's empty value.
+		if jsonOmitsEmpty(field) {
 			// Unless it is a pointer to a slice, a map, a pointer, or an interface
 			// because values with those types can themselves be nil and will be exported as "null".
 			k := field.Type.Elem().Kind()
@@ -1019,7 +4501,96 @@ func isNullable(field reflect.StructField) bool {
 	// nil slices and maps are exported as null so these types are usually nullable
 	if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Map {
 		// unless there are also optional in which case they are no longer nullable
-		return !strings.Contains(field.Tag.Get("json"), "omitempty")
+		return !jsonOmitsEmpty(field)
+	}
+
+	return false
+}
+
+// jsonOmitsEmpty checks whether the JSON encoding of a field leaves it out when
+// it is empty, ie. it is tagged with `json:",omitempty"` or `json:",omitzero"`.
+// Both omit nil pointers, slices and maps and zero strings, numbers and bools,
+// so that the field is optional rather than nullable. omitempty also omits
+// empty slices and maps, while omitzero also omits zero structs and arrays,
+// see isOptional.
+func jsonOmitsEmpty(field reflect.StructField) bool {
+	return hasJSONOption(field, "omitempty") || hasJSONOption(field, "omitzero")
+}
+
+// hasJSONOption checks whether the json tag of a field has the given option,
+// eg. omitempty.
+func hasJSONOption(field reflect.StructField, option string) bool {
+	_, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		if opt == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isNullableElem checks whether a map value or array element can be nil and
+// hence be encoded as null, which is ruled out by a required validation after
+// dive. Arrays of pointers are filled with nil by default, unlike slices, whose
+// elements are taken to be set. Interfaces mapped with WithInterfaceUnion are
+// treated like pointers, other interfaces are z.any(), which includes null.
+func (c *Converter) isNullableElem(t reflect.Type, validate string) bool {
+	if _, ok := c.unions[t]; !ok && t.Kind() != reflect.Ptr {
+		return false
+	}
+
+	for _, part := range strings.Split(getValidateCurrent(validate), ",") {
+		if strings.TrimSpace(part) == "required" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// dropRequired removes required from the validations of the current level. A
+// required pointer only has to be non-nil for go-validator, so the value it
+// points to may be zero. Slices, maps and interfaces are still checked once
+// dereferenced and keep their required.
+func dropRequired(validate string) string {
+	parts := strings.Split(validate, ",")
+	kept := make([]string, 0, len(parts))
+	for i, part := range parts {
+		if strings.TrimSpace(part) == "dive" {
+			kept = append(kept, parts[i:]...)
+			break
+		}
+		if strings.TrimSpace(part) != "required" {
+			kept = append(kept, part)
+		}
+	}
+
+	return strings.Join(kept, ",")
+}
+
+// isOmitMarker checks whether a validation is omitempty, omitnil or omitzero,
+// which skip the other validations of empty, nil or zero values.
+func isOmitMarker(part string) bool {
+	return part == "omitempty" || part == "omitnil" || part == "omitzero"
+}
+
+// omitsEmpty checks whether the validations of a value of type t are skipped
+// when it is empty, ie. a zero value or a nil pointer, slice or map. omitzero
+// behaves as omitempty, while omitnil only does so on types that can be nil.
+func omitsEmpty(t reflect.Type, validate string) bool {
+	for _, part := range strings.Split(validate, ",") {
+		switch strings.TrimSpace(part) {
+		case "omitempty", "omitzero":
+			return true
+		case "omitnil":
+			switch t.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+				return true
+			}
+		}
 	}
 
 	return false
@@ -1047,47 +4618,58 @@ func isInterface(field reflect.StructField) bool {
 	return t.Kind() == reflect.Interface
 }
 
-func isOptional(field reflect.StructField) bool {
-	validateCurrent := getValidateCurrent(field.Tag.Get("validate"))
+func (c *Converter) isOptional(field reflect.StructField) bool {
+	if parseZenTag(field).optional {
+		return true
+	}
+
+	validateCurrent := getValidateCurrent(c.fieldValidate(field))
 
 	// Non-pointer struct types and direct or indirect interface types should never be optional().
 	// Struct fields that are themselves structs ignore the "omitempty" tag because
-	// structs do not have an empty value.
+	// structs do not have an empty value. "omitzero" however omits zero structs, or
+	// those whose IsZero method reports them as zero, such as time.Time.
 	// Interfaces are currently exported with "any" type, which already includes "undefined"
-	if field.Type.Kind() == reflect.Struct || isInterface(field) ||
+	if field.Type.Kind() == reflect.Struct && !hasJSONOption(field, "omitzero") || isInterface(field) ||
 		strings.Contains(validateCurrent, "required") {
 		return false
 	}
 
+	// Arrays are only empty, and hence omitted with "omitempty", when their length is
+	// zero. "omitzero" omits arrays whose elements are all zero.
+	if field.Type.Kind() == reflect.Array && field.Type.Len() > 0 && !hasJSONOption(field, "omitzero") {
+		return false
+	}
+
 	// If some comparison is present min=1 or max=2 or len=4 etc. then go-validator requires the value
 	// to be non-nil unless omitempty is also present
-	if strings.Contains(validateCurrent, "=") && !strings.Contains(validateCurrent, "omitempty") {
+	if strings.Contains(validateCurrent, "=") && !omitsEmpty(field.Type, validateCurrent) {
 		return false
 	}
 
-	// Otherwise, omitempty zero-values are omitted and are mapped to undefined in JS/TS.
-	return strings.Contains(field.Tag.Get("json"), "omitempty")
+	// Otherwise, omitempty and omitzero zero-values are omitted and are mapped to undefined in JS/TS.
+	return jsonOmitsEmpty(field)
 }
 
 func indentation(level int) string {
 	return strings.Repeat(" ", level*2)
 }
 
-func detectCycle(name string, stack []meta) {
-	var found bool
-	var cycle strings.Builder
-	for _, m := range stack {
-		cycle.WriteString(m.name)
-		if m.name == name {
-			found = true
-			break
+// markCycle checks whether the named struct is currently being converted, ie.
+// it is referenced from within itself either directly or through other structs.
+// All structs that are part of the cycle are marked as self-referential, so that
+// they are emitted with explicit TS types and z.ZodType annotations.
+func (c *Converter) markCycle(name string) bool {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].name == name {
+			for j := i; j < len(c.stack); j++ {
+				c.stack[j].selfRef = true
+			}
+			return true
 		}
-		cycle.WriteString(" -> ")
 	}
 
-	if found {
-		panic(fmt.Sprintf("circular dependency detected: %s", cycle.String()))
-	}
+	return false
 }
 
 func getTypeNameWithGenerics(name string) string {
@@ -1099,11 +4681,74 @@ func getTypeNameWithGenerics(name string) string {
 	var sb strings.Builder
 	sb.WriteString(name[:typeArgsIdx])
 
-	typeArgs := strings.Split(name[typeArgsIdx+1:len(name)-1], ",")
-	for _, arg := range typeArgs {
-		sb.WriteString(strings.ToTitle(arg[:1])) // Capitalize first letter
-		sb.WriteString(arg[1:])
+	for _, arg := range splitTypeArgs(name[typeArgsIdx+1 : len(name)-1]) {
+		sb.WriteString(typeArgName(arg))
 	}
 
 	return sb.String()
 }
+
+// splitTypeArgs splits the type arguments of a generic type name on the commas
+// outside of brackets, as the arguments may be generic themselves.
+func splitTypeArgs(args string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range args {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, args[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, args[start:])
+}
+
+// typeArgName turns a type argument into a PascalCase identifier, ie.
+// *pkg.Foo becomes PtrFoo, []int becomes SliceInt and map[string]int becomes
+// MapStringInt. Package paths are dropped, as are the indices of types
+// declared in functions.
+func typeArgName(arg string) string {
+	switch {
+	case strings.HasPrefix(arg, "*"):
+		return "Ptr" + typeArgName(arg[1:])
+	case strings.HasPrefix(arg, "[]"):
+		return "Slice" + typeArgName(arg[2:])
+	case strings.HasPrefix(arg, "["):
+		end := strings.Index(arg, "]")
+		return "Array" + arg[1:end] + typeArgName(arg[end+1:])
+	case strings.HasPrefix(arg, "map["):
+		end, depth := len("map["), 0
+		for ; arg[end] != ']' || depth > 0; end++ {
+			if arg[end] == '[' {
+				depth++
+			} else if arg[end] == ']' {
+				depth--
+			}
+		}
+		return "Map" + typeArgName(arg[len("map["):end]) + typeArgName(arg[end+1:])
+	}
+
+	base, args := arg, ""
+	if i := strings.Index(arg, "["); i >= 0 {
+		base, args = arg[:i], arg[i:]
+	}
+	if i := strings.LastIndex(base, "/"); i >= 0 {
+		base = base[i+1:]
+	}
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		base = base[i+1:]
+	}
+	// Types declared in functions carry an index, ie. Node·1.
+	if i := strings.Index(base, "·"); i >= 0 {
+		base = base[:i]
+	}
+
+	return pascalCase(getTypeNameWithGenerics(base + args))
+}
@@ -1,12 +1,19 @@
 package zen
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFieldName(t *testing.T) {
@@ -68,7 +75,7 @@ func TestStructSimple(t *testing.T) {
 	assert.Equal(t,
 		`export const UserSchema = z.object({
   Name: z.string(),
-  Age: z.number(),
+  Age: z.number().int(),
   Height: z.number(),
 })
 export type User = z.infer<typeof UserSchema>
@@ -87,7 +94,7 @@ func TestStructSimpleWithOmittedField(t *testing.T) {
 	assert.Equal(t,
 		`export const UserSchema = z.object({
   Name: z.string(),
-  Age: z.number(),
+  Age: z.number().int(),
   Height: z.number(),
 })
 export type User = z.infer<typeof UserSchema>
@@ -105,7 +112,7 @@ func TestStructSimplePrefix(t *testing.T) {
 	assert.Equal(t,
 		`export const BotUserSchema = z.object({
   Name: z.string(),
-  Age: z.number(),
+  Age: z.number().int(),
   Height: z.number(),
 })
 export type BotUser = z.infer<typeof BotUserSchema>
@@ -138,8 +145,10 @@ export const HasNameSchema = z.object({
 export type HasName = z.infer<typeof HasNameSchema>
 
 export const UserSchema = z.object({
+  ...HasIDSchema.shape,
+  ...HasNameSchema.shape,
   Tags: z.string().array().nullable(),
-}).merge(HasIDSchema).merge(HasNameSchema)
+})
 export type User = z.infer<typeof UserSchema>
 
 `,
@@ -175,6 +184,62 @@ export type User = z.infer<typeof UserSchema>
 		StructToZodSchema(User{}))
 }
 
+func TestArrayPointers(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Arrays struct {
+		Items         [3]Item
+		PtrArray      *[3]Item
+		PtrElems      [3]*Item
+		PtrBoth       *[3]*Item
+		RequiredElems [3]*Item  `validate:"dive,required"`
+		RequiredBoth  *[3]*Item `validate:"required,dive,required"`
+		OmitPtrArray  *[3]Item  `json:",omitempty"`
+		OmitArray     [3]Item   `json:",omitempty"`
+		Strings       [2]*string
+		Grid          [2][2]*int
+		Slice         []*Item
+	}
+
+	assert.Equal(t, `export const ItemSchema = z.object({
+  name: z.string(),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+export const ArraysSchema = z.object({
+  Items: ItemSchema.array().length(3),
+  PtrArray: ItemSchema.array().length(3).nullable(),
+  PtrElems: ItemSchema.nullable().array().length(3),
+  PtrBoth: ItemSchema.nullable().array().length(3).nullable(),
+  RequiredElems: ItemSchema.array().length(3),
+  RequiredBoth: ItemSchema.array().length(3),
+  OmitPtrArray: ItemSchema.array().length(3).optional(),
+  OmitArray: ItemSchema.array().length(3),
+  Strings: z.string().nullable().array().length(2),
+  Grid: z.number().int().nullable().array().length(2).array().length(2),
+  Slice: ItemSchema.array().nullable(),
+})
+export type Arrays = z.infer<typeof ArraysSchema>
+
+`, StructToZodSchema(Arrays{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Arrays{}), `export type Arrays = {
+  Items: Item[],
+  PtrArray: Item[] | null,
+  PtrElems: (Item | null)[],
+  PtrBoth: (Item | null)[] | null,
+  RequiredElems: Item[],
+  RequiredBoth: Item[],
+  OmitPtrArray?: Item[] | undefined,
+  OmitArray: Item[],
+  Strings: (string | null)[],
+  Grid: (number | null)[][],
+  Slice: Item[] | null,
+}`)
+}
+
 func TestStructSlice(t *testing.T) {
 	type User struct {
 		Favourites []struct {
@@ -387,11 +452,11 @@ func TestNullableWithValidations(t *testing.T) {
   Slice1: z.string().array().min(2).max(5),
   Slice2: z.string().array().min(2).max(5),
   SliceNullable: z.string().array().min(2).max(5).nullable(),
-  PtrIntOptional1: z.number().optional(),
-  PtrIntOptional2: z.number().gte(2).lte(5).optional(),
-  PtrInt1: z.number().gte(2).lte(5),
-  PtrInt2: z.number().gte(2).lte(5),
-  PtrIntNullable: z.number().gte(2).lte(5).nullable(),
+  PtrIntOptional1: z.number().int().optional(),
+  PtrIntOptional2: z.number().int().gte(2).lte(5).optional(),
+  PtrInt1: z.number().int().gte(2).lte(5),
+  PtrInt2: z.number().int().gte(2).lte(5),
+  PtrIntNullable: z.number().int().gte(2).lte(5).nullable(),
   PtrStringOptional1: z.string().optional(),
   PtrStringOptional2: z.string().min(2).max(5).optional(),
   PtrString1: z.string().min(2).max(5),
@@ -453,33 +518,19 @@ export type OneOfSeparated = z.infer<typeof OneOfSeparatedSchema>
 `,
 		StructToZodSchema(OneOfSeparated{}))
 
-	// TODO: This test case is not supported yet even for the go-validator package whose logic
-	// I stole to parse the value after oneof=.
-	//
-	//	type OneOfEscaped struct {
-	//		Name string `validate:"oneof='a b c' 'd e f' 'g\\' h'"`
-	//	}
-	//	assert.Equal(t,
-	//		`export const OneOfEscapedSchema = z.object({
-	//  Name: z.string().enum(["a b c", "d e f", "g' h"]),
-	//})
-	//export type OneOfEscaped = z.infer<typeof OneOfEscapedSchema>
-	//
-	//`,
-	//		StructToZodSchema(OneOfEscaped{}))
-
-	// Same story as above.
-	//	type OneOfEscaped2 struct {
-	//		Name string `validate:"oneof='a b c' 'd e f' 'g\x27 h'"`
-	//	}
-	//	assert.Equal(t,
-	//		`export const OneOfEscapedSchema = z.object({
-	//  Name: z.string().enum(["a b c", "d e f", "g' h"]),
-	//})
-	//export type OneOfEscaped = z.infer<typeof OneOfEscapedSchema>
-	//
-	//`,
-	//		StructToZodSchema(OneOfEscaped2{}))
+	// go-validator has no escape for quotes, so a quoted value ends at the next
+	// quote, while commas and pipes are given as 0x2C and 0x7C.
+	type OneOfEscaped struct {
+		Name string `validate:"required,oneof='a b c' 'g\\' h' 'x0x2Cy' \"q\""`
+	}
+	assert.Equal(t,
+		`export const OneOfEscapedSchema = z.object({
+  Name: z.string().min(1).pipe(z.enum(["a b c", "g\\", "h", "x,y", "\"q\""] as const)),
+})
+export type OneOfEscaped = z.infer<typeof OneOfEscapedSchema>
+
+`,
+		StructToZodSchema(OneOfEscaped{}))
 
 	type Len struct {
 		Name string `validate:"len=5"`
@@ -589,6 +640,33 @@ export type Contains = z.infer<typeof ContainsSchema>
 `,
 		StructToZodSchema(Contains{}))
 
+	type Substrings struct {
+		Any          string `validate:"containsany=!@#"`
+		Rune         string `validate:"containsrune=☺"`
+		Excludes     string `validate:"excludes=\"x\""`
+		ExcludesAll  string `validate:"excludesall=<>"`
+		ExcludesRune string `validate:"excludesrune=€uro"`
+	}
+	assert.Equal(t,
+		`export const SubstringsSchema = z.object({
+  Any: z.string().refine((val) => [..."!@#"].some((c) => val.includes(c))),
+  Rune: z.string().includes("☺"),
+  Excludes: z.string().refine((val) => !val.includes("\"x\"")),
+  ExcludesAll: z.string().refine((val) => ![..."<>"].some((c) => val.includes(c))),
+  ExcludesRune: z.string().refine((val) => !val.includes("€")),
+})
+export type Substrings = z.infer<typeof SubstringsSchema>
+
+`,
+		StructToZodSchema(Substrings{}))
+
+	type BadRune struct {
+		Name string `validate:"containsrune=\xff"`
+	}
+	assert.PanicsWithValue(t, "containsrune=\xff must be followed by a rune", func() {
+		StructToZodSchema(BadRune{})
+	})
+
 	type EndsWith struct {
 		Name string `validate:"endswith=hello"`
 	}
@@ -908,6 +986,20 @@ export type datetime = z.infer<typeof datetimeSchema>
 `,
 		StructToZodSchema(datetime{}))
 
+	type DatetimeLayout struct {
+		Day   string `validate:"required,datetime=2006-01-02"`
+		Clock string `validate:"datetime=15:04"`
+	}
+	assert.Equal(t,
+		`export const DatetimeLayoutSchema = z.object({
+  Day: z.string().min(1).date(),
+  Clock: z.string().regex(/^([01][0-9]|2[0-3]|[0-9]):[0-5][0-9]$/),
+})
+export type DatetimeLayout = z.infer<typeof DatetimeLayoutSchema>
+
+`,
+		StructToZodSchema(DatetimeLayout{}))
+
 	type Hexadecimal struct {
 		Name string `validate:"hexadecimal"`
 	}
@@ -1112,6 +1204,192 @@ export type SHA512 = z.infer<typeof SHA512Schema>
 `, sha512RegexString),
 		StructToZodSchema(SHA512{}))
 
+	type Hostname struct {
+		Name string `validate:"hostname"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const HostnameSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type Hostname = z.infer<typeof HostnameSchema>
+
+`, hostnameRegexStringRFC952),
+		StructToZodSchema(Hostname{}))
+
+	type HostnameRFC1123 struct {
+		Name string `validate:"hostname_rfc1123"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const HostnameRFC1123Schema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type HostnameRFC1123 = z.infer<typeof HostnameRFC1123Schema>
+
+`, hostnameRegexStringRFC1123),
+		StructToZodSchema(HostnameRFC1123{}))
+
+	type FQDN struct {
+		Name string `validate:"fqdn"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const FQDNSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type FQDN = z.infer<typeof FQDNSchema>
+
+`, fqdnRegexStringRFC1123),
+		StructToZodSchema(FQDN{}))
+
+	type MAC struct {
+		Name string `validate:"mac"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const MACSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type MAC = z.infer<typeof MACSchema>
+
+`, macRegexString),
+		StructToZodSchema(MAC{}))
+
+	type E164 struct {
+		Name string `validate:"e164"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const E164Schema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type E164 = z.infer<typeof E164Schema>
+
+`, e164RegexString),
+		StructToZodSchema(E164{}))
+
+	type Semver struct {
+		Name string `validate:"semver"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const SemverSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type Semver = z.infer<typeof SemverSchema>
+
+`, semverRegexString),
+		StructToZodSchema(Semver{}))
+
+	type CVE struct {
+		Name string `validate:"cve"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const CVESchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type CVE = z.infer<typeof CVESchema>
+
+`, cveRegexString),
+		StructToZodSchema(CVE{}))
+
+	type Cron struct {
+		Name string `validate:"cron"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const CronSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type Cron = z.infer<typeof CronSchema>
+
+`, cronRegexString),
+		StructToZodSchema(Cron{}))
+
+	type ULID struct {
+		Name string `validate:"ulid"`
+	}
+	assert.Equal(t,
+		`export const ULIDSchema = z.object({
+  Name: z.string().ulid(),
+})
+export type ULID = z.infer<typeof ULIDSchema>
+
+`,
+		StructToZodSchema(ULID{}))
+
+	type HexColor struct {
+		Name string `validate:"hexcolor"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const HexColorSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type HexColor = z.infer<typeof HexColorSchema>
+
+`, hexColorRegexString),
+		StructToZodSchema(HexColor{}))
+
+	type RGB struct {
+		Name string `validate:"rgb"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const RGBSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type RGB = z.infer<typeof RGBSchema>
+
+`, rgbRegexString),
+		StructToZodSchema(RGB{}))
+
+	type RGBA struct {
+		Name string `validate:"rgba"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const RGBASchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type RGBA = z.infer<typeof RGBASchema>
+
+`, rgbaRegexString),
+		StructToZodSchema(RGBA{}))
+
+	type HSL struct {
+		Name string `validate:"hsl"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const HSLSchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type HSL = z.infer<typeof HSLSchema>
+
+`, hslRegexString),
+		StructToZodSchema(HSL{}))
+
+	type HSLA struct {
+		Name string `validate:"hsla"`
+	}
+	assert.Equal(t,
+		fmt.Sprintf(`export const HSLASchema = z.object({
+  Name: z.string().regex(/%s/),
+})
+export type HSLA = z.infer<typeof HSLASchema>
+
+`, hslaRegexString),
+		StructToZodSchema(HSLA{}))
+
+	type Codes struct {
+		Country   string `validate:"iso3166_1_alpha2"`
+		CountryEU string `validate:"iso3166_1_alpha3_eu"`
+		Region    string `validate:"iso3166_2"`
+		Currency  string `validate:"iso4217"`
+	}
+	assert.Equal(t,
+		`export const CodesSchema = z.object({
+  Country: z.string().regex(/^[A-Z]{2}$/),
+  CountryEU: z.string().regex(/^[A-Z]{3}$/),
+  Region: z.string().regex(/^[A-Z]{2}-[A-Z0-9]{1,3}$/),
+  Currency: z.string().regex(/^[A-Z]{3}$/),
+})
+export type Codes = z.infer<typeof CodesSchema>
+
+`,
+		StructToZodSchema(Codes{}))
+
 	type Bad2 struct {
 		Name string `validate:"bad2"`
 	}
@@ -1120,13 +1398,46 @@ export type SHA512 = z.infer<typeof SHA512Schema>
 	})
 }
 
+func TestBooleanValidations(t *testing.T) {
+	type Consent struct {
+		Agreed   bool   `validate:"required"`
+		Accepted bool   `validate:"eq=true"`
+		Opted    bool   `validate:"omitempty,ne=1"`
+		Flags    []bool `validate:"dive,eq=false"`
+	}
+	assert.Equal(t,
+		`export const ConsentSchema = z.object({
+  Agreed: z.boolean().refine((val) => val !== false),
+  Accepted: z.boolean().refine((val) => val === true),
+  Opted: z.boolean().refine((val) => val !== true),
+  Flags: z.boolean().refine((val) => val === false).array().nullable(),
+})
+export type Consent = z.infer<typeof ConsentSchema>
+
+`, StructToZodSchema(Consent{}))
+
+	type BadEq struct {
+		Agreed bool `validate:"eq=yes"`
+	}
+	assert.PanicsWithValue(t, "eq= must be followed by a boolean", func() {
+		StructToZodSchema(BadEq{})
+	})
+
+	type BadMin struct {
+		Agreed bool `validate:"min=1"`
+	}
+	assert.PanicsWithValue(t, "unknown validation: min=1", func() {
+		StructToZodSchema(BadMin{})
+	})
+}
+
 func TestNumberValidations(t *testing.T) {
 	type User1 struct {
 		Age int `validate:"gte=18,lte=60"`
 	}
 	assert.Equal(t,
 		`export const User1Schema = z.object({
-  Age: z.number().gte(18).lte(60),
+  Age: z.number().int().gte(18).lte(60),
 })
 export type User1 = z.infer<typeof User1Schema>
 
@@ -1137,7 +1448,7 @@ export type User1 = z.infer<typeof User1Schema>
 	}
 	assert.Equal(t,
 		`export const User2Schema = z.object({
-  Age: z.number().gt(18).lt(60),
+  Age: z.number().int().gt(18).lt(60),
 })
 export type User2 = z.infer<typeof User2Schema>
 
@@ -1148,7 +1459,7 @@ export type User2 = z.infer<typeof User2Schema>
 	}
 	assert.Equal(t,
 		`export const User3Schema = z.object({
-  Age: z.number().refine((val) => val === 18),
+  Age: z.number().int().refine((val) => val === 18),
 })
 export type User3 = z.infer<typeof User3Schema>
 
@@ -1159,7 +1470,7 @@ export type User3 = z.infer<typeof User3Schema>
 	}
 	assert.Equal(t,
 		`export const User4Schema = z.object({
-  Age: z.number().refine((val) => val !== 18),
+  Age: z.number().int().refine((val) => val !== 18),
 })
 export type User4 = z.infer<typeof User4Schema>
 
@@ -1170,18 +1481,39 @@ export type User4 = z.infer<typeof User4Schema>
 	}
 	assert.Equal(t,
 		`export const User5Schema = z.object({
-  Age: z.number().refine((val) => [18, 19, 20].includes(val)),
+  Age: z.number().int().refine((val) => [18, 19, 20].includes(val)),
 })
 export type User5 = z.infer<typeof User5Schema>
 
 `, StructToZodSchema(User5{}))
 
+	// quoted values are compared to the formatted number too
+	type QuotedOneOf struct {
+		Age   int     `validate:"oneof='18' '-1'"`
+		Ratio float64 `validate:"oneof=0.5 1"`
+	}
+	assert.Equal(t,
+		`export const QuotedOneOfSchema = z.object({
+  Age: z.number().int().refine((val) => [18, -1].includes(val)),
+  Ratio: z.number().refine((val) => [0.5, 1].includes(val)),
+})
+export type QuotedOneOf = z.infer<typeof QuotedOneOfSchema>
+
+`, StructToZodSchema(QuotedOneOf{}))
+
+	type BadOneOf struct {
+		Age int `validate:"oneof=018"`
+	}
+	assert.PanicsWithValue(t, "invalid oneof validation: oneof=018", func() {
+		StructToZodSchema(BadOneOf{})
+	})
+
 	type User6 struct {
 		Age int `validate:"min=18,max=60"`
 	}
 	assert.Equal(t,
 		`export const User6Schema = z.object({
-  Age: z.number().gte(18).lte(60),
+  Age: z.number().int().gte(18).lte(60),
 })
 export type User6 = z.infer<typeof User6Schema>
 
@@ -1192,7 +1524,7 @@ export type User6 = z.infer<typeof User6Schema>
 	}
 	assert.Equal(t,
 		`export const User7Schema = z.object({
-  Age: z.number().refine((val) => val === 18),
+  Age: z.number().int().refine((val) => val === 18),
 })
 export type User7 = z.infer<typeof User7Schema>
 
@@ -1493,7 +1825,7 @@ func TestMapWithNonStringKey(t *testing.T) {
 	assert.Equal(t,
 		`export const Map1Schema = z.object({
   Name: z.string(),
-  Metadata: z.record(z.coerce.number(), z.string()).nullable(),
+  Metadata: z.record(z.coerce.number().int(), z.string()).nullable(),
 })
 export type Map1 = z.infer<typeof Map1Schema>
 
@@ -1550,15 +1882,16 @@ func TestGetValidateValues(t *testing.T) {
 	assert.Equal(t, "", getValidateValues("dive,keys,min=3,max=5,endkeys"))
 	assert.Equal(t, "max=4", getValidateValues("dive,keys,endkeys,max=4"))
 
-	assert.Equal(t, "max=4", getValidateValues("dive,keys,min=3,endkeys,max=4,dive,keys,min=3,endkeys,max=4"))
-	assert.Equal(t, "min=3,max=4", getValidateValues("dive,keys,min=3,max=5,endkeys,min=3,max=4,dive,keys,min=3,max=5,endkeys,max=4"))
-	assert.Equal(t, "", getValidateValues("dive,keys,min=3,endkeys,dive,keys,min=3,endkeys"))
-	assert.Equal(t, "", getValidateValues("dive,keys,min=3,max=5,endkeys,dive,keys,min=3,max=5,endkeys"))
-	assert.Equal(t, "max=4", getValidateValues("dive,keys,endkeys,max=4,dive,keys,endkeys,max=4"))
+	assert.Equal(t, "max=4,dive,keys,min=3,endkeys,max=4", getValidateValues("dive,keys,min=3,endkeys,max=4,dive,keys,min=3,endkeys,max=4"))
+	assert.Equal(t, "min=3,max=4,dive,keys,min=3,max=5,endkeys,max=4", getValidateValues("dive,keys,min=3,max=5,endkeys,min=3,max=4,dive,keys,min=3,max=5,endkeys,max=4"))
+	assert.Equal(t, "dive,keys,min=3,endkeys", getValidateValues("dive,keys,min=3,endkeys,dive,keys,min=3,endkeys"))
+	assert.Equal(t, "dive,keys,min=3,max=5,endkeys", getValidateValues("dive,keys,min=3,max=5,endkeys,dive,keys,min=3,max=5,endkeys"))
+	assert.Equal(t, "max=4,dive,keys,endkeys,max=4", getValidateValues("dive,keys,endkeys,max=4,dive,keys,endkeys,max=4"))
 
 	assert.Equal(t, "min=3", getValidateValues("min=2,dive,min=3"))
-	assert.Equal(t, "min=3,max=4", getValidateValues("dive,min=3,max=4,dive,min=4,max=5"))
+	assert.Equal(t, "min=3,max=4,dive,min=4,max=5", getValidateValues("dive,min=3,max=4,dive,min=4,max=5"))
 	assert.Equal(t, "max=4", getValidateValues("min=2,dive,keys,min=3,endkeys,max=4"))
+	assert.Equal(t, "", getValidateValues("min=2,dive"))
 }
 
 func TestGetValidateCurrent(t *testing.T) {
@@ -1621,7 +1954,7 @@ export type PostWithMetaData = z.infer<typeof PostWithMetaDataSchema>
 export const UserSchema = z.object({
   Name: z.string(),
   Nickname: z.string().nullable(),
-  Age: z.number(),
+  Age: z.number().int(),
   Height: z.number(),
   OldPostWithMetaData: PostWithMetaDataSchema,
   Tags: z.string().array().nullable(),
@@ -1705,7 +2038,7 @@ export type PostWithMetaData = z.infer<typeof PostWithMetaDataSchema>
 export const UserSchema = z.object({
   Name: z.string().min(1),
   Nickname: z.string().nullable(),
-  Age: z.number().gte(18).refine((val) => val !== 0),
+  Age: z.number().int().gte(18).refine((val) => val !== 0),
   Height: z.number().gte(1.5).refine((val) => val !== 0),
   OldPostWithMetaData: PostWithMetaDataSchema,
   Tags: z.string().array().min(1),
@@ -1966,7 +2299,7 @@ func TestDuration(t *testing.T) {
 	}
 	assert.Equal(t,
 		`export const UserSchema = z.object({
-  HowLong: z.number(),
+  HowLong: z.number().int(),
 })
 export type User = z.infer<typeof UserSchema>
 
@@ -2019,14 +2352,15 @@ func TestRecursive1(t *testing.T) {
   project_id: number,
   children: NestedItem[] | null,
 }
-export const NestedItemSchema: z.ZodType<NestedItem> = z.object({
-  id: z.number(),
+export const NestedItemSchemaShape = {
+  id: z.number().int(),
   title: z.string(),
-  pos: z.number(),
-  parent_id: z.number(),
-  project_id: z.number(),
+  pos: z.number().int(),
+  parent_id: z.number().int(),
+  project_id: z.number().int(),
   children: z.lazy(() => NestedItemSchema).array().nullable(),
-})
+}
+export const NestedItemSchema: z.ZodType<NestedItem> = z.object(NestedItemSchemaShape)
 
 `, StructToZodSchema(NestedItem{}))
 }
@@ -2045,10 +2379,11 @@ func TestRecursive2(t *testing.T) {
   value: number,
   next: Node | null,
 }
-export const NodeSchema: z.ZodType<Node> = z.object({
-  value: z.number(),
+export const NodeSchemaShape = {
+  value: z.number().int(),
   next: z.lazy(() => NodeSchema).nullable(),
-})
+}
+export const NodeSchema: z.ZodType<Node> = z.object(NodeSchemaShape)
 
 export const ParentSchema = z.object({
   child: NodeSchema.nullable(),
@@ -2067,9 +2402,72 @@ type TestCyclicB struct {
 }
 
 func TestCyclic(t *testing.T) {
-	assert.Panics(t, func() {
-		StructToZodSchema(TestCyclicA{})
-	})
+	assert.Equal(t, `export type TestCyclicB = {
+  A: TestCyclicA | null,
+}
+export const TestCyclicBSchemaShape = {
+  A: z.lazy(() => TestCyclicASchema).nullable(),
+}
+export const TestCyclicBSchema: z.ZodType<TestCyclicB> = z.object(TestCyclicBSchemaShape)
+
+export type TestCyclicA = {
+  B: TestCyclicB | null,
+}
+export const TestCyclicASchemaShape = {
+  B: TestCyclicBSchema.nullable(),
+}
+export const TestCyclicASchema: z.ZodType<TestCyclicA> = z.object(TestCyclicASchemaShape)
+
+`, StructToZodSchema(TestCyclicA{}))
+}
+
+type TestCyclicGraph struct {
+	Nodes []TestCyclicNode `json:"nodes"`
+}
+
+type TestCyclicNode struct {
+	ID    string           `json:"id"`
+	Edges []TestCyclicEdge `json:"edges"`
+}
+
+type TestCyclicEdge struct {
+	To    *TestCyclicNode  `json:"to"`
+	Graph *TestCyclicGraph `json:"graph"`
+}
+
+func TestCyclicLong(t *testing.T) {
+	c := NewConverter(nil)
+	c.AddType(TestCyclicGraph{})
+	c.AddType(TestCyclicNode{})
+	assert.Equal(t, `export type TestCyclicEdge = {
+  to: TestCyclicNode | null,
+  graph: TestCyclicGraph | null,
+}
+export const TestCyclicEdgeSchemaShape = {
+  to: z.lazy(() => TestCyclicNodeSchema).nullable(),
+  graph: z.lazy(() => TestCyclicGraphSchema).nullable(),
+}
+export const TestCyclicEdgeSchema: z.ZodType<TestCyclicEdge> = z.object(TestCyclicEdgeSchemaShape)
+
+export type TestCyclicNode = {
+  id: string,
+  edges: TestCyclicEdge[] | null,
+}
+export const TestCyclicNodeSchemaShape = {
+  id: z.string(),
+  edges: TestCyclicEdgeSchema.array().nullable(),
+}
+export const TestCyclicNodeSchema: z.ZodType<TestCyclicNode> = z.object(TestCyclicNodeSchemaShape)
+
+export type TestCyclicGraph = {
+  nodes: TestCyclicNode[] | null,
+}
+export const TestCyclicGraphSchemaShape = {
+  nodes: TestCyclicNodeSchema.array().nullable(),
+}
+export const TestCyclicGraphSchema: z.ZodType<TestCyclicGraph> = z.object(TestCyclicGraphSchemaShape)
+
+`, c.Export())
 }
 
 type GenericPair[T any, U any] struct {
@@ -2090,12 +2488,12 @@ func TestGenerics(t *testing.T) {
 	c.AddType(PairMap[string, int, bool]{})
 	assert.Equal(t, `export const StringIntPairSchema = z.object({
   First: z.string(),
-  Second: z.number(),
+  Second: z.number().int(),
 })
 export type StringIntPair = z.infer<typeof StringIntPairSchema>
 
 export const GenericPairIntBoolSchema = z.object({
-  First: z.number(),
+  First: z.number().int(),
   Second: z.boolean(),
 })
 export type GenericPairIntBool = z.infer<typeof GenericPairIntBoolSchema>
@@ -2108,6 +2506,171 @@ export type PairMapStringIntBool = z.infer<typeof PairMapStringIntBoolSchema>
 `, c.Export())
 }
 
+type Über struct {
+	Name string `json:"name"`
+}
+
+type Box[T any] struct {
+	Value T `json:"value"`
+}
+
+func TestGenericTypeArgNames(t *testing.T) {
+	for _, tc := range []struct {
+		value    interface{}
+		expected string
+	}{
+		{Box[*GenericPair[int, bool]]{}, "BoxPtrGenericPairIntBool"},
+		{Box[[]int]{}, "BoxSliceInt"},
+		{Box[[3]string]{}, "BoxArray3String"},
+		{Box[map[string][]int]{}, "BoxMapStringSliceInt"},
+		{Box[map[GenericPair[int, bool]]int]{}, "BoxMapGenericPairIntBoolInt"},
+		{Box[Über]{}, "BoxÜber"},
+		{Box[Box[uint8]]{}, "BoxBoxUint8"},
+	} {
+		assert.Equal(t, tc.expected, getTypeNameWithGenerics(reflect.TypeOf(tc.value).Name()))
+	}
+}
+
+func TestGenericsPrefix(t *testing.T) {
+	type Node struct {
+		Pair     GenericPair[Über, int]      `json:"pair"`
+		Children []Box[*Node]                `json:"children"`
+		Items    PairMap[string, Über, bool] `json:"items"`
+	}
+
+	c := NewConverterWithOpts(WithPrefix("Api"))
+	c.AddType(Node{})
+	assert.Equal(t, `export const ApiÜberSchema = z.object({
+  name: z.string(),
+})
+export type ApiÜber = z.infer<typeof ApiÜberSchema>
+
+export const ApiGenericPairÜberIntSchema = z.object({
+  First: ApiÜberSchema,
+  Second: z.number().int(),
+})
+export type ApiGenericPairÜberInt = z.infer<typeof ApiGenericPairÜberIntSchema>
+
+export type ApiBoxPtrNode = {
+  value: ApiNode | null,
+}
+export const ApiBoxPtrNodeSchemaShape = {
+  value: z.lazy(() => ApiNodeSchema).nullable(),
+}
+export const ApiBoxPtrNodeSchema: z.ZodType<ApiBoxPtrNode> = z.object(ApiBoxPtrNodeSchemaShape)
+
+export const ApiGenericPairÜberBoolSchema = z.object({
+  First: ApiÜberSchema,
+  Second: z.boolean(),
+})
+export type ApiGenericPairÜberBool = z.infer<typeof ApiGenericPairÜberBoolSchema>
+
+export const ApiPairMapStringÜberBoolSchema = z.object({
+  items: z.record(z.string(), ApiGenericPairÜberBoolSchema).nullable(),
+})
+export type ApiPairMapStringÜberBool = z.infer<typeof ApiPairMapStringÜberBoolSchema>
+
+export type ApiNode = {
+  pair: ApiGenericPairÜberInt,
+  children: ApiBoxPtrNode[] | null,
+  items: ApiPairMapStringÜberBool,
+}
+export const ApiNodeSchemaShape = {
+  pair: ApiGenericPairÜberIntSchema,
+  children: ApiBoxPtrNodeSchema.array().nullable(),
+  items: ApiPairMapStringÜberBoolSchema,
+}
+export const ApiNodeSchema: z.ZodType<ApiNode> = z.object(ApiNodeSchemaShape)
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithPrefix("Api"), WithAlwaysLazy())
+	c.AddType(Box[GenericPair[Über, int]]{})
+	assert.Equal(t, `export type ApiÜber = {
+  name: string,
+}
+export const ApiÜberSchema: z.ZodType<ApiÜber> = z.lazy(() => z.object({
+  name: z.string(),
+}))
+
+export type ApiGenericPairÜberInt = {
+  First: ApiÜber,
+  Second: number,
+}
+export const ApiGenericPairÜberIntSchema: z.ZodType<ApiGenericPairÜberInt> = z.lazy(() => z.object({
+  First: ApiÜberSchema,
+  Second: z.number().int(),
+}))
+
+export type ApiBoxGenericPairÜberInt = {
+  value: ApiGenericPairÜberInt,
+}
+export const ApiBoxGenericPairÜberIntSchema: z.ZodType<ApiBoxGenericPairÜberInt> = z.lazy(() => z.object({
+  value: ApiGenericPairÜberIntSchema,
+}))
+
+`, c.Export())
+}
+
+func TestGenericsRecursiveCustom(t *testing.T) {
+	type Node struct {
+		Name   string                       `json:"name"`
+		Child  Box[*Node]                   `json:"child"`
+		Parent Box[GenericPair[*Node, int]] `json:"parent"`
+		Nested Box[Box[*Node]]              `json:"nested"`
+	}
+
+	// the custom type matches the wrappers nested in one another, while the
+	// cycle is detected at Node
+	c := NewConverterWithOpts(WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.Box": func(c *Converter, t reflect.Type, validate string, i int) string {
+			return c.ConvertType(t.Field(0).Type, validate, i) + ".optional()"
+		},
+	}))
+	assert.Equal(t, `export type GenericPairPtrNodeInt = {
+  First: Node | null,
+  Second: number,
+}
+export const GenericPairPtrNodeIntSchemaShape = {
+  First: z.lazy(() => NodeSchema).nullable(),
+  Second: z.number().int(),
+}
+export const GenericPairPtrNodeIntSchema: z.ZodType<GenericPairPtrNodeInt> = z.object(GenericPairPtrNodeIntSchemaShape)
+
+export type Node = {
+  name: string,
+  child?: unknown,
+  parent?: unknown,
+  nested?: unknown,
+}
+export const NodeSchemaShape = {
+  name: z.string(),
+  child: z.lazy(() => NodeSchema).optional(),
+  parent: GenericPairPtrNodeIntSchema.optional(),
+  nested: z.lazy(() => NodeSchema).optional().optional(),
+}
+export const NodeSchema: z.ZodType<Node> = z.object(NodeSchemaShape)
+
+`, c.Convert(Node{}))
+
+	// the TS types of custom types follow their handlers
+	c = NewConverterWithOpts(WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.Box": func(c *Converter, t reflect.Type, validate string, i int) string {
+			return c.ConvertType(t.Field(0).Type, validate, i) + ".optional()"
+		},
+	}), WithCustomTSTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.Box": func(c *Converter, t reflect.Type, validate string, i int) string {
+			return c.ConvertTSType(t.Field(0).Type, validate, i) + " | undefined"
+		},
+	}))
+	assert.Contains(t, c.Convert(Node{}), `export type Node = {
+  name: string,
+  child?: Node | undefined,
+  parent?: GenericPairPtrNodeInt | undefined,
+  nested?: Node | undefined | undefined,
+}`)
+}
+
 func TestSliceFields(t *testing.T) {
 	type TestSliceFieldsStruct struct {
 		NoValidate       []int
@@ -2120,15 +2683,3384 @@ func TestSliceFields(t *testing.T) {
 	}
 
 	assert.Equal(t, `export const TestSliceFieldsStructSchema = z.object({
-  NoValidate: z.number().array().nullable(),
-  Required: z.number().array(),
-  Min: z.number().array().min(1),
-  OmitEmpty: z.number().array().nullable(),
-  JSONOmitEmpty: z.number().array().optional(),
-  MinOmitEmpty: z.number().array().min(1).nullable(),
-  JSONMinOmitEmpty: z.number().array().min(1).optional(),
+  NoValidate: z.number().int().array().nullable(),
+  Required: z.number().int().array(),
+  Min: z.number().int().array().min(1),
+  OmitEmpty: z.number().int().array().nullable(),
+  JSONOmitEmpty: z.number().int().array().optional(),
+  MinOmitEmpty: z.number().int().array().min(1).nullable(),
+  JSONMinOmitEmpty: z.number().int().array().min(1).optional(),
 })
 export type TestSliceFieldsStruct = z.infer<typeof TestSliceFieldsStructSchema>
 
 `, StructToZodSchema(TestSliceFieldsStruct{}))
 }
+
+func TestNameCollision(t *testing.T) {
+	newUser := func() interface{} {
+		type User struct {
+			Name string
+		}
+		return User{}
+	}
+	type User struct {
+		ID int
+	}
+	type Team struct {
+		Owner User
+	}
+
+	c := NewConverter(nil)
+	c.AddType(Team{})
+	err := c.TryAddType(newUser())
+
+	var collision *NameCollisionError
+	assert.ErrorAs(t, err, &collision)
+	assert.Equal(t, "User", collision.Name)
+	assert.Equal(t, reflect.TypeOf(User{}), collision.First)
+	assert.Equal(t, reflect.TypeOf(newUser()), collision.Second)
+	assert.Panics(t, func() {
+		c.AddType(newUser())
+	})
+
+	// the converter is still usable after a failed TryAddType
+	assert.NoError(t, c.TryAddType(User{}))
+	assert.Equal(t, `export const UserSchema = z.object({
+  ID: z.number().int(),
+})
+export type User = z.infer<typeof UserSchema>
+
+export const TeamSchema = z.object({
+  Owner: UserSchema,
+})
+export type Team = z.infer<typeof TeamSchema>
+
+`, c.Export())
+}
+
+func TestNameCollisionNested(t *testing.T) {
+	type Item struct {
+		ID int
+	}
+	type Order struct {
+		Item Item
+	}
+	otherItem := func() interface{} {
+		type Item struct {
+			Name string
+		}
+		type Cart struct {
+			Item Item
+		}
+		return Cart{}
+	}
+
+	c := NewConverter(nil)
+	c.AddType(Order{})
+	var collision *NameCollisionError
+	assert.ErrorAs(t, c.TryAddType(otherItem()), &collision)
+	assert.Equal(t, "Item", collision.Name)
+}
+
+func TestTryAddTypeRollback(t *testing.T) {
+	broken := func() interface{} {
+		type Address struct {
+			City string `validate:"bogus"`
+		}
+		type Order struct {
+			Address Address
+		}
+		return Order{}
+	}
+	type Address struct {
+		City string `validate:"required"`
+	}
+	type Order struct {
+		Address Address
+	}
+
+	c := NewConverter(nil)
+	assert.EqualError(t, c.TryAddType(broken()), "unknown validation: bogus")
+	assert.Empty(t, c.Export())
+
+	// the names registered before the failure are released
+	assert.NoError(t, c.TryAddType(Order{}))
+	assert.Equal(t, `export const AddressSchema = z.object({
+  City: z.string().min(1),
+})
+export type Address = z.infer<typeof AddressSchema>
+
+export const OrderSchema = z.object({
+  Address: AddressSchema,
+})
+export type Order = z.infer<typeof OrderSchema>
+
+`, c.Export())
+}
+
+func TestPackageQualifiedNames(t *testing.T) {
+	type Address struct {
+		City string
+	}
+	type User struct {
+		Address  Address
+		Modified time.Time
+	}
+
+	c := NewConverterWithOpts(WithPackageQualifiedNames())
+	assert.Equal(t, `export const ZenAddressSchema = z.object({
+  City: z.string(),
+})
+export type ZenAddress = z.infer<typeof ZenAddressSchema>
+
+export const ZenUserSchema = z.object({
+  Address: ZenAddressSchema,
+  Modified: z.coerce.date(),
+})
+export type ZenUser = z.infer<typeof ZenUserSchema>
+
+`, c.Convert(User{}))
+}
+
+func TestPackageName(t *testing.T) {
+	assert.Equal(t, "PkgA", packageName("example.com/x/pkg_a"))
+	assert.Equal(t, "Zen", packageName("github.com/hypersequent/zen"))
+	assert.Equal(t, "Yaml", packageName("gopkg.in/yaml.v3"))
+	assert.Equal(t, "Bar", packageName("github.com/foo/bar/v2"))
+	assert.Equal(t, "Main", packageName("main"))
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	type Address struct {
+		City string            `json:"city"`
+		Tags map[string]string `json:"tags"`
+	}
+	type User struct {
+		Name    string         `json:"name" validate:"oneof=a b c"`
+		Address Address        `json:"address"`
+		Scores  map[string]int `json:"scores" validate:"dive,keys,min=1,endkeys,gte=0"`
+	}
+	type Team struct {
+		Members []User              `json:"members"`
+		Lead    *User               `json:"lead"`
+		Labels  map[string][]string `json:"labels"`
+	}
+
+	convert := func(types ...interface{}) string {
+		c := NewConverter(nil)
+		for _, typ := range types {
+			c.AddType(typ)
+		}
+		return c.Export()
+	}
+	expected := convert(Team{}, User{}, Address{})
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, convert(Team{}, User{}, Address{}))
+	}
+
+	// the registration order only changes the order of the schemas
+	schemas := func(output string) []string {
+		blocks := strings.Split(strings.TrimSpace(output), "\n\n")
+		sort.Strings(blocks)
+		return blocks
+	}
+	assert.Equal(t, schemas(expected), schemas(convert(Address{}, User{}, Team{})))
+	assert.Equal(t, schemas(expected), schemas(convert(User{}, Address{}, Team{})))
+}
+
+type TestEmbeddedRecursiveItemA struct {
+	ID       int                          `json:"id"`
+	Children []TestEmbeddedRecursiveItemA `json:"children"`
+}
+
+type TestEmbeddedRecursiveItemB struct {
+	TestEmbeddedRecursiveItemA
+	Name string `json:"name"`
+}
+
+func TestEmbeddedRecursive(t *testing.T) {
+	expected := `export type TestEmbeddedRecursiveItemA = {
+  id: number,
+  children: TestEmbeddedRecursiveItemA[] | null,
+}
+export const TestEmbeddedRecursiveItemASchemaShape = {
+  id: z.number().int(),
+  children: z.lazy(() => TestEmbeddedRecursiveItemASchema).array().nullable(),
+}
+export const TestEmbeddedRecursiveItemASchema: z.ZodType<TestEmbeddedRecursiveItemA> = z.object(TestEmbeddedRecursiveItemASchemaShape)
+
+export const TestEmbeddedRecursiveItemBSchema = z.object({
+  ...TestEmbeddedRecursiveItemASchemaShape,
+  name: z.string(),
+})
+export type TestEmbeddedRecursiveItemB = z.infer<typeof TestEmbeddedRecursiveItemBSchema>
+
+`
+
+	// embedded type converted first
+	c := NewConverter(nil)
+	c.AddType(TestEmbeddedRecursiveItemA{})
+	c.AddType(TestEmbeddedRecursiveItemB{})
+	assert.Equal(t, expected, c.Export())
+
+	// embedded type converted while converting the embedding type
+	c = NewConverter(nil)
+	c.AddType(TestEmbeddedRecursiveItemB{})
+	c.AddType(TestEmbeddedRecursiveItemA{})
+	assert.Equal(t, expected, c.Export())
+}
+
+type TestEmbeddedCyclicParent struct {
+	ID       int                       `json:"id"`
+	Children []TestEmbeddedCyclicChild `json:"children"`
+}
+
+type TestEmbeddedCyclicChild struct {
+	TestEmbeddedCyclicParent
+	Name string `json:"name"`
+}
+
+func TestEmbeddedCyclic(t *testing.T) {
+	// the child embeds the parent while the parent is still being converted,
+	// so the parent fields are inlined
+	assert.Equal(t, `export type TestEmbeddedCyclicChild = {
+  name: string,
+} & TestEmbeddedCyclicParent
+export const TestEmbeddedCyclicChildSchemaShape = {
+  id: z.number().int(),
+  children: z.lazy(() => TestEmbeddedCyclicChildSchema).array().nullable(),
+  name: z.string(),
+}
+export const TestEmbeddedCyclicChildSchema: z.ZodType<TestEmbeddedCyclicChild> = z.object(TestEmbeddedCyclicChildSchemaShape)
+
+export type TestEmbeddedCyclicParent = {
+  id: number,
+  children: TestEmbeddedCyclicChild[] | null,
+}
+export const TestEmbeddedCyclicParentSchemaShape = {
+  id: z.number().int(),
+  children: TestEmbeddedCyclicChildSchema.array().nullable(),
+}
+export const TestEmbeddedCyclicParentSchema: z.ZodType<TestEmbeddedCyclicParent> = z.object(TestEmbeddedCyclicParentSchemaShape)
+
+`, StructToZodSchema(TestEmbeddedCyclicParent{}))
+
+	// the parent is converted while converting the child, so its shape is spread
+	assert.Equal(t, `export type TestEmbeddedCyclicParent = {
+  id: number,
+  children: TestEmbeddedCyclicChild[] | null,
+}
+export const TestEmbeddedCyclicParentSchemaShape = {
+  id: z.number().int(),
+  children: z.lazy(() => TestEmbeddedCyclicChildSchema).array().nullable(),
+}
+export const TestEmbeddedCyclicParentSchema: z.ZodType<TestEmbeddedCyclicParent> = z.object(TestEmbeddedCyclicParentSchemaShape)
+
+export type TestEmbeddedCyclicChild = {
+  name: string,
+} & TestEmbeddedCyclicParent
+export const TestEmbeddedCyclicChildSchemaShape = {
+  ...TestEmbeddedCyclicParentSchemaShape,
+  name: z.string(),
+}
+export const TestEmbeddedCyclicChildSchema: z.ZodType<TestEmbeddedCyclicChild> = z.object(TestEmbeddedCyclicChildSchemaShape)
+
+`, StructToZodSchema(TestEmbeddedCyclicChild{}))
+}
+
+func TestEmbeddedPointer(t *testing.T) {
+	type Audit struct {
+		By string `json:"by" validate:"required"`
+	}
+	type Base struct {
+		ID   string  `json:"id"`
+		Note *string `json:"note"`
+		Audit
+	}
+	type User struct {
+		*Base
+		Name string `json:"name"`
+	}
+
+	// encoding/json promotes the fields of embedded pointers only when they
+	// are not nil
+	c := NewConverterWithOpts()
+	assert.Equal(t, `export const UserSchema = z.object({
+  by: z.string().min(1).optional(),
+  id: z.string().optional(),
+  note: z.string().optional().nullable(),
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type User = {
+  id?: string | undefined,
+  note?: string | null | undefined,
+  by?: string | undefined,
+  name: string,
+}
+export const UserSchemaShape = {
+  by: z.string().min(1).optional(),
+  id: z.string().optional(),
+  note: z.string().optional().nullable(),
+  name: z.string(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+
+`, c.Convert(User{}))
+
+	// the promoted fields of a struct embedding itself are shadowed
+	type Node struct {
+		*Node
+		Value int `json:"value"`
+	}
+	c = NewConverterWithOpts()
+	assert.Equal(t, `export const NodeSchema = z.object({
+  value: z.number().int(),
+})
+export type Node = z.infer<typeof NodeSchema>
+
+`, c.Convert(Node{}))
+	kotlin, err := c.ExportKotlin("api")
+	assert.NoError(t, err)
+	assert.Contains(t, kotlin, `data class Node(
+    @SerialName("value") val value: Long,
+)`)
+}
+
+type TestEmbeddedPayload interface {
+	Kind() string
+}
+
+func TestEmbeddedInterface(t *testing.T) {
+	type Meta struct {
+		At string `json:"at"`
+	}
+	type Event struct {
+		TestEmbeddedPayload
+		Meta
+	}
+	type TaggedEvent struct {
+		TestEmbeddedPayload `json:"payload"`
+		ID                  int `json:"id"`
+	}
+
+	// encoding/json does not flatten embedded interfaces
+	assert.Equal(t, `export const MetaSchema = z.object({
+  at: z.string(),
+})
+export type Meta = z.infer<typeof MetaSchema>
+
+export const EventSchema = z.object({
+  ...MetaSchema.shape,
+  TestEmbeddedPayload: z.any(),
+})
+export type Event = z.infer<typeof EventSchema>
+
+`, StructToZodSchema(Event{}))
+
+	c := NewConverterWithOpts(WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.TestEmbeddedPayload": func(c *Converter, t reflect.Type, validate string, i int) string {
+			return "z.union([CreatedSchema, DeletedSchema])"
+		},
+	}))
+	assert.Equal(t, `export const TaggedEventSchema = z.object({
+  payload: z.union([CreatedSchema, DeletedSchema]),
+  id: z.number().int(),
+})
+export type TaggedEvent = z.infer<typeof TaggedEventSchema>
+
+`, c.Convert(TaggedEvent{}))
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type TaggedEvent = {
+  payload: any,
+  id: number,
+}
+export const TaggedEventSchemaShape = {
+  payload: z.any(),
+  id: z.number().int(),
+}
+export const TaggedEventSchema: z.ZodType<TaggedEvent> = z.object(TaggedEventSchemaShape)
+
+`, c.Convert(TaggedEvent{}))
+}
+
+func TestEmbeddedPrimitive(t *testing.T) {
+	type ID string
+	type Version int
+	type Labels []string
+	type Item struct {
+		ID
+		*Version
+		Labels `json:"labels"`
+		Name   string `json:"name" validate:"required"`
+	}
+
+	// encoding/json does not flatten embedded non-structs
+	assert.Equal(t, `export const ItemSchema = z.object({
+  ID: z.string(),
+  Version: z.number().int().nullable(),
+  labels: z.string().array().nullable(),
+  name: z.string().min(1),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+`, StructToZodSchema(Item{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Equal(t, `export type Item = {
+  ID: string,
+  Version: number | null,
+  labels: string[] | null,
+  name: string,
+}
+export const ItemSchemaShape = {
+  ID: z.string(),
+  Version: z.number().int().nullable(),
+  labels: z.string().array().nullable(),
+  name: z.string().min(1),
+}
+export const ItemSchema: z.ZodType<Item> = z.object(ItemSchemaShape)
+
+`, c.Convert(Item{}))
+}
+
+func TestExplicitTypes(t *testing.T) {
+	type Decimal struct {
+		Value int
+	}
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Post struct {
+		Title string `json:"title" validate:"required"`
+	}
+	type User struct {
+		Base
+		Name    string            `json:"name"`
+		Email   *string           `json:"email,omitempty"`
+		Posts   []Post            `json:"posts"`
+		Labels  map[string]string `json:"labels"`
+		Created time.Time         `json:"created"`
+		Balance Decimal           `json:"balance"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.Decimal": func(c *Converter, t reflect.Type, validate string, i int) string {
+			return "z.string()"
+		},
+	}))
+	assert.Equal(t, `export type Base = {
+  id: string,
+}
+export const BaseSchemaShape = {
+  id: z.string(),
+}
+export const BaseSchema: z.ZodType<Base> = z.object(BaseSchemaShape)
+
+export type Post = {
+  title: string,
+}
+export const PostSchemaShape = {
+  title: z.string().min(1),
+}
+export const PostSchema: z.ZodType<Post> = z.object(PostSchemaShape)
+
+export type User = {
+  name: string,
+  email?: string | undefined,
+  posts: Post[] | null,
+  labels: Record<string, string> | null,
+  created: Date,
+  balance?: unknown,
+} & Base
+export const UserSchemaShape = {
+  ...BaseSchemaShape,
+  name: z.string(),
+  email: z.string().optional(),
+  posts: PostSchema.array().nullable(),
+  labels: z.record(z.string(), z.string()).nullable(),
+  created: z.coerce.date(),
+  balance: z.string(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+
+`, c.Convert(User{}))
+}
+
+func TestOmitEmptyZeroValues(t *testing.T) {
+	type User struct {
+		IntOptional  int    `json:",omitempty"`
+		Int1         int    `validate:"min=2,max=5"`
+		Int2         int    `json:",omitempty" validate:"min=2,max=5"`
+		IntNullable1 int    `validate:"omitempty,min=2,max=5"`
+		IntNullable2 int    `json:",omitempty" validate:"omitempty,min=2,max=5"`
+		IntOmitEmpty int    `validate:"omitempty"`
+		PtrInt       *int   `validate:"omitempty,min=2,max=5"`
+		String1      string `validate:"min=2,max=5"`
+		String2      string `json:",omitempty" validate:"omitempty,min=2,max=5"`
+		Enum         string `validate:"omitempty,oneof=a b"`
+	}
+
+	expected := `export const UserSchema = z.object({
+  IntOptional: z.number().int().optional(),
+  Int1: z.number().int().gte(2).lte(5),
+  Int2: z.number().int().gte(2).lte(5),
+  IntNullable1: z.number().int().gte(2).lte(5).or(z.literal(0)),
+  IntNullable2: z.number().int().gte(2).lte(5).or(z.literal(0)).optional(),
+  IntOmitEmpty: z.number().int(),
+  PtrInt: z.number().int().gte(2).lte(5).nullable(),
+  String1: z.string().min(2).max(5),
+  String2: z.string().min(2).max(5).or(z.literal("")).optional(),
+  Enum: z.enum(["a", "b"] as const).or(z.literal("")),
+})
+export type User = z.infer<typeof UserSchema>
+
+`
+	c := NewConverterWithOpts(WithOmitEmptyZeroValues())
+	assert.Equal(t, expected, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithOmitEmptyZeroValues(), WithExplicitTypes())
+	assert.Contains(t, c.Convert(User{}), `export type User = {
+  IntOptional?: number | undefined,
+  Int1: number,
+  Int2: number,
+  IntNullable1: number,
+  IntNullable2?: number | undefined,
+  IntOmitEmpty: number,
+  PtrInt: number | null,
+  String1: string,
+  String2?: string | undefined,
+  Enum: string,
+}`)
+}
+
+func TestStructOnly(t *testing.T) {
+	type Inner struct {
+		Name string `json:"name" validate:"required,min=2"`
+	}
+	type Outer struct {
+		Plain    Inner            `json:"plain" validate:"structonly"`
+		Pointer  *Inner           `json:"pointer" validate:"required,nostructlevel"`
+		Optional *Inner           `json:"optional" validate:"nostructlevel,required"`
+		Slice    []Inner          `json:"slice" validate:"required,structonly,min=1"`
+		Map      map[string]Inner `json:"map" validate:"structonly,dive,required"`
+		Elems    []*Inner         `json:"elems" validate:"dive,required,structonly"`
+	}
+
+	assert.Equal(t, `export const InnerSchema = z.object({
+  name: z.string().min(1).min(2),
+})
+export type Inner = z.infer<typeof InnerSchema>
+
+export const OuterSchema = z.object({
+  plain: InnerSchema,
+  pointer: InnerSchema,
+  optional: InnerSchema.nullable(),
+  slice: InnerSchema.array(),
+  map: z.record(z.string(), InnerSchema).nullable(),
+  elems: InnerSchema.array().nullable(),
+})
+export type Outer = z.infer<typeof OuterSchema>
+
+`, StructToZodSchema(Outer{}))
+}
+
+func TestTagSet(t *testing.T) {
+	type Payment struct {
+		Card   string `json:"card" validate:"required,credit_card"`
+		Code   string `json:"code" validate:"luhn_checksum,max=30"`
+		Number int    `json:"number" validate:"luhn_checksum,gte=0"`
+		Mongo  string `json:"mongo" validate:"mongodb_connection_string"`
+		Object string `json:"object" validate:"spicedb"`
+		Perm   string `json:"perm" validate:"spicedb=permission"`
+		Kind   string `json:"kind" validate:"spicedb=type"`
+	}
+
+	luhn := "/^[0-9]%s$/.test(%s) && [...%s].reverse().reduce((sum, d, i) => sum + (i %% 2 ? [0, 2, 4, 6, 8, 1, 3, 5, 7, 9][d] : +d), 0) %% 10 === 0"
+	c := NewConverterWithOpts(WithTagSet(RecentTags))
+	assert.Equal(t, fmt.Sprintf(`export const PaymentSchema = z.object({
+  card: z.string().min(1).refine((val) => val.split(" ").every((s) => s.length >= 3) && %s),
+  code: z.string().max(30).refine((val) => %s),
+  number: z.number().int().gte(0).refine((val) => %s),
+  mongo: z.string().regex(/%s/),
+  object: z.string().regex(/%s/),
+  perm: z.string().regex(/%s/),
+  kind: z.string().regex(/%s/),
+})
+export type Payment = z.infer<typeof PaymentSchema>
+
+`,
+		fmt.Sprintf(luhn, "{12,19}", `val.replaceAll(" ", "")`, `val.replaceAll(" ", "")`),
+		fmt.Sprintf(luhn, "{2,}", "val", "val"),
+		fmt.Sprintf(luhn, "{2,}", "String(val)", "String(val)"),
+		mongodbConnStringRegexString, spicedbIDRegexString, spicedbPermissionRegexString, spicedbTypeRegexString),
+		c.Convert(Payment{}))
+
+	assert.PanicsWithValue(t, "unknown validation: credit_card", func() {
+		StructToZodSchema(Payment{})
+	})
+	assert.PanicsWithValue(t, "unknown validation: luhn_checksum", func() {
+		StructToZodSchema(struct {
+			Number int `validate:"luhn_checksum"`
+		}{})
+	})
+	assert.PanicsWithValue(t, "unknown validation: spicedb=type", func() {
+		StructToZodSchema(struct {
+			Kind string `validate:"spicedb=type"`
+		}{})
+	})
+	assert.PanicsWithValue(t, "invalid validation: spicedb=relation", func() {
+		c.Convert(struct {
+			Kind string `validate:"spicedb=relation"`
+		}{})
+	})
+}
+
+func TestValidatorTranslation(t *testing.T) {
+	type Product struct {
+		SKU    string   `json:"sku" validate:"sku=ean,max=20"`
+		Codes  []string `json:"codes" validate:"dive,sku"`
+		Stock  int      `json:"stock" validate:"sku"`
+		Active bool     `json:"active" validate:"sku"`
+	}
+
+	var seen []Validation
+	c := NewConverterWithOpts(WithValidatorTranslation("sku", func(v Validation) string {
+		seen = append(seen, v)
+		if v.Type.Kind() != reflect.String {
+			return ""
+		}
+		if v.Param == "ean" {
+			return ".regex(/^[0-9]{13}$/)"
+		}
+		return `.refine((val) => val.startsWith("SKU-"))`
+	}))
+	assert.Equal(t, `export const ProductSchema = z.object({
+  sku: z.string().max(20).regex(/^[0-9]{13}$/),
+  codes: z.string().refine((val) => val.startsWith("SKU-")).array().nullable(),
+  stock: z.number().int(),
+  active: z.boolean(),
+})
+export type Product = z.infer<typeof ProductSchema>
+
+`, c.Convert(Product{}))
+
+	parent := reflect.TypeOf(Product{})
+	assert.Equal(t, []Validation{
+		{Tag: "sku", Param: "ean", Type: reflect.TypeOf(""), Parent: parent, Field: parent.Field(0), Key: "sku", Schema: "ProductSchema"},
+		{Tag: "sku", Type: reflect.TypeOf(""), Parent: parent, Field: parent.Field(1), Key: "codes", Schema: "ProductSchema"},
+		{Tag: "sku", Type: reflect.TypeOf(0), Parent: parent, Field: parent.Field(2), Key: "stock", Schema: "ProductSchema"},
+		{Tag: "sku", Type: reflect.TypeOf(false), Parent: parent, Field: parent.Field(3), Key: "active", Schema: "ProductSchema"},
+	}, seen)
+
+	assert.PanicsWithValue(t, "unknown validation: sku=ean", func() {
+		StructToZodSchema(Product{})
+	})
+
+	type Dimensions struct {
+		Width  int `json:"width"`
+		Height int `json:"height"`
+	}
+	type Box struct {
+		Size   Dimensions     `json:"size" validate:"square"`
+		Sides  []int          `json:"sides" validate:"min=2,square,dive,gt=0"`
+		Corner [2]int         `json:"corner" validate:"square"`
+		Labels map[string]int `json:"labels" validate:"square"`
+	}
+
+	c = NewConverterWithOpts(WithValidatorTranslation("square", func(v Validation) string {
+		switch v.Type.Kind() {
+		case reflect.Struct:
+			return ".refine((val) => val.width === val.height)"
+		case reflect.Map:
+			return ".refine((val) => Object.keys(val).length % 2 === 0)"
+		}
+		return ".refine((val) => val.every((side) => side === val[0]))"
+	}))
+	assert.Equal(t, `export const DimensionsSchema = z.object({
+  width: z.number().int(),
+  height: z.number().int(),
+})
+export type Dimensions = z.infer<typeof DimensionsSchema>
+
+export const BoxSchema = z.object({
+  size: DimensionsSchema.refine((val) => val.width === val.height),
+  sides: z.number().int().gt(0).array().min(2).refine((val) => val.every((side) => side === val[0])),
+  corner: z.number().int().array().length(2).refine((val) => val.every((side) => side === val[0])),
+  labels: z.record(z.string(), z.number().int()).refine((val) => Object.keys(val).length % 2 === 0).nullable(),
+})
+export type Box = z.infer<typeof BoxSchema>
+
+`, c.Convert(Box{}))
+}
+
+func TestValidatorSemantics(t *testing.T) {
+	type Device struct {
+		Serial  string  `json:"serial" validate:"hexadecimal"`
+		Color   string  `json:"color" validate:"hexcolor"`
+		Query   string  `json:"query" validate:"url_encoded"`
+		Phone   string  `json:"phone" validate:"e164"`
+		ID      string  `json:"id" validate:"uuid"`
+		Retries *int    `json:"retries" validate:"required"`
+		Name    *string `json:"name" validate:"required,max=10"`
+	}
+
+	c := NewConverterWithOpts(WithValidatorSemantics("v8.18.2"))
+	assert.Equal(t, `export const DeviceSchema = z.object({
+  serial: z.string().regex(/^[0-9a-fA-F]+$/),
+  color: z.string().regex(/^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$/),
+  query: z.string().regex(/(%[A-Fa-f0-9]{2})/),
+  phone: z.string().regex(/^\+[1-9]?[0-9]{7,14}$/),
+  id: z.string().regex(/^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/),
+  retries: z.number().int().refine((val) => val !== 0),
+  name: z.string().min(1).max(10),
+})
+export type Device = z.infer<typeof DeviceSchema>
+
+`, c.Convert(Device{}))
+
+	c = NewConverterWithOpts(WithValidatorSemantics("v10.30.5"))
+	assert.Equal(t, `export const DeviceSchema = z.object({
+  serial: z.string().regex(/^(0[xX])?[0-9a-fA-F]+$/),
+  color: z.string().regex(/^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$/),
+  query: z.string().regex(/^(?:[^%]|%[0-9A-Fa-f]{2})*$/),
+  phone: z.string().regex(/^\+?[1-9]\d{7,14}$/),
+  id: z.string().regex(/^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$/),
+  retries: z.number().int(),
+  name: z.string().max(10),
+})
+export type Device = z.infer<typeof DeviceSchema>
+
+`, c.Convert(Device{}))
+
+	type Contact struct {
+		Phone string `json:"phone" validate:"e164"`
+	}
+	contact := `export const ContactSchema = z.object({
+  phone: z.string().regex(/%s/),
+})
+export type Contact = z.infer<typeof ContactSchema>
+
+`
+	c = NewConverterWithOpts()
+	assert.Equal(t, fmt.Sprintf(contact, e164RegexString), c.Convert(Contact{}))
+	c = NewConverterWithOpts(WithValidatorSemantics("v10.29.0"))
+	assert.Equal(t, fmt.Sprintf(contact, e164ShortRegexString), c.Convert(Contact{}))
+	c = NewConverterWithOpts(WithValidatorSemantics("v9.31.0+incompatible"))
+	assert.Equal(t, fmt.Sprintf(contact, e164RegexString), c.Convert(Contact{}))
+
+	assert.PanicsWithValue(t, "invalid validator version: v10.x", func() {
+		NewConverterWithOpts(WithValidatorSemantics("v10.x"))
+	})
+}
+
+func TestSharedRegexes(t *testing.T) {
+	type Account struct {
+		ID     string `json:"id" validate:"uuid"`
+		Handle string `json:"handle" validate:"alpha|uuid"`
+		Region string `json:"region" validate:"iso3166_2"`
+	}
+	type Team struct {
+		ID    string   `json:"id" validate:"uuid"`
+		Name  string   `json:"name" validate:"alpha,max=20"`
+		Owner *Account `json:"owner"`
+	}
+
+	c := NewConverterWithOpts(WithSharedRegexes("./regexes"))
+	assert.Equal(t, `import { AlphaRegex, Iso31662Regex, UuidRegex } from "./regexes"
+
+export const AccountSchema = z.object({
+  id: z.string().regex(UuidRegex),
+  handle: z.string().refine((val) => z.string().regex(AlphaRegex).safeParse(val).success || z.string().regex(UuidRegex).safeParse(val).success),
+  region: z.string().regex(Iso31662Regex),
+})
+export type Account = z.infer<typeof AccountSchema>
+
+export const TeamSchema = z.object({
+  id: z.string().regex(UuidRegex),
+  name: z.string().regex(AlphaRegex).max(20),
+  owner: AccountSchema.nullable(),
+})
+export type Team = z.infer<typeof TeamSchema>
+
+`, c.Convert(Team{}))
+	assert.Equal(t, `export const AlphaRegex = /^[a-zA-Z]+$/
+export const Iso31662Regex = /^[A-Z]{2}-[A-Z0-9]{1,3}$/
+export const UuidRegex = /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/
+`, c.ExportRegexes())
+
+	c = NewConverterWithOpts(WithSharedRegexes("./regexes"))
+	type Plain struct {
+		Name string `json:"name" validate:"max=20"`
+	}
+	assert.Equal(t, `export const PlainSchema = z.object({
+  name: z.string().max(20),
+})
+export type Plain = z.infer<typeof PlainSchema>
+
+`, c.Convert(Plain{}))
+	assert.Equal(t, "", c.ExportRegexes())
+}
+
+func TestSharedRefinements(t *testing.T) {
+	type Ledger struct {
+		Accounts map[string]int `json:"accounts" validate:"required,lt=10"`
+		Totals   map[string]int `json:"totals" validate:"min=2,ne=3"`
+		Card     string         `json:"card" validate:"credit_card"`
+		Code     int            `json:"code" validate:"luhn_checksum"`
+	}
+
+	c := NewConverterWithOpts(WithSharedRefinements(), WithTagSet(RecentTags))
+	assert.Equal(t, `const luhn = (val: string, digits: RegExp) => digits.test(val) && [...val].reverse().reduce((sum, d, i) => sum + (i % 2 ? [0, 2, 4, 6, 8, 1, 3, 5, 7, 9][+d] : +d), 0) % 10 === 0
+const mapSize = (min: number, max: number) => (val: object) => Object.keys(val).length >= min && Object.keys(val).length <= max
+
+export const LedgerSchema = z.object({
+  accounts: z.record(z.string(), z.number().int()).refine(mapSize(1, Infinity), 'Empty map').refine(mapSize(0, 9), 'Map too large'),
+  totals: z.record(z.string(), z.number().int()).refine(mapSize(2, Infinity), 'Map too small').refine((val) => Object.keys(val).length !== 3, 'Map wrong size'),
+  card: z.string().refine((val) => val.split(" ").every((s) => s.length >= 3) && luhn(val.replaceAll(" ", ""), /^[0-9]{12,19}$/)),
+  code: z.number().int().refine((val) => luhn(String(val), /^[0-9]{2,}$/)),
+})
+export type Ledger = z.infer<typeof LedgerSchema>
+
+`, c.Convert(Ledger{}))
+
+	type Plain struct {
+		Name string `json:"name"`
+	}
+	c = NewConverterWithOpts(WithSharedRefinements())
+	assert.Equal(t, `export const PlainSchema = z.object({
+  name: z.string(),
+})
+export type Plain = z.infer<typeof PlainSchema>
+
+`, c.Convert(Plain{}))
+}
+
+func TestDefaults(t *testing.T) {
+	type Config struct {
+		Host    string  `json:"host" default:"localhost"`
+		Port    uint16  `json:"port" validate:"gte=1" default:"8080"`
+		Ratio   float64 `json:"ratio" default:"0.5"`
+		Debug   *bool   `json:"debug" default:"true"`
+		Mode    string  `json:"mode" validate:"oneof=dev prod" default:"dev"`
+		Timeout int64   `json:"timeout,omitempty" default:"30"`
+	}
+
+	assert.Equal(t, `export const ConfigSchema = z.object({
+  host: z.string().default("localhost"),
+  port: z.number().int().nonnegative().gte(1).default(8080),
+  ratio: z.number().default(0.5),
+  debug: z.boolean().nullable().default(true),
+  mode: z.enum(["dev", "prod"] as const).default("dev"),
+  timeout: z.number().int().optional().default(30),
+})
+export type Config = z.infer<typeof ConfigSchema>
+
+`, StructToZodSchema(Config{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithAudit(), WithInt64AsBigInt())
+	assert.Equal(t, `export type Config = {
+  host?: string | undefined,
+  port?: number | undefined,
+  ratio?: number | undefined,
+  debug?: boolean | null | undefined,
+  mode?: string | undefined,
+  timeout?: bigint | undefined,
+}
+export const ConfigSchemaShape = {
+  host: z.string().default("localhost"),
+  port: z.number().int().nonnegative().gte(1).default(8080),
+  ratio: z.number().default(0.5),
+  debug: z.boolean().nullable().default(true),
+  mode: z.enum(["dev", "prod"] as const).default("dev"),
+  timeout: z.bigint().optional().default(30n),
+}
+export const ConfigSchema: z.ZodType<Config> = z.object(ConfigSchemaShape)
+
+`, c.Convert(Config{}))
+
+	assert.PanicsWithValue(t, "default staging of Mode is not one of dev prod", func() {
+		StructToZodSchema(struct {
+			Mode string `validate:"oneof=dev prod" default:"staging"`
+		}{})
+	})
+	assert.PanicsWithValue(t, `invalid default of Port: strconv.ParseUint: parsing "-1": invalid syntax`, func() {
+		StructToZodSchema(struct {
+			Port uint16 `default:"-1"`
+		}{})
+	})
+	assert.PanicsWithValue(t, "cannot handle default of Hosts: []string", func() {
+		StructToZodSchema(struct {
+			Hosts []string `default:"localhost"`
+		}{})
+	})
+}
+
+func TestExportTypes(t *testing.T) {
+	type Address struct {
+		City string `json:"city" validate:"alpha"`
+	}
+	type User struct {
+		ID      string  `json:"id" validate:"uuid"`
+		Address Address `json:"address"`
+	}
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+	type Order struct {
+		Buyer *User  `json:"buyer"`
+		Items []Item `json:"items"`
+	}
+	type Tag struct {
+		Name string `json:"name" validate:"alpha"`
+	}
+
+	c := NewConverterWithOpts(WithSharedRegexes("./regexes"))
+	c.AddType(Order{})
+	c.AddType(Tag{})
+	assert.Equal(t, `import { AlphaRegex, UuidRegex } from "./regexes"
+
+export const AddressSchema = z.object({
+  city: z.string().regex(AlphaRegex),
+})
+export type Address = z.infer<typeof AddressSchema>
+
+export const UserSchema = z.object({
+  id: z.string().regex(UuidRegex),
+  address: AddressSchema,
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.ExportTypes("User"))
+	assert.Equal(t, `import { AlphaRegex } from "./regexes"
+
+export const ItemSchema = z.object({
+  sku: z.string(),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+export const TagSchema = z.object({
+  name: z.string().regex(AlphaRegex),
+})
+export type Tag = z.infer<typeof TagSchema>
+
+`, c.ExportTypes("Tag", "Item"))
+	assert.Equal(t, c.Export(), c.ExportTypes("Order", "Tag"))
+
+	assert.PanicsWithValue(t, "unknown type: Customer", func() {
+		c.ExportTypes("Customer")
+	})
+}
+
+func TestDependenciesOf(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Address Address `json:"address"`
+	}
+	type Order struct {
+		Buyer  *User    `json:"buyer"`
+		Seller User     `json:"seller"`
+		Ships  *Address `json:"ships"`
+	}
+	type Node struct {
+		Children []Node `json:"children"`
+	}
+
+	c := NewConverterWithOpts()
+	c.AddType(Order{})
+	c.AddType(Node{})
+	assert.Equal(t, []string{"Address", "User"}, c.DependenciesOf("Order"))
+	assert.Equal(t, []string{"Address"}, c.DependenciesOf("User"))
+	assert.Equal(t, []string{}, c.DependenciesOf("Address"))
+	assert.Equal(t, []string{}, c.DependenciesOf("Node"))
+
+	assert.PanicsWithValue(t, "unknown type: Customer", func() {
+		c.DependenciesOf("Customer")
+	})
+}
+
+func TestTypeName(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	c := NewConverterWithOpts(WithPrefix("API"))
+	assert.Equal(t, "APIUser", c.TypeName(User{}))
+
+	c = NewConverterWithOpts(WithPackageQualifiedNames())
+	assert.Equal(t, "ZenUser", c.TypeName(User{}))
+
+	assert.PanicsWithValue(t, "input must be a struct", func() {
+		c.TypeName("user")
+	})
+}
+
+func TestAddTypeOptions(t *testing.T) {
+	type Address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type User struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+	type Team struct {
+		Members []User `json:"members"`
+	}
+
+	c := NewConverterWithOpts()
+	c.AddType(User{})
+	c.AddType(User{}, WithPrefix("Admin"), WithExplicitTypes())
+	c.AddType(Team{})
+	assert.Equal(t, `export const AddressSchema = z.object({
+  city: z.string().min(1),
+})
+export type Address = z.infer<typeof AddressSchema>
+
+export const UserSchema = z.object({
+  name: z.string(),
+  address: AddressSchema,
+})
+export type User = z.infer<typeof UserSchema>
+
+export type AdminAddress = {
+  city: string,
+}
+export const AdminAddressSchemaShape = {
+  city: z.string().min(1),
+}
+export const AdminAddressSchema: z.ZodType<AdminAddress> = z.object(AdminAddressSchemaShape)
+
+export type AdminUser = {
+  name: string,
+  address: AdminAddress,
+}
+export const AdminUserSchemaShape = {
+  name: z.string(),
+  address: AdminAddressSchema,
+}
+export const AdminUserSchema: z.ZodType<AdminUser> = z.object(AdminUserSchemaShape)
+
+export const TeamSchema = z.object({
+  members: UserSchema.array().nullable(),
+})
+export type Team = z.infer<typeof TeamSchema>
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithPrefix("API"))
+	assert.NoError(t, c.TryAddType(Address{}, WithPrefix("Admin")))
+	assert.Equal(t, `export const APIAdminAddressSchema = z.object({
+  city: z.string().min(1),
+})
+export type APIAdminAddress = z.infer<typeof APIAdminAddressSchema>
+
+`, c.Export())
+	assert.Equal(t, "APIAddress", c.TypeName(Address{}))
+}
+
+func TestAddTypeOptionsScope(t *testing.T) {
+	type A struct {
+		ID     string         `json:"id" validate:"uuid"`
+		Labels map[string]int `json:"labels" validate:"min=1"`
+	}
+	type B struct {
+		ID     string         `json:"id" validate:"uuid"`
+		Labels map[string]int `json:"labels" validate:"min=1"`
+	}
+
+	c := NewConverterWithOpts()
+	c.AddType(A{}, WithSharedRegexes("./regexes"), WithSharedRefinements())
+	c.AddType(B{})
+	assert.Equal(t, fmt.Sprintf(`import { UuidRegex } from "./regexes"
+
+const mapSize = (min: number, max: number) => (val: object) => Object.keys(val).length >= min && Object.keys(val).length <= max
+
+export const ASchema = z.object({
+  id: z.string().regex(UuidRegex),
+  labels: z.record(z.string(), z.number().int()).refine(mapSize(1, Infinity), 'Map too small'),
+})
+export type A = z.infer<typeof ASchema>
+
+export const BSchema = z.object({
+  id: z.string().regex(/%s/),
+  labels: z.record(z.string(), z.number().int()).refine((val) => Object.keys(val).length >= 1, 'Map too small'),
+})
+export type B = z.infer<typeof BSchema>
+
+`, uUIDRegexString), c.Export())
+	assert.Equal(t, "export const UuidRegex = /"+uUIDRegexString+"/\n", c.ExportRegexes())
+}
+
+func TestAddTypeOptionsExports(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type User struct {
+		Address Address `json:"address"`
+	}
+
+	c := NewConverterWithOpts()
+	c.AddType(User{}, WithPrefix("Admin"), WithInputOutputSchemas())
+	c.AddType(Address{})
+	schemas, err := c.OpenAPISchemas()
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/AdminAddressInput", schemas["AdminUserInput"].Properties["address"].Ref)
+	assert.Equal(t, "#/components/schemas/AdminAddressOutput", schemas["AdminUserOutput"].Properties["address"].Ref)
+	assert.Contains(t, schemas, "Address")
+
+	swift, err := c.ExportSwift()
+	require.NoError(t, err)
+	assert.Contains(t, swift, "struct AdminUserInput: Codable {\n    let address: AdminAddressInput\n}")
+	assert.Contains(t, swift, "struct Address: Codable {\n    let city: String\n}")
+
+	// exports leave the settings of the converter untouched
+	c.AddType(User{})
+	assert.Contains(t, c.Export(), "export const UserSchema = z.object({\n  address: AddressSchema,\n})")
+}
+
+func TestIntersect(t *testing.T) {
+	type AuditFields struct {
+		CreatedBy string `json:"createdBy"`
+	}
+	type User struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type Admin struct {
+		User
+		Level int `json:"level"`
+	}
+
+	c := NewConverterWithOpts()
+	c.Intersect(User{}, AuditFields{})
+	c.AddType(Admin{})
+	assert.Equal(t, `export const AuditFieldsSchema = z.object({
+  createdBy: z.string(),
+})
+export type AuditFields = z.infer<typeof AuditFieldsSchema>
+
+export const UserSchemaShape = {
+  name: z.string().min(1),
+}
+export const UserSchema = z.object(UserSchemaShape).and(AuditFieldsSchema)
+export type User = z.infer<typeof UserSchema>
+
+export const AdminSchemaShape = {
+  ...UserSchemaShape,
+  level: z.number().int(),
+}
+export const AdminSchema = z.object(AdminSchemaShape).and(AuditFieldsSchema)
+export type Admin = z.infer<typeof AdminSchema>
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithExplicitTypes())
+	c.Intersect(User{}, AuditFields{})
+	c.AddType(User{})
+	assert.Equal(t, `export type AuditFields = {
+  createdBy: string,
+}
+export const AuditFieldsSchemaShape = {
+  createdBy: z.string(),
+}
+export const AuditFieldsSchema: z.ZodType<AuditFields> = z.object(AuditFieldsSchemaShape)
+
+export type User = {
+  name: string,
+} & AuditFields
+export const UserSchemaShape = {
+  name: z.string().min(1),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape).and(AuditFieldsSchema)
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithAlwaysLazy())
+	c.Intersect(User{}, AuditFields{})
+	c.AddType(Admin{})
+	assert.Contains(t, c.Export(), `export const AdminSchema: z.ZodType<Admin> = z.lazy(() => z.object({
+  name: z.string().min(1),
+  level: z.number().int(),
+}).and(AuditFieldsSchema))`)
+
+	assert.PanicsWithValue(t, "User has already been converted", func() {
+		c.Intersect(User{}, AuditFields{})
+	})
+	assert.PanicsWithValue(t, "intersected types must be structs", func() {
+		c := NewConverterWithOpts()
+		c.Intersect(User{}, "audit")
+	})
+}
+
+func TestInputOutputSchemas(t *testing.T) {
+	type Profile struct {
+		Bio       string `json:"bio"`
+		UpdatedAt string `json:"updatedAt" zen:"readonly"`
+	}
+	type Account struct {
+		ID       int     `json:"id" zen:"readonly"`
+		Email    string  `json:"email" validate:"email"`
+		Password string  `json:"password" zen:"writeonly"`
+		Secret   string  `json:"-"`
+		Profile  Profile `json:"profile"`
+	}
+
+	c := NewConverterWithOpts(WithInputOutputSchemas())
+	assert.Equal(t, `export const ProfileInputSchema = z.object({
+  bio: z.string(),
+})
+export type ProfileInput = z.infer<typeof ProfileInputSchema>
+
+export const AccountInputSchema = z.object({
+  email: z.string().email(),
+  password: z.string(),
+  profile: ProfileInputSchema,
+})
+export type AccountInput = z.infer<typeof AccountInputSchema>
+
+export const ProfileOutputSchema = z.object({
+  bio: z.string(),
+  updatedAt: z.string(),
+})
+export type ProfileOutput = z.infer<typeof ProfileOutputSchema>
+
+export const AccountOutputSchema = z.object({
+  id: z.number().int(),
+  email: z.string().email(),
+  profile: ProfileOutputSchema,
+})
+export type AccountOutput = z.infer<typeof AccountOutputSchema>
+
+`, c.Convert(Account{}))
+
+	c = NewConverterWithOpts(WithInputOutputSchemas(), WithExplicitTypes(), WithAudit())
+	c.AddType(Account{})
+	assert.Equal(t, `export type ProfileInput = {
+  bio: string,
+}
+export const ProfileInputSchemaShape = {
+  bio: z.string(),
+}
+export const ProfileInputSchema: z.ZodType<ProfileInput> = z.object(ProfileInputSchemaShape)
+
+export type AccountInput = {
+  email: string,
+  password: string,
+  profile: ProfileInput,
+}
+export const AccountInputSchemaShape = {
+  email: z.string().email(),
+  password: z.string(),
+  profile: ProfileInputSchema,
+}
+export const AccountInputSchema: z.ZodType<AccountInput> = z.object(AccountInputSchemaShape)
+
+`, c.ExportTypes("AccountInput"))
+
+	c = NewConverterWithOpts()
+	assert.Equal(t, `export const ProfileSchema = z.object({
+  bio: z.string(),
+  updatedAt: z.string(),
+})
+export type Profile = z.infer<typeof ProfileSchema>
+
+`, c.Convert(Profile{}))
+}
+
+type TestMoney struct {
+	Cents int `json:"cents"`
+}
+
+func (m TestMoney) IsZero() bool {
+	return m.Cents == 0
+}
+
+func TestJSONOmitZero(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Order struct {
+		Note     *string           `json:"note,omitzero"`
+		Tags     *[]string         `json:"tags,omitzero"`
+		Items    []string          `json:"items,omitzero"`
+		Meta     map[string]string `json:"meta,omitzero"`
+		Address  Address           `json:"address,omitzero"`
+		Price    TestMoney         `json:"price,omitzero"`
+		Shipped  time.Time         `json:"shipped,omitzero"`
+		Grid     [2]int            `json:"grid,omitzero"`
+		Count    int               `json:"count,omitzero"`
+		Required Address           `json:"required,omitzero" validate:"required"`
+		Billing  Address           `json:"billing,omitempty"`
+		Cells    [2]int            `json:"cells,omitempty"`
+	}
+
+	c := NewConverterWithOpts()
+	assert.Equal(t, `export const AddressSchema = z.object({
+  city: z.string(),
+})
+export type Address = z.infer<typeof AddressSchema>
+
+export const TestMoneySchema = z.object({
+  cents: z.number().int(),
+})
+export type TestMoney = z.infer<typeof TestMoneySchema>
+
+export const OrderSchema = z.object({
+  note: z.string().optional(),
+  tags: z.string().array().optional().nullable(),
+  items: z.string().array().optional(),
+  meta: z.record(z.string(), z.string()).optional(),
+  address: AddressSchema.optional(),
+  price: TestMoneySchema.optional(),
+  shipped: z.coerce.date().optional(),
+  grid: z.number().int().array().length(2).optional(),
+  count: z.number().int().optional(),
+  required: AddressSchema,
+  billing: AddressSchema,
+  cells: z.number().int().array().length(2),
+})
+export type Order = z.infer<typeof OrderSchema>
+
+`, c.Convert(Order{}))
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Order{}), `export type Order = {
+  note?: string | undefined,
+  tags?: string[] | null | undefined,
+  items?: string[] | undefined,
+  meta?: Record<string, string> | undefined,
+  address?: Address | undefined,
+  price?: TestMoney | undefined,
+  shipped?: Date | undefined,
+  grid?: number[] | undefined,
+  count?: number | undefined,
+  required: Address,
+  billing: Address,
+  cells: number[],
+}`)
+}
+
+func TestCoercePrimitives(t *testing.T) {
+	type Query struct {
+		Page   int       `json:"page" validate:"omitempty,gte=1"`
+		Size   *uint8    `json:"size,omitempty" validate:"omitempty,oneof=10 20 50"`
+		Active bool      `json:"active" validate:"required"`
+		Q      string    `json:"q"`
+		IDs    []int     `json:"ids" validate:"dive,gte=1"`
+		Since  time.Time `json:"since"`
+		Big    int64     `json:"big"`
+	}
+
+	c := NewConverterWithOpts(WithCoercePrimitives())
+	assert.Equal(t, `export const QuerySchema = z.object({
+  page: z.coerce.number().int().gte(1),
+  size: z.coerce.number().int().nonnegative().refine((val) => [10, 20, 50].includes(val)).optional(),
+  active: z.preprocess((val) => (val === "true" ? true : val === "false" ? false : val), z.boolean()).refine((val) => val !== false),
+  q: z.string(),
+  ids: z.coerce.number().int().gte(1).array().nullable(),
+  since: z.coerce.date(),
+  big: z.coerce.number().int(),
+})
+export type Query = z.infer<typeof QuerySchema>
+
+`, c.Convert(Query{}))
+
+	c = NewConverterWithOpts(WithCoercePrimitives(), WithInt64AsBigInt(), WithTimeFormat(UnixNumber), WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Query{}), `export const QuerySchemaShape = {
+  page: z.coerce.number().int().gte(1),
+  size: z.coerce.number().int().nonnegative().refine((val) => [10, 20, 50].includes(val)).optional(),
+  active: z.preprocess((val) => (val === "true" ? true : val === "false" ? false : val), z.boolean()).refine((val) => val !== false),
+  q: z.string(),
+  ids: z.coerce.number().int().gte(1).array().nullable(),
+  since: z.coerce.number(),
+  big: z.coerce.bigint(),
+}`)
+}
+
+func TestMapOfSliceValidations(t *testing.T) {
+	type Item struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type Catalog struct {
+		Sized    map[string][]Item     `json:"sized" validate:"min=1,dive,keys,alphanum,endkeys,dive,required"`
+		Keys     map[string][]Item     `json:"keys" validate:"dive,keys,min=2,max=5,alphanum,endkeys"`
+		Values   map[string][]Item     `json:"values" validate:"dive,keys,oneof=a b,endkeys,min=2,dive"`
+		Elems    map[string][]*Item    `json:"elems" validate:"required,max=3,dive,keys,len=2,endkeys,required,max=4,dive,required"`
+		Pointers map[string]*[]Item    `json:"pointers" validate:"dive,keys,alphanum,endkeys,required,min=1"`
+		Nested   map[string][][]string `json:"nested" validate:"omitempty,min=1,dive,keys,alphanum,endkeys,min=1,dive,min=2,dive,email"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type Item = {
+  name: string,
+}
+export const ItemSchemaShape = {
+  name: z.string().min(1),
+}
+export const ItemSchema: z.ZodType<Item> = z.object(ItemSchemaShape)
+
+export type Catalog = {
+  sized: Record<string, Item[]>,
+  keys: Record<string, Item[]> | null,
+  values: Partial<Record<"a" | "b", Item[]>> | null,
+  elems: Record<string, Item[]>,
+  pointers: Record<string, Item[]> | null,
+  nested: Record<string, string[][]> | null,
+}
+export const CatalogSchemaShape = {
+  sized: z.record(z.string().regex(/^[a-zA-Z0-9]+$/), ItemSchema.array()).refine((val) => Object.keys(val).length >= 1, 'Map too small'),
+  keys: z.record(z.string().min(2).max(5).regex(/^[a-zA-Z0-9]+$/), ItemSchema.array()).nullable(),
+  values: z.record(z.enum(["a", "b"] as const), ItemSchema.array().min(2)).nullable(),
+  elems: z.record(z.string().length(2), ItemSchema.array().max(4)).refine((val) => Object.keys(val).length > 0, 'Empty map').refine((val) => Object.keys(val).length <= 3, 'Map too large'),
+  pointers: z.record(z.string().regex(/^[a-zA-Z0-9]+$/), ItemSchema.array().min(1)).nullable(),
+  nested: z.record(z.string().regex(/^[a-zA-Z0-9]+$/), z.string().email().array().min(2).array().min(1)).refine((val) => Object.keys(val).length >= 1, 'Map too small').nullable(),
+}
+export const CatalogSchema: z.ZodType<Catalog> = z.object(CatalogSchemaShape)
+
+`, c.Convert(Catalog{}))
+}
+
+func TestTranslationStage(t *testing.T) {
+	type Product struct {
+		SKU    string         `json:"sku" validate:"trim,prefix,lowercase,max=20,even"`
+		Price  int            `json:"price" validate:"trim,even,ne=3,prefix,gte=1"`
+		Codes  []string       `json:"codes" validate:"trim,ne=2,prefix,even,min=1"`
+		Labels map[string]int `json:"labels" validate:"trim,even,min=1"`
+		Flag   bool           `json:"flag" validate:"trim,required"`
+	}
+
+	opts := []Opt{
+		WithValidatorTranslation("trim", func(v Validation) string { return ".transform((val) => val)" }),
+		WithValidatorTranslation("prefix", func(v Validation) string { return ".prefix()" }),
+		WithValidatorTranslation("even", func(v Validation) string { return ".refine(even)" }),
+	}
+
+	c := NewConverterWithOpts(append(opts,
+		WithTranslationStage("trim", TransformStage),
+		WithTranslationStage("prefix", CheckStage),
+	)...)
+	assert.Equal(t, `export const ProductSchema = z.object({
+  sku: z.string().prefix().max(20).refine((val) => val === val.toLowerCase()).refine(even).transform((val) => val),
+  price: z.number().int().prefix().gte(1).refine(even).refine((val) => val !== 3).transform((val) => val),
+  codes: z.string().array().prefix().min(1).refine((val) => val.length !== 2).refine(even).transform((val) => val),
+  labels: z.record(z.string(), z.number().int()).refine(even).refine((val) => Object.keys(val).length >= 1, 'Map too small').transform((val) => val),
+  flag: z.boolean().refine((val) => val !== false).transform((val) => val),
+})
+export type Product = z.infer<typeof ProductSchema>
+
+`, c.Convert(Product{}))
+
+	c = NewConverterWithOpts(opts...)
+	assert.Equal(t, `export const ProductSchema = z.object({
+  sku: z.string().max(20).transform((val) => val).prefix().refine((val) => val === val.toLowerCase()).refine(even),
+  price: z.number().int().gte(1).transform((val) => val).refine(even).refine((val) => val !== 3).prefix(),
+  codes: z.string().array().min(1).refine((val) => val.length !== 2).transform((val) => val).prefix().refine(even),
+  labels: z.record(z.string(), z.number().int()).transform((val) => val).refine(even).refine((val) => Object.keys(val).length >= 1, 'Map too small'),
+  flag: z.boolean().transform((val) => val).refine((val) => val !== false),
+})
+export type Product = z.infer<typeof ProductSchema>
+
+`, c.Convert(Product{}))
+}
+
+func TestObjectValidatorTranslation(t *testing.T) {
+	type Credentials struct {
+		Password string `json:"password" validate:"min=8"`
+		Confirm  string `json:"confirm" validate:"eqfield=Password"`
+	}
+	type Signup struct {
+		Credentials
+		Email string `json:"email" validate:"email"`
+		Start int    `json:"start"`
+		End   *int   `json:"end" validate:"gtfield=Start"`
+	}
+	type Form struct {
+		Signup Signup `json:"signup"`
+		Range  struct {
+			Min int `json:"min"`
+			Max int `json:"max" validate:"gtfield=Min"`
+		} `json:"range"`
+	}
+
+	var seen []Validation
+	crossField := func(op string) TranslationFn {
+		return func(v Validation) string {
+			seen = append(seen, v)
+			other, _ := v.Parent.FieldByName(v.Param)
+			return fmt.Sprintf(".refine((val) => val.%s %s val.%s, { path: [%s] })",
+				v.Key, op, fieldName(other), strconv.Quote(v.Key))
+		}
+	}
+	opts := []Opt{
+		WithObjectValidatorTranslation("eqfield", crossField("===")),
+		WithObjectValidatorTranslation("gtfield", crossField(">")),
+	}
+
+	c := NewConverterWithOpts(opts...)
+	assert.Equal(t, `export const CredentialsSchemaShape = {
+  password: z.string().min(8),
+  confirm: z.string(),
+}
+export const CredentialsSchema = z.object(CredentialsSchemaShape).refine((val) => val.confirm === val.password, { path: ["confirm"] })
+export type Credentials = z.infer<typeof CredentialsSchema>
+
+export const SignupSchemaShape = {
+  ...CredentialsSchemaShape,
+  email: z.string().email(),
+  start: z.number().int(),
+  end: z.number().int().nullable(),
+}
+export const SignupSchema = z.object(SignupSchemaShape).refine((val) => val.confirm === val.password, { path: ["confirm"] }).refine((val) => val.end > val.start, { path: ["end"] })
+export type Signup = z.infer<typeof SignupSchema>
+
+export const FormSchema = z.object({
+  signup: SignupSchema,
+  range: z.object({
+    min: z.number().int(),
+    max: z.number().int(),
+  }).refine((val) => val.max > val.min, { path: ["max"] }),
+})
+export type Form = z.infer<typeof FormSchema>
+
+`, c.Convert(Form{}))
+
+	credentials, signup := reflect.TypeOf(Credentials{}), reflect.TypeOf(Signup{})
+	assert.Equal(t, Validation{
+		Tag: "eqfield", Param: "Password", Type: reflect.TypeOf(""), Parent: credentials,
+		Field: credentials.Field(1), Key: "confirm", Schema: "CredentialsSchema",
+	}, seen[0])
+	assert.Equal(t, Validation{
+		Tag: "gtfield", Param: "Start", Type: reflect.TypeOf((*int)(nil)), Parent: signup,
+		Field: signup.Field(3), Key: "end", Schema: "SignupSchema",
+	}, seen[1])
+	assert.Equal(t, "", seen[2].Schema)
+
+	c = NewConverterWithOpts(append(opts, WithExplicitTypes(), WithAudit())...)
+	assert.Contains(t, c.Convert(Form{}), `export const SignupSchema: z.ZodType<Signup> = z.object(SignupSchemaShape).refine((val) => val.confirm === val.password, { path: ["confirm"] }).refine((val) => val.end > val.start, { path: ["end"] })`)
+
+	c = NewConverterWithOpts(append(opts, WithDerivedSchemas(Derivation{Suffix: "Update", Partial: true}))...)
+	assert.Contains(t, c.Convert(Signup{}), `export const SignupUpdateSchema = z.object(SignupSchemaShape).partial()`)
+
+	assert.PanicsWithValue(t, "unknown validation: eqfield=Password", func() {
+		StructToZodSchema(Credentials{})
+	})
+}
+
+func TestIgnoredTagComments(t *testing.T) {
+	type Link struct {
+		URL   string   `json:"url" validate:"required,uri"`
+		Title string   `json:"title" validate:"min=3,max=50,alphanum"`
+		Tags  []string `json:"tags" validate:"dive,uri,max=10"`
+		Notes string   `json:"notes"`
+	}
+
+	c := NewConverterWithOpts(WithIgnoreTags("uri", "max=50", "alphanum"), WithIgnoredTagComments())
+	assert.Equal(t, `export const LinkSchema = z.object({
+  url: z.string().min(1), // zen: ignored validation "uri"
+  title: z.string().min(3), // zen: ignored validations "max=50", "alphanum"
+  tags: z.string().max(10).array().nullable(), // zen: ignored validation "uri"
+  notes: z.string(),
+})
+export type Link = z.infer<typeof LinkSchema>
+
+`, c.Convert(Link{}))
+
+	// The comments are left out of the audit.
+	c = NewConverterWithOpts(WithIgnoreTags("uri"), WithIgnoredTagComments(), WithExplicitTypes(), WithAudit())
+	assert.NoError(t, c.TryAddType(Link{}))
+}
+
+func TestNameTag(t *testing.T) {
+	type Filter struct {
+		Page    int      `form:"page" json:"p" validate:"omitempty,gte=1"`
+		Sort    string   `form:"sort,default=name" validate:"omitempty,oneof=name date"`
+		Tags    []string `form:"tag"`
+		Debug   bool     `form:"-"`
+		Cursor  string
+		Renamed string `form:"x" zen:"name=y"`
+	}
+
+	c := NewConverterWithOpts(WithNameTag("form"), WithCoercePrimitives())
+	assert.Equal(t, `export const FilterSchema = z.object({
+  page: z.coerce.number().int().gte(1),
+  sort: z.enum(["name", "date"] as const),
+  tag: z.string().array().nullable(),
+  Cursor: z.string(),
+  y: z.string(),
+})
+export type Filter = z.infer<typeof FilterSchema>
+
+`, c.Convert(Filter{}))
+
+	c = NewConverterWithOpts(WithNameTag("form"), WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Filter{}), `export type Filter = {
+  page: number,
+  sort: string,
+  tag: string[] | null,
+  Cursor: string,
+  y: string,
+}`)
+
+	c = NewConverterWithOpts()
+	assert.Contains(t, c.Convert(Filter{}), `  p: z.number().int().gte(1),
+  Sort: z.enum(["name", "date"] as const),
+  Tags: z.string().array().nullable(),
+  Debug: z.boolean(),
+`)
+}
+
+func TestOrValidations(t *testing.T) {
+	type Contact struct {
+		Link  string            `json:"link" validate:"required,email|url,max=100"`
+		Kind  string            `json:"kind" validate:"oneof=a b|len=0"`
+		Code  string            `json:"code" validate:"min=3,oneof=abc def|startswith=x"`
+		Word  string            `json:"word" validate:"lowercase,max=5"`
+		Level int               `json:"level" validate:"gte=1,lte=5|eq=-1,lt=10"`
+		Tags  map[string]string `json:"tags" validate:"dive,keys,alpha|numeric,endkeys"`
+	}
+
+	assert.Equal(t, `export const ContactSchema = z.object({
+  link: z.string().min(1).max(100).refine((val) => z.string().email().safeParse(val).success || z.string().url().safeParse(val).success),
+  kind: z.string().refine((val) => ["a", "b"].includes(val) || z.string().length(0).safeParse(val).success),
+  code: z.string().min(3).refine((val) => ["abc", "def"].includes(val) || z.string().startsWith("x").safeParse(val).success),
+  word: z.string().max(5).refine((val) => val === val.toLowerCase()),
+  level: z.number().int().gte(1).lt(10).refine((val) => z.number().lte(5).safeParse(val).success || z.number().refine((val) => val === -1).safeParse(val).success),
+  tags: z.record(z.string().refine((val) => z.string().regex(/^[a-zA-Z]+$/).safeParse(val).success || z.string().regex(/^[-+]?[0-9]+(?:\.[0-9]+)?$/).safeParse(val).success), z.string()).nullable(),
+})
+export type Contact = z.infer<typeof ContactSchema>
+
+`, StructToZodSchema(Contact{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(Contact{}), "  kind: string,\n")
+
+	assert.PanicsWithValue(t, "invalid validation: email|", func() {
+		StructToZodSchema(struct {
+			Link string `validate:"email|"`
+		}{})
+	})
+}
+
+func TestTagAliases(t *testing.T) {
+	type User struct {
+		Name  string   `json:"name" validate:"name"`
+		Email *string  `json:"email" validate:"contact"`
+		Tags  []string `json:"tags" validate:"dive,tag"`
+	}
+
+	c := NewConverterWithOpts(WithTagAliases(map[string]string{
+		"name":    "required,min=2,max=50",
+		"contact": "required,email",
+		"tag":     "name",
+	}))
+	assert.Equal(t, `export const UserSchema = z.object({
+  name: z.string().min(1).min(2).max(50),
+  email: z.string().email(),
+  tags: z.string().min(1).min(2).max(50).array().nullable(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	assert.PanicsWithValue(t, "unknown validation: name", func() {
+		StructToZodSchema(User{})
+	})
+}
+
+func TestOmitNilAndOmitZero(t *testing.T) {
+	type Profile struct {
+		Nick   string            `json:"nick,omitempty" validate:"omitzero,min=2"`
+		Bio    string            `json:"bio,omitempty" validate:"omitnil,min=2"`
+		Age    *int              `json:"age" validate:"omitnil,gte=18"`
+		Score  int               `validate:"omitzero,gte=1"`
+		Tags   []string          `validate:"omitnil,min=1,dive,omitzero,min=2"`
+		Labels map[string]string `validate:"omitzero,min=1"`
+	}
+
+	assert.Equal(t, `export const ProfileSchema = z.object({
+  nick: z.string().min(2).optional(),
+  bio: z.string().min(2),
+  age: z.number().int().gte(18).nullable(),
+  Score: z.number().int().gte(1),
+  Tags: z.string().min(2).array().min(1).nullable(),
+  Labels: z.record(z.string(), z.string()).refine((val) => Object.keys(val).length >= 1, 'Map too small').nullable(),
+})
+export type Profile = z.infer<typeof ProfileSchema>
+
+`, StructToZodSchema(Profile{}))
+
+	c := NewConverterWithOpts(WithOmitEmptyZeroValues())
+	assert.Equal(t, `export const ProfileSchema = z.object({
+  nick: z.string().min(2).or(z.literal("")).optional(),
+  bio: z.string().min(2),
+  age: z.number().int().gte(18).nullable(),
+  Score: z.number().int().gte(1).or(z.literal(0)),
+  Tags: z.string().min(2).array().min(1).nullable(),
+  Labels: z.record(z.string(), z.string()).refine((val) => Object.keys(val).length >= 1, 'Map too small').nullable(),
+})
+export type Profile = z.infer<typeof ProfileSchema>
+
+`, c.Convert(Profile{}))
+}
+
+func TestExportShapes(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type User struct {
+		Base
+		Name string `json:"name"`
+	}
+
+	c := NewConverterWithOpts(WithExportShapes())
+	assert.Equal(t, `export const BaseSchemaShape = {
+  id: z.string(),
+}
+export const BaseSchema = z.object(BaseSchemaShape)
+export type Base = z.infer<typeof BaseSchema>
+
+export const UserSchemaShape = {
+  ...BaseSchemaShape,
+  name: z.string(),
+}
+export const UserSchema = z.object(UserSchemaShape)
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+}
+
+func TestRequiredPresenceOnly(t *testing.T) {
+	type User struct {
+		Name     string  `validate:"required"`
+		Nickname string  `validate:"required,max=10"`
+		Age      int     `validate:"required"`
+		Score    *int    `validate:"required,gte=0"`
+		Bio      *string `validate:"required"`
+		Agreed   bool    `validate:"required"`
+	}
+
+	c := NewConverterWithOpts(WithRequiredPresenceOnly())
+	assert.Equal(t, `export const UserSchema = z.object({
+  Name: z.string(),
+  Nickname: z.string().max(10),
+  Age: z.number().int(),
+  Score: z.number().int().gte(0),
+  Bio: z.string(),
+  Agreed: z.boolean(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+}
+
+func TestRequiredPointers(t *testing.T) {
+	type User struct {
+		Name   *string   `validate:"required"`
+		Age    *int      `validate:"required,gte=0"`
+		Agreed *bool     `validate:"required"`
+		Nick   **string  `validate:"required,max=10"`
+		Tags   *[]string `validate:"required"`
+		Emails []*string `validate:"dive,required,email"`
+	}
+
+	assert.Equal(t, `export const UserSchema = z.object({
+  Name: z.string(),
+  Age: z.number().int().gte(0),
+  Agreed: z.boolean(),
+  Nick: z.string().max(10),
+  Tags: z.string().array(),
+  Emails: z.string().email().array().nullable(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, StructToZodSchema(User{}))
+}
+
+func TestMapOfStructsDive(t *testing.T) {
+	type Item struct {
+		Name string `validate:"required"`
+	}
+	type Bag struct {
+		Items       map[string]Item   `validate:"dive,required"`
+		PtrItems    map[string]*Item  `validate:"dive,required"`
+		MaybeItems  map[string]*Item  `validate:"min=1,dive"`
+		KeyedItems  map[string]*Item  `validate:"dive,keys,min=2,endkeys,required"`
+		NestedItems map[string][]Item `validate:"dive,dive,required"`
+	}
+
+	expected := `export const ItemSchema = z.object({
+  Name: z.string().min(1),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+export const BagSchema = z.object({
+  Items: z.record(z.string(), ItemSchema).nullable(),
+  PtrItems: z.record(z.string(), ItemSchema).nullable(),
+  MaybeItems: z.record(z.string(), ItemSchema.nullable()).refine((val) => Object.keys(val).length >= 1, 'Map too small'),
+  KeyedItems: z.record(z.string().min(2), ItemSchema).nullable(),
+  NestedItems: z.record(z.string(), ItemSchema.array()).nullable(),
+})
+export type Bag = z.infer<typeof BagSchema>
+
+`
+	assert.Equal(t, expected, StructToZodSchema(Bag{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(Bag{}), `export type Bag = {
+  Items: Record<string, Item> | null,
+  PtrItems: Record<string, Item> | null,
+  MaybeItems: Record<string, Item | null>,
+  KeyedItems: Record<string, Item> | null,
+  NestedItems: Record<string, Item[]> | null,
+}`)
+}
+
+func TestMultiLevelDive(t *testing.T) {
+	type Item struct {
+		Name string `validate:"required"`
+	}
+	type Bag struct {
+		Grid       [][]Item                    `validate:"dive,min=1,dive,required"`
+		Groups     map[string][]Item           `validate:"dive,min=2,dive,required"`
+		Tags       map[string][]string         `validate:"dive,min=1,dive,min=3"`
+		Nested     map[string]map[string]*Item `validate:"dive,min=1,dive,required"`
+		KeyedItems map[string]map[string]*Item `validate:"dive,keys,min=2,endkeys,min=1,dive,required"`
+		Loose      map[string]map[string]*Item `validate:"dive,min=1,dive"`
+	}
+
+	expected := `export const ItemSchema = z.object({
+  Name: z.string().min(1),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+export const BagSchema = z.object({
+  Grid: ItemSchema.array().min(1).array().nullable(),
+  Groups: z.record(z.string(), ItemSchema.array().min(2)).nullable(),
+  Tags: z.record(z.string(), z.string().min(3).array().min(1)).nullable(),
+  Nested: z.record(z.string(), z.record(z.string(), ItemSchema).refine((val) => Object.keys(val).length >= 1, 'Map too small')).nullable(),
+  KeyedItems: z.record(z.string().min(2), z.record(z.string(), ItemSchema).refine((val) => Object.keys(val).length >= 1, 'Map too small')).nullable(),
+  Loose: z.record(z.string(), z.record(z.string(), ItemSchema.nullable()).refine((val) => Object.keys(val).length >= 1, 'Map too small')).nullable(),
+})
+export type Bag = z.infer<typeof BagSchema>
+
+`
+	assert.Equal(t, expected, StructToZodSchema(Bag{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(Bag{}), `export type Bag = {
+  Grid: Item[][] | null,
+  Groups: Record<string, Item[]> | null,
+  Tags: Record<string, string[]> | null,
+  Nested: Record<string, Record<string, Item>> | null,
+  KeyedItems: Record<string, Record<string, Item>> | null,
+  Loose: Record<string, Record<string, Item | null>> | null,
+}`)
+}
+
+func TestFieldOverride(t *testing.T) {
+	type Event struct {
+		Name      string `json:"name"`
+		CreatedAt int64  `json:"created_at"`
+		Internal  string `json:"-"`
+	}
+	type Log struct {
+		CreatedAt int64 `json:"created_at"`
+	}
+
+	c := NewConverterWithOpts(
+		WithFieldOverride(func(structType reflect.Type, field reflect.StructField) (string, bool) {
+			if structType == reflect.TypeOf(Event{}) && field.Name == "CreatedAt" {
+				return "z.coerce.date()", true
+			}
+			return "", false
+		}),
+		WithFieldOverride(func(structType reflect.Type, field reflect.StructField) (string, bool) {
+			if field.Name == "CreatedAt" || field.Name == "Internal" {
+				return "z.number().int()", true
+			}
+			return "", false
+		}),
+	)
+	c.AddType(Event{})
+	c.AddType(Log{})
+	assert.Equal(t, `export const EventSchema = z.object({
+  name: z.string(),
+  created_at: z.coerce.date(),
+})
+export type Event = z.infer<typeof EventSchema>
+
+export const LogSchema = z.object({
+  created_at: z.number().int(),
+})
+export type Log = z.infer<typeof LogSchema>
+
+`, c.Export())
+
+	c = NewConverterWithOpts(
+		WithExplicitTypes(),
+		WithFieldOverride(func(structType reflect.Type, field reflect.StructField) (string, bool) {
+			return "z.coerce.date()", field.Name == "CreatedAt"
+		}),
+	)
+	assert.Contains(t, c.Convert(Log{}), `export type Log = {
+  created_at?: unknown,
+}`)
+}
+
+func TestSkipFields(t *testing.T) {
+	type Order struct {
+		ID int `json:"id"`
+	}
+	type Customer struct {
+		Name   string  `json:"name"`
+		Orders []Order `json:"orders" orm:"association"`
+		Notes  string  `json:"notes" orm:"internal"`
+	}
+
+	c := NewConverterWithOpts(
+		WithSkipFields(func(field reflect.StructField) bool {
+			return field.Tag.Get("orm") == "association"
+		}),
+		WithSkipFields(func(field reflect.StructField) bool {
+			return field.Tag.Get("orm") == "internal"
+		}),
+	)
+	assert.Equal(t, `export const CustomerSchema = z.object({
+  name: z.string(),
+})
+export type Customer = z.infer<typeof CustomerSchema>
+
+`, c.Convert(Customer{}))
+}
+
+func TestAlwaysLazy(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Post struct {
+		Title string `json:"title"`
+	}
+	type User struct {
+		Base
+		Name  string `json:"name"`
+		Posts []Post `json:"posts"`
+	}
+
+	c := NewConverterWithOpts(WithAlwaysLazy())
+	assert.Equal(t, `export type Base = {
+  id: string,
+}
+export const BaseSchema: z.ZodType<Base> = z.lazy(() => z.object({
+  id: z.string(),
+}))
+
+export type Post = {
+  title: string,
+}
+export const PostSchema: z.ZodType<Post> = z.lazy(() => z.object({
+  title: z.string(),
+}))
+
+export type User = {
+  name: string,
+  posts: Post[] | null,
+} & Base
+export const UserSchema: z.ZodType<User> = z.lazy(() => z.object({
+  id: z.string(),
+  name: z.string(),
+  posts: PostSchema.array().nullable(),
+}))
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithAlwaysLazy())
+	assert.Equal(t, `export type TestCyclicB = {
+  A: TestCyclicA | null,
+}
+export const TestCyclicBSchema: z.ZodType<TestCyclicB> = z.lazy(() => z.object({
+  A: z.lazy(() => TestCyclicASchema).nullable(),
+}))
+
+export type TestCyclicA = {
+  B: TestCyclicB | null,
+}
+export const TestCyclicASchema: z.ZodType<TestCyclicA> = z.lazy(() => z.object({
+  B: TestCyclicBSchema.nullable(),
+}))
+
+`, c.Convert(TestCyclicA{}))
+}
+
+func TestShapeSuffix(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type User struct {
+		Base
+		Name string `json:"name"`
+	}
+
+	c := NewConverterWithOpts(WithExportShapes(), WithShapeSuffix("Shape"))
+	assert.Equal(t, `export const BaseShape = {
+  id: z.string(),
+}
+export const BaseSchema = z.object(BaseShape)
+export type Base = z.infer<typeof BaseSchema>
+
+export const UserShape = {
+  ...BaseShape,
+  name: z.string(),
+}
+export const UserSchema = z.object(UserShape)
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	assert.PanicsWithValue(t, `invalid shape suffix: "Schema"`, func() {
+		WithShapeSuffix("Schema")
+	})
+}
+
+func TestRecursionStyle(t *testing.T) {
+	type Node struct {
+		Name     string `json:"name"`
+		Children []Node `json:"children"`
+	}
+	type Tree struct {
+		Node
+		Label string `json:"label"`
+	}
+
+	c := NewConverterWithOpts(WithRecursionStyle(InterfaceRecursion), WithAudit())
+	assert.Equal(t, `export interface Node {
+  name: string,
+  children: Node[] | null,
+}
+export const NodeSchema: z.ZodType<Node> = z.object({
+  name: z.string(),
+  children: z.lazy(() => NodeSchema).array().nullable(),
+})
+
+export const TreeSchema = z.object({
+  name: z.string(),
+  children: NodeSchema.array().nullable(),
+  label: z.string(),
+})
+export type Tree = z.infer<typeof TreeSchema>
+
+`, c.Convert(Tree{}))
+
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Category struct {
+		Base
+		Parent *Category `json:"parent"`
+	}
+	c = NewConverterWithOpts(WithRecursionStyle(InterfaceRecursion))
+	assert.Equal(t, `export const BaseSchema = z.object({
+  id: z.string(),
+})
+export type Base = z.infer<typeof BaseSchema>
+
+export interface Category extends Base {
+  parent: Category | null,
+}
+export const CategorySchema: z.ZodType<Category> = z.object({
+  ...BaseSchema.shape,
+  parent: z.lazy(() => CategorySchema).nullable(),
+})
+
+`, c.Convert(Category{}))
+
+	c = NewConverterWithOpts(WithRecursionStyle(GetterRecursion))
+	assert.Equal(t, `export const NodeSchema = z.object({
+  name: z.string(),
+  get children() {
+    return NodeSchema.array().nullable()
+  },
+})
+export type Node = z.infer<typeof NodeSchema>
+
+export const TreeSchema = z.object({
+  name: z.string(),
+  children: NodeSchema.array().nullable(),
+  label: z.string(),
+})
+export type Tree = z.infer<typeof TreeSchema>
+
+`, c.Convert(Tree{}))
+
+	c = NewConverterWithOpts(WithRecursionStyle(GetterRecursion))
+	assert.Equal(t, `export const TestCyclicBSchema = z.object({
+  get A() {
+    return TestCyclicASchema.nullable()
+  },
+})
+export type TestCyclicB = z.infer<typeof TestCyclicBSchema>
+
+export const TestCyclicASchema = z.object({
+  get B() {
+    return TestCyclicBSchema.nullable()
+  },
+})
+export type TestCyclicA = z.infer<typeof TestCyclicASchema>
+
+`, c.Convert(TestCyclicA{}))
+
+	c = NewConverterWithOpts(WithRecursionStyle(GetterRecursion), WithExplicitTypes())
+	assert.Equal(t, `export type Node = {
+  name: string,
+  children: Node[] | null,
+}
+export const NodeSchema: z.ZodType<Node> = z.object({
+  name: z.string(),
+  get children() {
+    return NodeSchema.array().nullable()
+  },
+})
+
+`, c.Convert(Node{}))
+}
+
+func TestZenTag(t *testing.T) {
+	type User struct {
+		ID       string            `json:"id" zen:"type=z.string().uuid()"`
+		Name     string            `json:"name" zen:"optional"`
+		Nickname string            `zen:"name=nick,nullable"`
+		Secret   string            `json:"secret" zen:"skip"`
+		Role     *string           `json:"role" zen:"type=z.enum(['admin', 'user'])"`
+		Meta     map[string]string `json:"meta" zen:"optional,type=z.record(z.string(), z.string())"`
+	}
+
+	assert.Equal(t, `export const UserSchema = z.object({
+  id: z.string().uuid(),
+  name: z.string().optional(),
+  nick: z.string().nullable(),
+  role: z.enum(['admin', 'user']).nullable(),
+  meta: z.record(z.string(), z.string()).optional().nullable(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, StructToZodSchema(User{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(User{}), `export type User = {
+  id?: unknown,
+  name?: string | undefined,
+  nick: string | null,
+  role?: unknown | null,
+  meta?: unknown | null | undefined,
+}`)
+}
+
+func TestZenTagInvalid(t *testing.T) {
+	type User struct {
+		Name string `zen:"unknown"`
+	}
+
+	assert.Panics(t, func() {
+		StructToZodSchema(User{})
+	})
+}
+
+func TestAudience(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+	}
+	type User struct {
+		Audit `zen-only:"admin"`
+		Name  string `json:"name"`
+		Email string `json:"email" zen-only:"admin,support"`
+		Notes string `json:"notes" zen-only:"admin" validate:"required"`
+	}
+
+	assert.Equal(t, `export const UserSchema = z.object({
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, StructToZodSchema(User{}))
+
+	support := NewConverterWithOpts(WithAudience("support"))
+	assert.Equal(t, `export const UserSchema = z.object({
+  name: z.string(),
+  email: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, support.Convert(User{}))
+
+	admin := NewConverterWithOpts(WithAudience("admin"))
+	assert.Equal(t, `export const AuditSchema = z.object({
+  created_by: z.string(),
+})
+export type Audit = z.infer<typeof AuditSchema>
+
+export const UserSchema = z.object({
+  ...AuditSchema.shape,
+  name: z.string(),
+  email: z.string(),
+  notes: z.string().min(1),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, admin.Convert(User{}))
+
+	explicit := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, explicit.Convert(User{}), `export type User = {
+  name: string,
+}`)
+}
+
+func TestSensitiveFields(t *testing.T) {
+	type Secrets struct {
+		Salt string `json:"salt"`
+	}
+	type User struct {
+		Secrets  `sensitive:"true"`
+		Name     string  `json:"name"`
+		Password string  `json:"password" sensitive:"true" validate:"required"`
+		Token    *string `json:"token,omitempty" sensitive:"true"`
+	}
+
+	assert.Equal(t, `export const SecretsSchema = z.object({
+  salt: z.string(),
+})
+export type Secrets = z.infer<typeof SecretsSchema>
+
+export const UserSchema = z.object({
+  ...SecretsSchema.shape,
+  name: z.string(),
+  password: z.string().min(1),
+  token: z.string().optional(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, StructToZodSchema(User{}))
+
+	omit := NewConverterWithOpts(WithSensitiveFields(SensitiveOmit))
+	assert.Equal(t, `export const UserSchema = z.object({
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, omit.Convert(User{}))
+
+	never := NewConverterWithOpts(WithSensitiveFields(SensitiveNever), WithExplicitTypes())
+	assert.Equal(t, `export type User = {
+  name: string,
+  password?: never,
+  token?: never,
+}
+export const UserSchemaShape = {
+  name: z.string(),
+  password: z.never().optional(),
+  token: z.never().optional(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+
+`, never.Convert(User{}))
+}
+
+type NotFoundError struct {
+	Resource string `json:"resource"`
+}
+
+func (e *NotFoundError) Error() string { return e.Resource + " not found" }
+
+type InvalidError struct {
+	Field string `json:"field"`
+}
+
+func (e InvalidError) Error() string { return e.Field + " is invalid" }
+
+func TestUnexportedFields(t *testing.T) {
+	type address struct {
+		City string `json:"city"`
+	}
+	type audit struct {
+		By string `json:"by"`
+	}
+	type User struct {
+		Name   string `json:"name"`
+		secret string
+		hidden string `json:"-"`
+		address
+		*audit
+	}
+
+	c := NewConverterWithOpts()
+	assert.Equal(t, `export const addressSchema = z.object({
+  city: z.string(),
+})
+export type address = z.infer<typeof addressSchema>
+
+export const UserSchema = z.object({
+  ...addressSchema.shape,
+  by: z.string().optional(),
+  name: z.string(),
+  secret: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithUnexportedFields(UnexportedSkip))
+	assert.Equal(t, `export const UserSchema = z.object({
+  city: z.string(),
+  by: z.string().optional(),
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithUnexportedFields(UnexportedSkip), WithExplicitTypes(), WithAudit())
+	assert.Equal(t, `export type User = {
+  name: string,
+  city: string,
+  by?: string | undefined,
+}
+export const UserSchemaShape = {
+  city: z.string(),
+  by: z.string().optional(),
+  name: z.string(),
+}
+export const UserSchema: z.ZodType<User> = z.object(UserSchemaShape)
+
+`, c.Convert(User{}))
+
+	c = NewConverterWithOpts(WithUnexportedFields(UnexportedError))
+	assert.EqualError(t, c.TryAddType(User{}), "unexported field zen.User.secret")
+
+	// embedded pointers to unexported structs are promoted as well
+	type Account struct {
+		ID     string `json:"id"`
+		secret string `json:"-"`
+		address
+		*audit
+	}
+	c = NewConverterWithOpts(WithUnexportedFields(UnexportedError))
+	assert.Equal(t, `export const AccountSchema = z.object({
+  city: z.string(),
+  by: z.string().optional(),
+  id: z.string(),
+})
+export type Account = z.infer<typeof AccountSchema>
+
+`, c.Convert(Account{}))
+}
+
+func TestCodeLists(t *testing.T) {
+	type Address struct {
+		Country  string         `json:"country" validate:"required,iso3166_1_alpha2_eu"`
+		Region   string         `json:"region" validate:"iso3166_2"`
+		Rates    map[string]int `json:"rates" validate:"dive,keys,iso3166_1_alpha3_eu,endkeys"`
+		Currency string         `json:"currency" validate:"required,oneof=EUR USD"`
+	}
+
+	eu2 := `"AT", "BE", "BG", "CY", "CZ", "DE", "DK", "EE", "ES", "FI", "FR", "GR", "HR", "HU", "IE", "IT", "LT", "LU", "LV", "MT", "NL", "PL", "PT", "RO", "SE", "SI", "SK"`
+	eu3 := `"AUT", "BEL", "BGR", "CYP", "CZE", "DEU", "DNK", "ESP", "EST", "FIN", "FRA", "GRC", "HRV", "HUN", "IRL", "ITA", "LTU", "LUX", "LVA", "MLT", "NLD", "POL", "PRT", "ROU", "SVK", "SVN", "SWE"`
+
+	c := NewConverterWithOpts(WithCodeLists())
+	assert.Equal(t, fmt.Sprintf(`export const AddressSchema = z.object({
+  country: z.string().min(1).pipe(z.enum([%s] as const)),
+  region: z.string().regex(/^[A-Z]{2}-[A-Z0-9]{1,3}$/),
+  rates: z.record(z.enum([%s] as const), z.number().int()).nullable(),
+  currency: z.string().min(1).pipe(z.enum(["EUR", "USD"] as const)),
+})
+export type Address = z.infer<typeof AddressSchema>
+
+`, eu2, eu3), c.Convert(Address{}))
+
+	c = NewConverterWithOpts(WithCodeLists(), WithExplicitTypes(), WithAudit())
+	assert.Equal(t, fmt.Sprintf(`export type Address = {
+  country: string,
+  region: string,
+  rates: Partial<Record<%s, number>> | null,
+  currency: string,
+}
+export const AddressSchemaShape = {
+  country: z.string().min(1).pipe(z.enum([%s] as const)),
+  region: z.string().regex(/^[A-Z]{2}-[A-Z0-9]{1,3}$/),
+  rates: z.record(z.enum([%s] as const), z.number().int()).nullable(),
+  currency: z.string().min(1).pipe(z.enum(["EUR", "USD"] as const)),
+}
+export const AddressSchema: z.ZodType<Address> = z.object(AddressSchemaShape)
+
+`, strings.ReplaceAll(eu3, ", ", " | "), eu2, eu3), c.Convert(Address{}))
+
+	assert.Len(t, iso3166Alpha2Codes, 250)
+	assert.Len(t, iso3166Alpha3Codes, 250)
+	assert.Len(t, iso4217Codes, 178)
+}
+
+func TestErrorSchema(t *testing.T) {
+	type Envelope struct {
+		Data  string  `json:"data"`
+		Error error   `json:"error"`
+		Cause error   `json:"cause,omitempty"`
+		Must  error   `json:"must" validate:"required"`
+		Errs  []error `json:"errs"`
+	}
+
+	assert.Equal(t, `export const EnvelopeSchema = z.object({
+  data: z.string(),
+  error: z.any(),
+  cause: z.any(),
+  must: z.any().refine((val) => val !== null && val !== undefined),
+  errs: z.any().array().nullable(),
+})
+export type Envelope = z.infer<typeof EnvelopeSchema>
+
+`, StructToZodSchema(Envelope{}))
+
+	messages := NewConverterWithOpts(WithErrorSchema("ApiError"))
+	assert.Equal(t, `export const EnvelopeSchema = z.object({
+  data: z.string(),
+  error: z.string().nullable(),
+  cause: z.string().optional(),
+  must: z.string(),
+  errs: z.string().array().nullable(),
+})
+export type Envelope = z.infer<typeof EnvelopeSchema>
+
+`, messages.Convert(Envelope{}))
+
+	union := NewConverterWithOpts(WithErrorSchema("ApiError", &NotFoundError{}, InvalidError{}))
+	assert.Equal(t, `export const NotFoundErrorSchema = z.object({
+  resource: z.string(),
+})
+export type NotFoundError = z.infer<typeof NotFoundErrorSchema>
+
+export const InvalidErrorSchema = z.object({
+  field: z.string(),
+})
+export type InvalidError = z.infer<typeof InvalidErrorSchema>
+
+export const ApiErrorSchema = z.union([NotFoundErrorSchema, InvalidErrorSchema])
+export type ApiError = z.infer<typeof ApiErrorSchema>
+
+export const EnvelopeSchema = z.object({
+  data: z.string(),
+  error: ApiErrorSchema.nullable(),
+  cause: ApiErrorSchema.optional(),
+  must: ApiErrorSchema,
+  errs: ApiErrorSchema.array().nullable(),
+})
+export type Envelope = z.infer<typeof EnvelopeSchema>
+
+`, union.Convert(Envelope{}))
+
+	explicit := NewConverterWithOpts(WithErrorSchema("ApiError", &NotFoundError{}), WithExplicitTypes())
+	assert.Equal(t, `export type NotFoundError = {
+  resource: string,
+}
+export const NotFoundErrorSchemaShape = {
+  resource: z.string(),
+}
+export const NotFoundErrorSchema: z.ZodType<NotFoundError> = z.object(NotFoundErrorSchemaShape)
+
+export type ApiError = NotFoundError
+export const ApiErrorSchema: z.ZodType<ApiError> = NotFoundErrorSchema
+
+export type Envelope = {
+  data: string,
+  error: ApiError | null,
+  cause?: ApiError | undefined,
+  must: ApiError,
+  errs: ApiError[] | null,
+}
+export const EnvelopeSchemaShape = {
+  data: z.string(),
+  error: ApiErrorSchema.nullable(),
+  cause: ApiErrorSchema.optional(),
+  must: ApiErrorSchema,
+  errs: ApiErrorSchema.array().nullable(),
+}
+export const EnvelopeSchema: z.ZodType<Envelope> = z.object(EnvelopeSchemaShape)
+
+`, explicit.Convert(Envelope{}))
+
+	type ApiError struct {
+		Code int `json:"code"`
+	}
+	collision := NewConverterWithOpts(WithErrorSchema("ApiError", InvalidError{}))
+	collision.AddType(ApiError{})
+	assert.EqualError(t, collision.TryAddType(Envelope{}),
+		"type name collision for ApiError: github.com/hypersequent/zen.ApiError and error")
+	collision = NewConverterWithOpts(WithErrorSchema("ApiError", InvalidError{}))
+	collision.AddType(Envelope{})
+	_, ok := collision.TryAddType(ApiError{}).(*NameCollisionError)
+	assert.True(t, ok)
+
+	assert.Panics(t, func() {
+		WithErrorSchema("ApiError", "not a struct")
+	})
+	assert.Panics(t, func() {
+		WithErrorSchema("ApiError", struct{}{})
+	})
+}
+
+func TestRequiredInterface(t *testing.T) {
+	type Event struct {
+		Payload  any           `json:"payload" validate:"required"`
+		Meta     interface{}   `json:"meta,omitempty"`
+		Items    []any         `json:"items" validate:"required,dive,required"`
+		Fallback *EventPayload `json:"fallback" validate:"required"`
+	}
+
+	assert.Equal(t, `export const EventSchema = z.object({
+  payload: z.any().refine((val) => val !== null && val !== undefined),
+  meta: z.any(),
+  items: z.any().refine((val) => val !== null && val !== undefined).array(),
+  fallback: z.any().refine((val) => val !== null && val !== undefined),
+})
+export type Event = z.infer<typeof EventSchema>
+
+`, StructToZodSchema(Event{}))
+
+	c := NewConverterWithOpts(WithInterfaceUnion((*EventPayload)(nil), ItemCreated{}), WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Event{}), `export type Event = {
+  payload: any,
+  meta: any,
+  items: any[],
+  fallback: ItemCreated,
+}
+export const EventSchemaShape = {
+  payload: z.any().refine((val) => val !== null && val !== undefined),
+  meta: z.any(),
+  items: z.any().refine((val) => val !== null && val !== undefined).array(),
+  fallback: ItemCreatedSchema,
+}`)
+}
+
+type EventPayload interface {
+	isEventPayload()
+}
+
+type ItemCreated struct {
+	ID string `json:"id"`
+}
+
+func (ItemCreated) isEventPayload() {}
+
+type ItemDeleted struct {
+	ID string `json:"id"`
+}
+
+func (*ItemDeleted) isEventPayload() {}
+
+func TestInterfaceUnion(t *testing.T) {
+	type Batch struct {
+		Payloads []EventPayload          `json:"payloads"`
+		Maybe    []EventPayload          `json:"maybe,omitempty"`
+		Required []EventPayload          `json:"required" validate:"required"`
+		First    EventPayload            `json:"first"`
+		Fixed    [2]EventPayload         `json:"fixed"`
+		ByID     map[string]EventPayload `json:"by_id"`
+	}
+
+	c := NewConverterWithOpts(WithInterfaceUnion((*EventPayload)(nil), ItemCreated{}, &ItemDeleted{}))
+	assert.Equal(t, `export const ItemCreatedSchema = z.object({
+  id: z.string(),
+})
+export type ItemCreated = z.infer<typeof ItemCreatedSchema>
+
+export const ItemDeletedSchema = z.object({
+  id: z.string(),
+})
+export type ItemDeleted = z.infer<typeof ItemDeletedSchema>
+
+export const BatchSchema = z.object({
+  payloads: z.union([ItemCreatedSchema, ItemDeletedSchema]).array().nullable(),
+  maybe: z.union([ItemCreatedSchema, ItemDeletedSchema]).array().optional(),
+  required: z.union([ItemCreatedSchema, ItemDeletedSchema]).array(),
+  first: z.union([ItemCreatedSchema, ItemDeletedSchema]).nullable(),
+  fixed: z.union([ItemCreatedSchema, ItemDeletedSchema]).nullable().array().length(2),
+  by_id: z.record(z.string(), z.union([ItemCreatedSchema, ItemDeletedSchema]).nullable()).nullable(),
+})
+export type Batch = z.infer<typeof BatchSchema>
+
+`, c.Convert(Batch{}))
+
+	c = NewConverterWithOpts(WithInterfaceUnion((*EventPayload)(nil), ItemCreated{}, ItemDeleted{}),
+		WithExplicitTypes(), WithAudit())
+	assert.Contains(t, c.Convert(Batch{}), `export type Batch = {
+  payloads: (ItemCreated | ItemDeleted)[] | null,
+  maybe?: (ItemCreated | ItemDeleted)[] | undefined,
+  required: (ItemCreated | ItemDeleted)[],
+  first: ItemCreated | ItemDeleted | null,
+  fixed: (ItemCreated | ItemDeleted | null)[],
+  by_id: Record<string, ItemCreated | ItemDeleted | null> | null,
+}`)
+
+	c = NewConverterWithOpts(WithInterfaceUnion((*EventPayload)(nil), ItemCreated{}))
+	assert.Contains(t, c.Convert(Batch{}), "  payloads: ItemCreatedSchema.array().nullable(),\n")
+
+	assert.Panics(t, func() {
+		WithInterfaceUnion(ItemCreated{}, ItemCreated{})
+	})
+	assert.Panics(t, func() {
+		WithInterfaceUnion((*EventPayload)(nil))
+	})
+	assert.Panics(t, func() {
+		WithInterfaceUnion((*EventPayload)(nil), NotFoundError{})
+	})
+}
+
+func TestExcludeTypes(t *testing.T) {
+	type Audit struct {
+		CreatedBy string `json:"created_by"`
+	}
+	type InternalFlags struct {
+		Beta bool `json:"beta"`
+	}
+	type Tag struct {
+		Name string `json:"name"`
+	}
+	type Document struct {
+		InternalFlags
+		Title string  `json:"title"`
+		Audit Audit   `json:"audit"`
+		Trail []Audit `json:"trail"`
+		Tags  []Tag   `json:"tags"`
+	}
+
+	c := NewConverterWithOpts(WithExcludeTypes("zen.Audit", "zen.Internal*"))
+	c.AddType(Document{})
+	assert.Equal(t, `export const TagSchema = z.object({
+  name: z.string(),
+})
+export type Tag = z.infer<typeof TagSchema>
+
+export const DocumentSchema = z.object({
+  title: z.string(),
+  audit: z.unknown(),
+  trail: z.unknown().array().nullable(),
+  tags: TagSchema.array().nullable(),
+})
+export type Document = z.infer<typeof DocumentSchema>
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithExplicitTypes(), WithExcludeTypes("github.com/hypersequent/zen.Audit", "zen.InternalFlags"))
+	c.AddType(Document{})
+	assert.Equal(t, `export type Tag = {
+  name: string,
+}
+export const TagSchemaShape = {
+  name: z.string(),
+}
+export const TagSchema: z.ZodType<Tag> = z.object(TagSchemaShape)
+
+export type Document = {
+  title: string,
+  audit?: unknown,
+  trail: unknown[] | null,
+  tags: Tag[] | null,
+}
+export const DocumentSchemaShape = {
+  title: z.string(),
+  audit: z.unknown(),
+  trail: z.unknown().array().nullable(),
+  tags: TagSchema.array().nullable(),
+}
+export const DocumentSchema: z.ZodType<Document> = z.object(DocumentSchemaShape)
+
+`, c.Export())
+
+	assert.Panics(t, func() {
+		WithExcludeTypes("zen.[")
+	})
+}
+
+func TestOpaqueTypes(t *testing.T) {
+	type Deep struct {
+		X int `json:"x"`
+	}
+	type Config struct {
+		Region string `json:"region"`
+		Deep   Deep   `json:"deep"`
+	}
+	type Labels map[string][]string
+	type Meta struct {
+		Trace string `json:"trace"`
+	}
+	type Service struct {
+		Meta
+		Name    string            `json:"name"`
+		Config  Config            `json:"config" validate:"required"`
+		Backup  *Config           `json:"backup,omitempty"`
+		Configs map[string]Config `json:"configs"`
+		Labels  Labels            `json:"labels" validate:"dive,keys,min=1,endkeys"`
+	}
+
+	c := NewConverterWithOpts(WithOpaqueTypes(Config{}, Labels{}, Meta{}))
+	assert.Equal(t, `export const ServiceSchema = z.object({
+  name: z.string(),
+  config: z.unknown(),
+  backup: z.unknown().optional(),
+  configs: z.record(z.string(), z.unknown()).nullable(),
+  labels: z.unknown().nullable(),
+})
+export type Service = z.infer<typeof ServiceSchema>
+
+`, c.Convert(Service{}))
+
+	c = NewConverterWithOpts(WithOpaqueTypes(Config{}, Labels{}, Meta{}), WithExplicitTypes())
+	assert.Equal(t, `export type Service = {
+  name: string,
+  config?: unknown,
+  backup?: unknown | undefined,
+  configs: Record<string, unknown> | null,
+  labels?: unknown | null,
+}
+export const ServiceSchemaShape = {
+  name: z.string(),
+  config: z.unknown(),
+  backup: z.unknown().optional(),
+  configs: z.record(z.string(), z.unknown()).nullable(),
+  labels: z.unknown().nullable(),
+}
+export const ServiceSchema: z.ZodType<Service> = z.object(ServiceSchemaShape)
+
+`, c.Convert(Service{}))
+}
+
+func TestTarget(t *testing.T) {
+	type AdminNote struct {
+		Text string `json:"text" validate:"required"`
+	}
+	type AdminAudit struct {
+		Actor string      `json:"actor"`
+		Notes []AdminNote `json:"notes"`
+	}
+	type PublicPost struct {
+		Title string     `json:"title" validate:"required"`
+		Note  *AdminNote `json:"note,omitempty"`
+	}
+
+	c := NewConverterWithOpts(WithTarget(TypeTarget, "zen.Admin*"))
+	c.AddType(AdminAudit{})
+	assert.Equal(t, `export type AdminNote = {
+  text: string,
+}
+
+export type AdminAudit = {
+  actor: string,
+  notes: AdminNote[] | null,
+}
+
+`, c.Export())
+
+	// The schema of AdminNote is referenced by PublicPostSchema.
+	c.AddType(PublicPost{})
+	assert.Equal(t, `export const AdminNoteSchema = z.object({
+  text: z.string().min(1),
+})
+export type AdminNote = z.infer<typeof AdminNoteSchema>
+
+export type AdminAudit = {
+  actor: string,
+  notes: AdminNote[] | null,
+}
+
+export const PublicPostSchema = z.object({
+  title: z.string().min(1),
+  note: AdminNoteSchema.optional(),
+})
+export type PublicPost = z.infer<typeof PublicPostSchema>
+
+`, c.Export())
+
+	c = NewConverterWithOpts(WithTarget(TypeTarget, "zen.*"), WithTarget(ZodTarget, "zen.AdminNote"))
+	c.AddType(AdminAudit{})
+	assert.Equal(t, `export const AdminNoteSchema = z.object({
+  text: z.string().min(1),
+})
+export type AdminNote = z.infer<typeof AdminNoteSchema>
+
+export type AdminAudit = {
+  actor: string,
+  notes: AdminNote[] | null,
+}
+
+`, c.Export())
+
+	assert.PanicsWithValue(t, `invalid target pattern "[": syntax error in pattern`, func() {
+		WithTarget(TypeTarget, "[")
+	})
+}
+
+func TestLazyDepth(t *testing.T) {
+	type Leaf struct {
+		Value string `json:"value"`
+	}
+	type Branch struct {
+		Leaf  Leaf   `json:"leaf"`
+		Other []Leaf `json:"other"`
+	}
+	type Root struct {
+		Branch Branch `json:"branch"`
+		Meta   struct {
+			Leaf Leaf `json:"leaf"`
+		} `json:"meta"`
+	}
+
+	c := NewConverterWithOpts(WithLazyDepth(2))
+	c.AddType(Root{})
+	assert.Equal(t, `export const BranchSchema = z.object({
+  leaf: z.lazy(() => LeafSchema),
+  other: z.lazy(() => LeafSchema).array().nullable(),
+})
+export type Branch = z.infer<typeof BranchSchema>
+
+export const RootSchema = z.object({
+  branch: BranchSchema,
+  meta: z.object({
+    leaf: z.lazy(() => LeafSchema),
+  }),
+})
+export type Root = z.infer<typeof RootSchema>
+
+export const LeafSchema = z.object({
+  value: z.string(),
+})
+export type Leaf = z.infer<typeof LeafSchema>
+
+`, c.Export())
+}
+
+func TestMaxDepth(t *testing.T) {
+	type Leaf struct {
+		Value string `json:"value"`
+	}
+	type Root struct {
+		Nested struct {
+			Deeper struct {
+				Leaf Leaf `json:"leaf"`
+			} `json:"deeper"`
+		} `json:"nested"`
+	}
+
+	c := NewConverterWithOpts(WithMaxDepth(3))
+	assert.EqualError(t, c.TryAddType(Root{}), "max depth 3 exceeded converting zen.Leaf")
+
+	c = NewConverterWithOpts(WithMaxDepth(3), WithLazyDepth(3))
+	assert.NoError(t, c.TryAddType(Root{}))
+	assert.Equal(t, `export const RootSchema = z.object({
+  nested: z.object({
+    deeper: z.object({
+      leaf: z.lazy(() => LeafSchema),
+    }),
+  }),
+})
+export type Root = z.infer<typeof RootSchema>
+
+export const LeafSchema = z.object({
+  value: z.string(),
+})
+export type Leaf = z.infer<typeof LeafSchema>
+
+`, c.Export())
+}
+
+func TestMaxAnyFields(t *testing.T) {
+	type Event struct {
+		Payload any                 `json:"payload"`
+		Tags    []interface{}       `json:"tags"`
+		Typed   any                 `json:"typed" zen:"type=z.string()"`
+		Meta    struct{ Extra any } `json:"meta"`
+	}
+	type Log struct {
+		First Event          `json:"first"`
+		Last  Event          `json:"last"`
+		Data  map[string]any `json:"data"`
+	}
+
+	c := NewConverterWithOpts(WithMaxAnyFields(3))
+	assert.NoError(t, c.TryAddType(Event{}))
+
+	var tooMany *AnyFieldsError
+	assert.ErrorAs(t, c.TryAddType(Log{}), &tooMany)
+	assert.EqualError(t, tooMany, "4 fields fall back to z.any(), more than 3: "+
+		"github.com/hypersequent/zen.Event.Payload, github.com/hypersequent/zen.Event.Tags, "+
+		"github.com/hypersequent/zen.Event.Meta.Extra, github.com/hypersequent/zen.Log.Data")
+
+	// the fields of the failed type are no longer counted
+	type Note struct {
+		Body any `json:"body"`
+	}
+	c = NewConverterWithOpts(WithMaxAnyFields(1))
+	assert.Error(t, c.TryAddType(Event{}))
+	assert.NoError(t, c.TryAddType(Note{}))
+}
+
+func TestObjectChunkSize(t *testing.T) {
+	type Base struct {
+		ID string `json:"id"`
+	}
+	type Wide struct {
+		Base
+		A string `json:"a"`
+		B string `json:"b"`
+		C struct {
+			X int `json:"x"`
+			Y int `json:"y"`
+			Z int `json:"z"`
+		} `json:"c"`
+		D string `json:"-"`
+		E string `json:"e"`
+	}
+
+	c := NewConverterWithOpts(WithObjectChunkSize(2))
+	c.AddType(Wide{})
+	assert.Equal(t, `export const BaseSchema = z.object({
+  id: z.string(),
+})
+export type Base = z.infer<typeof BaseSchema>
+
+export const WideSchema = z.object({
+  ...BaseSchema.shape,
+  a: z.string(),
+  b: z.string(),
+}).merge(z.object({
+  c: z.object({
+    x: z.number().int(),
+    y: z.number().int(),
+  }).merge(z.object({
+    z: z.number().int(),
+  })),
+  e: z.string(),
+}))
+export type Wide = z.infer<typeof WideSchema>
+
+`, c.Export())
+}
+
+func TestTimeFormat(t *testing.T) {
+	type Event struct {
+		At       time.Time            `json:"at" validate:"required"`
+		Updated  *time.Time           `json:"updated"`
+		Schedule map[time.Time]string `json:"schedule"`
+	}
+
+	c := NewConverterWithOpts(WithTimeFormat(ISOStringDatetime))
+	assert.Equal(t, `export const EventSchema = z.object({
+  at: z.string().datetime({ offset: true }).refine((val) => new Date(val).getTime() !== new Date('0001-01-01T00:00:00Z').getTime(), 'Invalid date'),
+  updated: z.string().datetime({ offset: true }).nullable(),
+  schedule: z.record(z.string().datetime({ offset: true }), z.string()).nullable(),
+})
+export type Event = z.infer<typeof EventSchema>
+
+`, c.Convert(Event{}))
+
+	c = NewConverterWithOpts(WithTimeFormat(UnixNumber), WithExplicitTypes())
+	assert.Equal(t, `export type Event = {
+  at: number,
+  updated: number | null,
+  schedule: Record<string, string> | null,
+}
+export const EventSchemaShape = {
+  at: z.number().refine((val) => val !== 0, 'Invalid date'),
+  updated: z.number().nullable(),
+  schedule: z.record(z.string().datetime({ offset: true }), z.string()).nullable(),
+}
+export const EventSchema: z.ZodType<Event> = z.object(EventSchemaShape)
+
+`, c.Convert(Event{}))
+}
+
+func TestZeroTimes(t *testing.T) {
+	type Event struct {
+		At time.Time `json:"at" validate:"required"`
+	}
+	epoch := time.Unix(0, 0)
+	dbDefault := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := NewConverterWithOpts(WithZeroTimes(time.Time{}, epoch, dbDefault))
+	assert.Contains(t, c.Convert(Event{}), "  at: z.coerce.date().refine((val) => "+
+		"val.getTime() !== new Date('0001-01-01T00:00:00Z').getTime() && "+
+		"val.getTime() !== new Date(0).getTime() && "+
+		"val.getTime() !== new Date('1900-01-01T00:00:00Z').getTime(), 'Invalid date'),\n")
+
+	// the list replaces the defaults
+	c = NewConverterWithOpts(WithZeroTimes(dbDefault))
+	assert.Contains(t, c.Convert(Event{}), "  at: z.coerce.date().refine((val) => "+
+		"val.getTime() !== new Date('1900-01-01T00:00:00Z').getTime(), 'Invalid date'),\n")
+
+	c = NewConverterWithOpts(WithZeroTimes(epoch, dbDefault, epoch), WithTimeFormat(ISOStringDatetime))
+	assert.Contains(t, c.Convert(Event{}), "  at: z.string().datetime({ offset: true }).refine((val) => "+
+		"new Date(val).getTime() !== new Date('1970-01-01T00:00:00Z').getTime() && "+
+		"new Date(val).getTime() !== new Date('1900-01-01T00:00:00Z').getTime(), 'Invalid date'),\n")
+
+	c = NewConverterWithOpts(WithZeroTimes(dbDefault), WithTimeFormat(UnixNumber))
+	assert.Contains(t, c.Convert(Event{}), "  at: z.number().refine((val) => val !== -2208988800, 'Invalid date'),\n")
+
+	c = NewConverterWithOpts(WithZeroTimes())
+	assert.Contains(t, c.Convert(Event{}), "  at: z.coerce.date(),\n")
+}
+
+func TestProfiler(t *testing.T) {
+	type Tag struct {
+		Name string `json:"name"`
+	}
+	type Post struct {
+		Title string `json:"title"`
+		Tags  []Tag  `json:"tags"`
+		Draft bool   `json:"-"`
+	}
+
+	var stats []ConversionStats
+	c := NewConverterWithOpts(WithProfiler(func(s ConversionStats) {
+		stats = append(stats, s)
+	}))
+	c.AddType(Post{})
+	c.AddType(Tag{})
+
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "Tag", stats[0].Name)
+	assert.Equal(t, reflect.TypeOf(Tag{}), stats[0].Type)
+	assert.Equal(t, 1, stats[0].Fields)
+	assert.Equal(t, "Post", stats[1].Name)
+	assert.Equal(t, 3, stats[1].Fields)
+	assert.GreaterOrEqual(t, stats[1].Duration, stats[0].Duration)
+}
+
+func TestZenValues(t *testing.T) {
+	type Item struct {
+		Meta   map[string]interface{}  `json:"meta" zen-values:"string|number"`
+		Labels *map[string]interface{} `json:"labels,omitempty" zen-values:"string" validate:"omitempty,max=3"`
+		Extra  map[string]interface{}  `json:"extra"`
+	}
+
+	assert.Equal(t, `export const ItemSchema = z.object({
+  meta: z.record(z.string(), z.union([z.string(), z.number()])).nullable(),
+  labels: z.record(z.string(), z.string()).refine((val) => Object.keys(val).length <= 3, 'Map too large').optional().nullable(),
+  extra: z.record(z.string(), z.any()).nullable(),
+})
+export type Item = z.infer<typeof ItemSchema>
+
+`, StructToZodSchema(Item{}))
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(Item{}), `export type Item = {
+  meta: Record<string, string | number> | null,
+  labels?: Record<string, string> | null | undefined,
+  extra: Record<string, any> | null,
+}`)
+
+	type Invalid struct {
+		Meta map[string]string `json:"meta" zen-values:"string"`
+	}
+	assert.Panics(t, func() {
+		StructToZodSchema(Invalid{})
+	})
+
+	type Unknown struct {
+		Meta map[string]interface{} `json:"meta" zen-values:"date"`
+	}
+	assert.Panics(t, func() {
+		StructToZodSchema(Unknown{})
+	})
+}
+
+func TestInt64AsBigInt(t *testing.T) {
+	type Account struct {
+		ID      int64   `json:"id" validate:"required"`
+		Balance uint64  `json:"balance" validate:"gte=10,lt=100"`
+		Kind    int64   `json:"kind" validate:"oneof=1 2"`
+		Parent  *int64  `json:"parent"`
+		Count   int     `json:"count"`
+		Limits  []int64 `json:"limits"`
+	}
+
+	c := NewConverterWithOpts(WithInt64AsBigInt())
+	assert.Equal(t, `export const AccountSchema = z.object({
+  id: z.bigint().refine((val) => val !== 0n),
+  balance: z.bigint().nonnegative().refine((val) => val >= 10n).refine((val) => val < 100n),
+  kind: z.bigint().refine((val) => [1n, 2n].includes(val)),
+  parent: z.bigint().nullable(),
+  count: z.number().int(),
+  limits: z.bigint().array().nullable(),
+})
+export type Account = z.infer<typeof AccountSchema>
+
+`, c.Convert(Account{}))
+
+	c = NewConverterWithOpts(WithInt64AsString(), WithExplicitTypes())
+	assert.Equal(t, `export type Account = {
+  id: string,
+  balance: string,
+  kind: string,
+  parent: string | null,
+  count: number,
+  limits: string[] | null,
+}
+export const AccountSchemaShape = {
+  id: z.string().regex(/^-?\d+$/).pipe(z.string().refine((val) => BigInt(val) !== 0n)),
+  balance: z.string().regex(/^\d+$/).pipe(z.string().refine((val) => BigInt(val) >= 10n).refine((val) => BigInt(val) < 100n)),
+  kind: z.string().regex(/^-?\d+$/).pipe(z.string().refine((val) => [1n, 2n].includes(BigInt(val)))),
+  parent: z.string().regex(/^-?\d+$/).nullable(),
+  count: z.number().int(),
+  limits: z.string().regex(/^-?\d+$/).array().nullable(),
+}
+export const AccountSchema: z.ZodType<Account> = z.object(AccountSchemaShape)
+
+`, c.Convert(Account{}))
+}
+
+func TestEnumKeyedRecordType(t *testing.T) {
+	type Settings struct {
+		Limits map[string]int    `json:"limits" validate:"dive,keys,oneof=daily monthly,endkeys,min=1"`
+		Notes  map[string]string `json:"notes" validate:"dive,keys,min=1,endkeys"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Equal(t, `export type Settings = {
+  limits: Partial<Record<"daily" | "monthly", number>> | null,
+  notes: Record<string, string> | null,
+}
+export const SettingsSchemaShape = {
+  limits: z.record(z.enum(["daily", "monthly"] as const), z.number().int().gte(1)).nullable(),
+  notes: z.record(z.string().min(1), z.string()).nullable(),
+}
+export const SettingsSchema: z.ZodType<Settings> = z.object(SettingsSchemaShape)
+
+`, c.Convert(Settings{}))
+}
+
+func TestUnsignedNonnegative(t *testing.T) {
+	type Counter struct {
+		Hits   uint               `json:"hits"`
+		Small  uint8              `json:"small" validate:"max=10"`
+		Signed int32              `json:"signed"`
+		ByID   map[uint16]float64 `json:"by_id"`
+	}
+
+	assert.Equal(t, `export const CounterSchema = z.object({
+  hits: z.number().int().nonnegative(),
+  small: z.number().int().nonnegative().lte(10),
+  signed: z.number().int(),
+  by_id: z.record(z.coerce.number().int().nonnegative(), z.number()).nullable(),
+})
+export type Counter = z.infer<typeof CounterSchema>
+
+`, StructToZodSchema(Counter{}))
+
+	c := NewConverterWithOpts(WithoutIntChecks())
+	assert.Contains(t, c.Convert(Counter{}), `  hits: z.number().nonnegative(),
+  small: z.number().nonnegative().lte(10),
+  signed: z.number(),
+`)
+}
+
+func TestIntegerBounds(t *testing.T) {
+	type Sample struct {
+		Tiny   int8   `json:"tiny"`
+		Port   uint16 `json:"port" validate:"gte=1024"`
+		Offset int32  `json:"offset"`
+		Count  uint   `json:"count"`
+		Big    int64  `json:"big"`
+	}
+
+	c := NewConverterWithOpts(WithIntegerBounds())
+	assert.Equal(t, `export const SampleSchema = z.object({
+  tiny: z.number().int().gte(-128).lte(127),
+  port: z.number().int().gte(0).lte(65535).gte(1024),
+  offset: z.number().int().gte(-2147483648).lte(2147483647),
+  count: z.number().int().nonnegative(),
+  big: z.number().int(),
+})
+export type Sample = z.infer<typeof SampleSchema>
+
+`, c.Convert(Sample{}))
+}
+
+func TestComplex(t *testing.T) {
+	type Signal struct {
+		Phase complex128  `json:"phase"`
+		Taps  []complex64 `json:"taps"`
+		Peak  *complex128 `json:"peak"`
+	}
+
+	assert.PanicsWithValue(t, "cannot handle: complex128", func() {
+		StructToZodSchema(Signal{})
+	})
+
+	c := NewConverterWithOpts(WithComplexAsObject(), WithExplicitTypes())
+	assert.Equal(t, `export type Signal = {
+  phase: {
+    re: number,
+    im: number,
+  },
+  taps: {
+    re: number,
+    im: number,
+  }[] | null,
+  peak: {
+    re: number,
+    im: number,
+  } | null,
+}
+export const SignalSchemaShape = {
+  phase: z.object({
+    re: z.number(),
+    im: z.number(),
+  }),
+  taps: z.object({
+    re: z.number(),
+    im: z.number(),
+  }).array().nullable(),
+  peak: z.object({
+    re: z.number(),
+    im: z.number(),
+  }).nullable(),
+}
+export const SignalSchema: z.ZodType<Signal> = z.object(SignalSchemaShape)
+
+`, c.Convert(Signal{}))
+}
+
+func TestDashFieldName(t *testing.T) {
+	type Diff struct {
+		Added   string  `json:"added"`
+		Removed *string `json:"-,omitempty"`
+		Hidden  string  `json:"-"`
+	}
+
+	assert.Equal(t, `export const DiffSchema = z.object({
+  added: z.string(),
+  "-": z.string().optional(),
+})
+export type Diff = z.infer<typeof DiffSchema>
+
+`, StructToZodSchema(Diff{}))
+
+	type Dash struct {
+		Value int `json:"-,"`
+	}
+	c := NewConverterWithOpts(WithExplicitTypes())
+	assert.Contains(t, c.Convert(Dash{}), `export type Dash = {
+  "-": number,
+}`)
+}
+
+type TestTextKey [2]byte
+
+func (k TestTextKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%x", k[:])), nil
+}
+
+type TestTextIntKey int
+
+func (k TestTextIntKey) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("k%d", int(k))), nil
+}
+
+type TestCustomKey struct {
+	A, B int
+}
+
+func TestTextMarshalerKeys(t *testing.T) {
+	type Index struct {
+		ByKey    map[TestTextKey]string    `json:"by_key"`
+		ByIntKey map[TestTextIntKey]int    `json:"by_int_key"`
+		ByCustom map[TestCustomKey]float64 `json:"by_custom"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithCustomTypes(map[string]CustomFn{
+		"github.com/hypersequent/zen.TestCustomKey": func(c *Converter, t reflect.Type, validate string, indent int) string {
+			return "z.string().regex(/^\\d+:\\d+$/)"
+		},
+	}))
+	assert.Equal(t, `export type Index = {
+  by_key: Record<string, string> | null,
+  by_int_key: Record<string, number> | null,
+  by_custom: Record<string, number> | null,
+}
+export const IndexSchemaShape = {
+  by_key: z.record(z.string(), z.string()).nullable(),
+  by_int_key: z.record(z.string(), z.number().int()).nullable(),
+  by_custom: z.record(z.string().regex(/^\d+:\d+$/), z.number()).nullable(),
+}
+export const IndexSchema: z.ZodType<Index> = z.object(IndexSchemaShape)
+
+`, c.Convert(Index{}))
+}
+
+func TestSpecialFieldNames(t *testing.T) {
+	type Headers struct {
+		ContentType string  `json:"content-type"`
+		Version     int     `json:"api.version"`
+		Display     *string `json:"display name,omitempty"`
+		Greeting    string  `json:"grüße"`
+		Ratio       float64 `json:"a: b"`
+		Dollar      string  `json:"$ref"`
+		Digit       string  `json:"1st"`
+	}
+
+	c := NewConverterWithOpts(WithExplicitTypes(), WithAudit())
+	assert.NoError(t, c.TryAddType(Headers{}))
+	assert.Equal(t, `export type Headers = {
+  "content-type": string,
+  "api.version": number,
+  "display name"?: string | undefined,
+  "grüße": string,
+  "a: b": number,
+  $ref: string,
+  "1st": string,
+}
+export const HeadersSchemaShape = {
+  "content-type": z.string(),
+  "api.version": z.number().int(),
+  "display name": z.string().optional(),
+  "grüße": z.string(),
+  "a: b": z.number(),
+  $ref: z.string(),
+  "1st": z.string(),
+}
+export const HeadersSchema: z.ZodType<Headers> = z.object(HeadersSchemaShape)
+
+`, c.Export())
+}
+
+func TestExportFile(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	c := NewConverter(nil)
+	c.AddType(User{})
+	name := filepath.Join(t.TempDir(), "schemas.ts")
+	require.NoError(t, c.ExportFile(name))
+	data, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, `import { z } from "zod"
+
+export const UserSchema = z.object({
+  name: z.string(),
+})
+export type User = z.infer<typeof UserSchema>
+
+`, string(data))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, os.WriteFile(name, []byte("previous"), 0o600))
+	assert.ErrorIs(t, c.ExportFileContext(ctx, name), context.Canceled)
+	data, err = os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(data))
+	entries, err := os.ReadDir(filepath.Dir(name))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}